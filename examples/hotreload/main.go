@@ -105,6 +105,26 @@ func main() {
 		QueryType: "A",
 	})
 
+	time.Sleep(3 * time.Second)
+	fmt.Println("\n>>> TRIGGERING HOT-RELOAD: Replacing Entire Server Set...")
+
+	// Demonstrating an atomic full swap (e.g. after reloading a config file).
+	// The previous set is dropped and the new one, restoring the original
+	// keyword, is installed in a single locked operation, so checks never
+	// observe an empty server list.
+	c.ReplaceServers([]nawala.DNSServer{
+		{
+			Address:   "180.131.144.144",
+			Keyword:   "internetpositif",
+			QueryType: "A",
+		},
+		{
+			Address:   "8.8.8.8",
+			Keyword:   "this-will-never-match",
+			QueryType: "A",
+		},
+	})
+
 	time.Sleep(3 * time.Second)
 	fmt.Println("\n>>> TRIGGERING HOT-RELOAD: Deleting Server...")
 
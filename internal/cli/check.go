@@ -128,17 +128,21 @@ func streamDomains(ctx context.Context, args []string, filePath string, in chan<
 	seen := make(map[string]struct{})
 	var count int
 
-	addDomain := func(d string) {
-		d = toASCIIDomain(strings.ToLower(strings.TrimSpace(d)))
-		if d == "" {
-			return
-		}
-		if _, ok := seen[d]; !ok {
-			seen[d] = struct{}{}
-			select {
-			case <-ctx.Done():
-			case in <- d:
-				count++
+	// Wildcard entries ("*.example.com") expand to the apex and a probe
+	// subdomain; see nawala.ExpandWildcard.
+	addDomain := func(entry string) {
+		for _, d := range nawala.ExpandWildcard(strings.ToLower(strings.TrimSpace(entry))) {
+			d = toASCIIDomain(d)
+			if d == "" {
+				continue
+			}
+			if _, ok := seen[d]; !ok {
+				seen[d] = struct{}{}
+				select {
+				case <-ctx.Done():
+				case in <- d:
+					count++
+				}
 			}
 		}
 	}
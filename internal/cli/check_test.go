@@ -96,6 +96,22 @@ func TestCollectDomains_BlankAndWhitespace(t *testing.T) {
 	}
 }
 
+func TestCollectDomains_Wildcard(t *testing.T) {
+	domains, err := collectDomains([]string{" *.Gambling.Example ", "gambling.example"}, "")
+	if err != nil {
+		t.Fatalf("collectDomains error: %v", err)
+	}
+	want := []string{"gambling.example", "nawala-wildcard-probe.gambling.example"}
+	if len(domains) != len(want) {
+		t.Fatalf("domains = %v, want %v", domains, want)
+	}
+	for i := range want {
+		if domains[i] != want[i] {
+			t.Errorf("domains[%d] = %q, want %q", i, domains[i], want[i])
+		}
+	}
+}
+
 func TestCollectDomains_File(t *testing.T) {
 	content := `# Header comment
 google.com
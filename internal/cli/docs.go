@@ -67,6 +67,11 @@
 // lines starting with '#' are comments and blank lines are ignored.
 // Duplicates across both sources are removed automatically.
 //
+// Wildcard entries such as "*.gambling.example" are expanded into the apex
+// ("gambling.example") and a synthetic probe subdomain
+// ("nawala-wildcard-probe.gambling.example"), since DNS wildcards are only
+// expanded server-side; see ExpandWildcard in the nawala package.
+//
 // Domains are streamed through a channel pipeline: the file is read
 // line-by-line (via [bufio.Scanner]) and each domain is sent to the
 // checker as it is read. This means memory usage stays constant
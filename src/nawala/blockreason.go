@@ -0,0 +1,175 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// BlockReason classifies how a blocked domain was blocked.
+//
+// The EDE-based reasons map the filtering-related INFO-CODEs defined by
+// [RFC 8914] (Extended DNS Errors), which Komdigi attaches to its block
+// responses. [BlockReasonRedirect] covers the classic Nawala CNAME redirect
+// to a landing page, which carries no EDE option.
+//
+// [RFC 8914]: https://datatracker.ietf.org/doc/html/rfc8914
+type BlockReason int
+
+// Block reasons reported in [Result.BlockReason].
+const (
+	// BlockReasonNone means the domain was not blocked.
+	BlockReasonNone BlockReason = iota
+
+	// BlockReasonBlocked is EDE 15 (Blocked): the domain is on a block list
+	// applied by the resolver operator.
+	BlockReasonBlocked
+
+	// BlockReasonCensored is EDE 16 (Censored): the domain is blocked due to
+	// an external requirement (e.g. a legal or regulatory order).
+	BlockReasonCensored
+
+	// BlockReasonFiltered is EDE 17 (Filtered): the domain is blocked because
+	// the client requested filtering.
+	BlockReasonFiltered
+
+	// BlockReasonProhibited is EDE 18 (Prohibited): the client is not
+	// authorized to query the domain.
+	BlockReasonProhibited
+
+	// BlockReasonRedirect means the block was detected through a CNAME
+	// redirect to a landing page without any EDE option.
+	BlockReasonRedirect
+
+	// BlockReasonUnknown means the domain was detected as blocked (the
+	// keyword matched) but the response carries neither a filtering EDE
+	// code nor a CNAME redirect.
+	BlockReasonUnknown
+
+	// BlockReasonSinkhole means a public domain resolved only to loopback,
+	// private, or reserved addresses, a common way for filters to block
+	// without any keyword to match (see [WithSinkholeDetection]).
+	BlockReasonSinkhole
+)
+
+// String returns a short lowercase name for the block reason.
+func (r BlockReason) String() string {
+	switch r {
+	case BlockReasonNone:
+		return "none"
+	case BlockReasonBlocked:
+		return "blocked"
+	case BlockReasonCensored:
+		return "censored"
+	case BlockReasonFiltered:
+		return "filtered"
+	case BlockReasonProhibited:
+		return "prohibited"
+	case BlockReasonRedirect:
+		return "redirect"
+	case BlockReasonSinkhole:
+		return "sinkhole"
+	default:
+		return "unknown"
+	}
+}
+
+// blockReasonFromEDE maps an RFC 8914 INFO-CODE to a [BlockReason].
+// It reports false for codes that are not filtering-related.
+func blockReasonFromEDE(code uint16) (BlockReason, bool) {
+	switch code {
+	case dns.ExtendedErrorCodeBlocked:
+		return BlockReasonBlocked, true
+	case dns.ExtendedErrorCodeCensored:
+		return BlockReasonCensored, true
+	case dns.ExtendedErrorCodeFiltered:
+		return BlockReasonFiltered, true
+	case dns.ExtendedErrorCodeProhibited:
+		return BlockReasonProhibited, true
+	default:
+		return BlockReasonNone, false
+	}
+}
+
+// extendedErrors returns every EDE option carried in the OPT record of msg.
+func extendedErrors(msg *dns.Msg) []*dns.EDNS0_EDE {
+	if msg == nil {
+		return nil
+	}
+
+	opt := msg.IsEdns0()
+	if opt == nil {
+		return nil
+	}
+
+	var edes []*dns.EDNS0_EDE
+	for _, o := range opt.Option {
+		if ede, ok := o.(*dns.EDNS0_EDE); ok {
+			edes = append(edes, ede)
+		}
+	}
+	return edes
+}
+
+// classifyBlock determines the [BlockReason] for a response already
+// detected as blocked. A filtering EDE code takes precedence; otherwise a
+// CNAME in the Answer section marks a redirect block.
+func classifyBlock(msg *dns.Msg) BlockReason {
+	for _, ede := range extendedErrors(msg) {
+		if reason, ok := blockReasonFromEDE(ede.InfoCode); ok {
+			return reason
+		}
+	}
+
+	for _, rr := range msg.Answer {
+		if _, ok := rr.(*dns.CNAME); ok {
+			return BlockReasonRedirect
+		}
+	}
+
+	return BlockReasonUnknown
+}
+
+// edeFields parses the structured EXTRA-TEXT of the first EDE option in msg
+// that carries any, such as Komdigi's
+// "source=block-list-zone; blockListUrl=https://...; domain=reddit.com",
+// into its key=value pairs. Keys are lowercased; segments without "=" are
+// skipped. It returns nil when no EDE carries structured text.
+func edeFields(msg *dns.Msg) map[string]string {
+	for _, ede := range extendedErrors(msg) {
+		var fields map[string]string
+		for part := range strings.SplitSeq(ede.ExtraText, ";") {
+			key, value, ok := strings.Cut(part, "=")
+			key = strings.TrimSpace(key)
+			if !ok || key == "" {
+				continue
+			}
+			if fields == nil {
+				fields = make(map[string]string)
+			}
+			fields[strings.ToLower(key)] = strings.TrimSpace(value)
+		}
+		if fields != nil {
+			return fields
+		}
+	}
+	return nil
+}
+
+// edeDomainMismatch reports the domain named by the "domain=" EDE field of
+// msg when it is neither the queried domain nor one of its parents, which
+// indicates a stale or cross-contaminated response. A parent is accepted
+// because a block list entry such as reddit.com also covers
+// www.reddit.com. It reports false when there is no such field.
+func edeDomainMismatch(msg *dns.Msg, domain string) (string, bool) {
+	named, ok := edeFields(msg)["domain"]
+	if !ok || named == "" {
+		return "", false
+	}
+	return named, !dns.IsSubDomain(dns.CanonicalName(named), dns.CanonicalName(domain))
+}
@@ -0,0 +1,308 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newKomdigiReply builds a Komdigi-style block response: an A record to a
+// block-page IP plus an EDE option with the given INFO-CODE.
+func newKomdigiReply(r *dns.Msg, code uint16) *dns.Msg {
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Answer = append(m.Answer, &dns.A{
+		Hdr: dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 30},
+		A:   net.ParseIP("103.155.26.29"),
+	})
+	m.SetEdns0(1232, false)
+	opt := m.IsEdns0()
+	opt.Option = append(opt.Option, &dns.EDNS0_EDE{
+		InfoCode:  code,
+		ExtraText: "source=block-list-zone; blockListUrl=https://trustpositif.komdigi.go.id/assets/db/domains_isp; domain=" + dns.CanonicalName(r.Question[0].Name),
+	})
+	return m
+}
+
+func TestBlockReasonString(t *testing.T) {
+	tests := []struct {
+		reason BlockReason
+		want   string
+	}{
+		{BlockReasonNone, "none"},
+		{BlockReasonBlocked, "blocked"},
+		{BlockReasonCensored, "censored"},
+		{BlockReasonFiltered, "filtered"},
+		{BlockReasonProhibited, "prohibited"},
+		{BlockReasonRedirect, "redirect"},
+		{BlockReasonUnknown, "unknown"},
+		{BlockReasonSinkhole, "sinkhole"},
+		{BlockReason(99), "unknown"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.reason.String())
+	}
+}
+
+func TestBlockReasonFromEDE(t *testing.T) {
+	tests := []struct {
+		code uint16
+		want BlockReason
+		ok   bool
+	}{
+		{dns.ExtendedErrorCodeBlocked, BlockReasonBlocked, true},
+		{dns.ExtendedErrorCodeCensored, BlockReasonCensored, true},
+		{dns.ExtendedErrorCodeFiltered, BlockReasonFiltered, true},
+		{dns.ExtendedErrorCodeProhibited, BlockReasonProhibited, true},
+		{dns.ExtendedErrorCodeStaleAnswer, BlockReasonNone, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := blockReasonFromEDE(tt.code)
+		assert.Equal(t, tt.want, got, "code %d", tt.code)
+		assert.Equal(t, tt.ok, ok, "code %d", tt.code)
+	}
+}
+
+func TestClassifyBlock(t *testing.T) {
+	q := new(dns.Msg)
+	q.SetQuestion("example.com.", dns.TypeA)
+
+	t.Run("EDE wins", func(t *testing.T) {
+		assert.Equal(t, BlockReasonCensored, classifyBlock(newKomdigiReply(q, dns.ExtendedErrorCodeCensored)))
+	})
+
+	t.Run("CNAME redirect", func(t *testing.T) {
+		m := new(dns.Msg)
+		m.SetReply(q)
+		m.Answer = append(m.Answer, &dns.CNAME{
+			Hdr:    dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeCNAME, Class: dns.ClassINET},
+			Target: "internetpositif.id.",
+		})
+		assert.Equal(t, BlockReasonRedirect, classifyBlock(m))
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		m := new(dns.Msg)
+		m.SetReply(q)
+		assert.Equal(t, BlockReasonUnknown, classifyBlock(m))
+	})
+
+	assert.Nil(t, extendedErrors(nil))
+}
+
+func TestCheckBlockReason(t *testing.T) {
+	t.Run("komdigi EDE", func(t *testing.T) {
+		handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			_ = w.WriteMsg(newKomdigiReply(r, dns.ExtendedErrorCodeBlocked))
+		})
+		addr, cleanup := startTestDNSServer(t, handler)
+		defer cleanup()
+
+		c := New(WithServers([]DNSServer{{Address: addr, Keyword: "trustpositif", QueryType: "A"}}))
+		result, err := c.CheckOne(context.Background(), "reddit.com")
+		require.NoError(t, err)
+		require.NoError(t, result.Error)
+		assert.True(t, result.Blocked)
+		assert.Equal(t, BlockReasonBlocked, result.BlockReason)
+		assert.Equal(t, SectionAdditional, result.MatchedSection)
+	})
+
+	t.Run("nawala redirect", func(t *testing.T) {
+		addr, cleanup := startBlockingDNSServer(t)
+		defer cleanup()
+
+		c := New(WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}))
+		result, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.True(t, result.Blocked)
+		assert.Equal(t, BlockReasonRedirect, result.BlockReason)
+		assert.Equal(t, SectionAnswer, result.MatchedSection)
+	})
+
+	t.Run("sinkhole", func(t *testing.T) {
+		handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(r)
+			m.Answer = append(m.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   net.IPv4zero,
+			})
+			_ = w.WriteMsg(m)
+		})
+		addr, cleanup := startTestDNSServer(t, handler)
+		defer cleanup()
+
+		servers := []DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}
+
+		c := New(WithServers(servers), WithSinkholeDetection(true))
+		result, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.True(t, result.Blocked)
+		assert.Equal(t, BlockReasonSinkhole, result.BlockReason)
+		assert.Equal(t, SectionAnswer, result.MatchedSection)
+
+		c = New(WithServers(servers))
+		result, err = c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.False(t, result.Blocked)
+	})
+
+	t.Run("all matches", func(t *testing.T) {
+		handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := newKomdigiReply(r, dns.ExtendedErrorCodeBlocked)
+			m.Answer = append([]dns.RR{&dns.CNAME{
+				Hdr:    dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 30},
+				Target: "internetpositif.id.",
+			}}, m.Answer...)
+			_ = w.WriteMsg(m)
+		})
+		addr, cleanup := startTestDNSServer(t, handler)
+		defer cleanup()
+
+		servers := []DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}
+
+		c := New(WithServers(servers), WithAllMatches(true))
+		result, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.True(t, result.Blocked)
+		assert.Equal(t, BlockReasonBlocked, result.BlockReason)
+		assert.Equal(t, []BlockSignal{
+			{Reason: BlockReasonRedirect, Keyword: "internetpositif", Section: SectionAnswer},
+			{Reason: BlockReasonBlocked, Section: SectionAdditional},
+		}, result.Signals)
+
+		c = New(WithServers(servers))
+		result, err = c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.True(t, result.Blocked)
+		assert.Nil(t, result.Signals)
+	})
+
+	t.Run("not blocked", func(t *testing.T) {
+		addr, cleanup := startNormalDNSServer(t)
+		defer cleanup()
+
+		c := New(WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}))
+		result, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.False(t, result.Blocked)
+		assert.Equal(t, BlockReasonNone, result.BlockReason)
+		assert.Empty(t, result.MatchedSection)
+	})
+}
+
+func TestEDEFields(t *testing.T) {
+	q := new(dns.Msg)
+	q.SetQuestion("reddit.com.", dns.TypeA)
+
+	fields := edeFields(newKomdigiReply(q, dns.ExtendedErrorCodeBlocked))
+	assert.Equal(t, map[string]string{
+		"source":       "block-list-zone",
+		"blocklisturl": "https://trustpositif.komdigi.go.id/assets/db/domains_isp",
+		"domain":       "reddit.com.",
+	}, fields)
+
+	// Free-form text carries no fields; a later EDE with fields is used.
+	m := new(dns.Msg)
+	m.SetEdns0(1232, false)
+	opt := m.IsEdns0()
+	opt.Option = append(opt.Option,
+		&dns.EDNS0_EDE{InfoCode: dns.ExtendedErrorCodeBlocked, ExtraText: "blocked by policy"},
+		&dns.EDNS0_EDE{InfoCode: dns.ExtendedErrorCodeFiltered, ExtraText: " domain = Example.com ;=ignored; flag"},
+	)
+	assert.Equal(t, map[string]string{"domain": "Example.com"}, edeFields(m))
+
+	assert.Nil(t, edeFields(nil))
+	assert.Nil(t, edeFields(new(dns.Msg)))
+}
+
+func TestEDEDomainMismatch(t *testing.T) {
+	q := new(dns.Msg)
+	q.SetQuestion("reddit.com.", dns.TypeA)
+	reply := newKomdigiReply(q, dns.ExtendedErrorCodeBlocked)
+
+	_, mismatch := edeDomainMismatch(reply, "reddit.com")
+	assert.False(t, mismatch)
+	_, mismatch = edeDomainMismatch(reply, "REDDIT.com.")
+	assert.False(t, mismatch)
+
+	_, mismatch = edeDomainMismatch(reply, "www.reddit.com")
+	assert.False(t, mismatch, "a subdomain of the named domain")
+
+	named, mismatch := edeDomainMismatch(reply, "example.com")
+	assert.True(t, mismatch)
+	assert.Equal(t, "reddit.com.", named)
+
+	_, mismatch = edeDomainMismatch(reply, "notreddit.com")
+	assert.True(t, mismatch, "a suffix that is not a label boundary")
+
+	q.SetQuestion("www.reddit.com.", dns.TypeA)
+	_, mismatch = edeDomainMismatch(newKomdigiReply(q, dns.ExtendedErrorCodeBlocked), "reddit.com")
+	assert.True(t, mismatch, "the parent of the named domain")
+
+	_, mismatch = edeDomainMismatch(new(dns.Msg), "example.com")
+	assert.False(t, mismatch, "no domain field")
+}
+
+func TestCheckBlockListURL(t *testing.T) {
+	ctx := context.Background()
+
+	komdigi, cleanup := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		_ = w.WriteMsg(newKomdigiReply(r, dns.ExtendedErrorCodeBlocked))
+	}))
+	defer cleanup()
+
+	c := New(WithServers([]DNSServer{{Address: komdigi, Keyword: "trustpositif", QueryType: "A"}}))
+	result, err := c.CheckOne(ctx, "reddit.com")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	assert.True(t, result.Blocked)
+	assert.Equal(t, "https://trustpositif.komdigi.go.id/assets/db/domains_isp", result.BlockListURL)
+
+	// A response naming another domain is rejected and failed over.
+	stale, cleanupStale := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		other := r.Copy()
+		other.Question[0].Name = "other.example."
+		reply := newKomdigiReply(other, dns.ExtendedErrorCodeBlocked)
+		reply.Question = r.Question
+		reply.Answer[0].Header().Name = r.Question[0].Name
+		_ = w.WriteMsg(reply)
+	}))
+	defer cleanupStale()
+
+	only := New(
+		WithServers([]DNSServer{{Address: stale, Keyword: "trustpositif", QueryType: "A"}}),
+		WithMaxRetries(0),
+	)
+	result, err = only.CheckOne(ctx, "reddit.com")
+	require.NoError(t, err)
+	assert.ErrorIs(t, result.Error, ErrAllDNSFailed)
+	assert.ErrorIs(t, result.Error, ErrEDEDomainMismatch)
+
+	normal, cleanupNormal := startNormalDNSServer(t)
+	defer cleanupNormal()
+
+	failover := New(
+		WithServers([]DNSServer{
+			{Address: stale, Keyword: "trustpositif", QueryType: "A"},
+			{Address: normal, Keyword: "trustpositif", QueryType: "A"},
+		}),
+		WithMaxRetries(0),
+	)
+	result, err = failover.CheckOne(ctx, "reddit.com")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	assert.Equal(t, normal, result.Server)
+	assert.Empty(t, result.BlockListURL)
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// reliabilityWindow is the number of most recent server queries kept
+	// per server for [Checker.ServerReliability].
+	reliabilityWindow = 50
+
+	// breakerMinSamples is the number of queries a server must have in its
+	// window before [WithCircuitBreaker] may trip it, so that a single
+	// early failure does not take a server out of rotation.
+	breakerMinSamples = 5
+)
+
+// outcomeWindow is a ring buffer of a server's most recent query outcomes
+// plus its circuit breaker and failure cooldown state.
+type outcomeWindow struct {
+	ok        [reliabilityWindow]bool
+	next      int // index the next outcome is written to
+	n         int // number of outcomes recorded, up to reliabilityWindow
+	successes int
+
+	openUntil time.Time // zero while the circuit is closed
+	halfOpen  bool      // cooldown elapsed; the next outcome decides
+
+	consecutive int       // failures since the last success
+	skipUntil   time.Time // end of the WithServerCooldown skip, if any
+}
+
+func (w *outcomeWindow) add(ok bool) {
+	if w.n == reliabilityWindow {
+		if w.ok[w.next] {
+			w.successes--
+		}
+	} else {
+		w.n++
+	}
+	w.ok[w.next] = ok
+	if ok {
+		w.successes++
+	}
+	w.next = (w.next + 1) % reliabilityWindow
+}
+
+func (w *outcomeWindow) reset() {
+	*w = outcomeWindow{}
+}
+
+func (w *outcomeWindow) successRate() float64 {
+	if w.n == 0 {
+		return 1
+	}
+	return float64(w.successes) / float64(w.n)
+}
+
+// serverHealth tracks per-server query outcomes and, when threshold and
+// cooldown are set by [WithCircuitBreaker], trips servers whose error rate
+// exceeds threshold. Independently, when maxFailures and failCooldown are
+// set by [WithServerCooldown], it skips servers that failed maxFailures
+// times in a row.
+type serverHealth struct {
+	mu      sync.Mutex
+	windows map[string]*outcomeWindow // keyed by server address
+
+	threshold float64       // error rate above which a server is skipped; 0 disables the breaker
+	cooldown  time.Duration // how long a tripped server is skipped before a re-probe
+
+	maxFailures  int           // consecutive failures before a server is skipped; 0 disables
+	failCooldown time.Duration // how long a server is skipped after maxFailures failures
+}
+
+// record adds the outcome of a query against addr at now and updates the
+// circuit breaker state.
+func (h *serverHealth) record(addr string, ok bool, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.windows == nil {
+		h.windows = make(map[string]*outcomeWindow)
+	}
+	w, exists := h.windows[addr]
+	if !exists {
+		w = new(outcomeWindow)
+		h.windows[addr] = w
+	}
+
+	// A server still failing after its cooldown is skipped again at once;
+	// any success starts the count over.
+	if ok {
+		w.consecutive = 0
+	} else {
+		w.consecutive++
+		if h.maxFailures > 0 && w.consecutive >= h.maxFailures {
+			w.skipUntil = now.Add(h.failCooldown)
+		}
+	}
+
+	if w.halfOpen {
+		// The re-probe after the cooldown decides: a success starts the
+		// server over with a clean window, a failure skips it again.
+		if ok {
+			w.reset()
+			w.add(true)
+		} else {
+			w.add(false)
+			w.halfOpen = false
+			w.openUntil = now.Add(h.cooldown)
+		}
+		return
+	}
+
+	w.add(ok)
+	if h.threshold > 0 && w.n >= breakerMinSamples && 1-w.successRate() > h.threshold {
+		w.openUntil = now.Add(h.cooldown)
+	}
+}
+
+// allow reports whether addr may be queried at now. A server whose circuit
+// breaker cooldown has elapsed is let through once more, half-open.
+func (h *serverHealth) allow(addr string, now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	w := h.windows[addr]
+	if w == nil {
+		return true
+	}
+	if now.Before(w.skipUntil) {
+		return false
+	}
+	if w.openUntil.IsZero() {
+		return true
+	}
+	if now.Before(w.openUntil) {
+		return false
+	}
+	w.openUntil = time.Time{}
+	w.halfOpen = true
+	return true
+}
+
+// skipping reports whether the circuit breaker or the failure cooldown is
+// skipping addr at now. Unlike allow, it does not move an elapsed circuit
+// to half-open.
+func (h *serverHealth) skipping(addr string, now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	w := h.windows[addr]
+	if w == nil {
+		return false
+	}
+	return now.Before(w.skipUntil) || now.Before(w.openUntil)
+}
+
+// filter returns the servers the circuit breaker and the failure cooldown
+// let through at now. If every server is skipped the full list is returned
+// instead, so a check never fails because of them alone.
+func (h *serverHealth) filter(servers []DNSServer, now time.Time) []DNSServer {
+	if h.threshold <= 0 && h.maxFailures <= 0 {
+		return servers
+	}
+
+	allowed := make([]DNSServer, 0, len(servers))
+	for _, srv := range servers {
+		if h.allow(srv.Address, now) {
+			allowed = append(allowed, srv)
+		}
+	}
+	if len(allowed) == 0 {
+		return servers
+	}
+	return allowed
+}
+
+// ServerReliability reports the success rate of the most recent queries
+// (up to 50) against the server with the given address, and how many
+// queries that rate is based on. Each failover-loop query counts once, after
+// its retries: it succeeds when the server answered, including with
+// NXDOMAIN, REFUSED, or NODATA, and fails on timeouts, network errors, and
+// SERVFAIL. Queries aborted by the caller's context are not counted.
+//
+// A server without samples reports a rate of 1 and 0 samples. It is safe to
+// call concurrently with checks, and is the input [WithCircuitBreaker] uses.
+func (c *Checker) ServerReliability(address string) (successRate float64, samples int) {
+	c.health.mu.Lock()
+	defer c.health.mu.Unlock()
+
+	w := c.health.windows[address]
+	if w == nil {
+		// The address may be spelled differently from the configured one.
+		for addr, win := range c.health.windows {
+			if sameAddress(addr, address) {
+				w = win
+				break
+			}
+		}
+	}
+	if w == nil {
+		return 1, 0
+	}
+	return w.successRate(), w.n
+}
@@ -0,0 +1,289 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutcomeWindow(t *testing.T) {
+	var w outcomeWindow
+	assert.Equal(t, 1.0, w.successRate())
+
+	w.add(true)
+	w.add(false)
+	assert.Equal(t, 2, w.n)
+	assert.Equal(t, 0.5, w.successRate())
+
+	// Fill past the window: only the most recent outcomes count.
+	for range reliabilityWindow {
+		w.add(false)
+	}
+	assert.Equal(t, reliabilityWindow, w.n)
+	assert.Equal(t, 0.0, w.successRate())
+
+	for range reliabilityWindow / 2 {
+		w.add(true)
+	}
+	assert.Equal(t, 0.5, w.successRate())
+}
+
+// startFlakyDNSServer answers A queries normally while healthy is true and
+// with SERVFAIL otherwise, counting every query it receives.
+func startFlakyDNSServer(t *testing.T, healthy *atomic.Bool, queries *atomic.Int32) string {
+	t.Helper()
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		queries.Add(1)
+		m := new(dns.Msg)
+		m.SetReply(r)
+		if !healthy.Load() {
+			m.Rcode = dns.RcodeServerFailure
+		} else {
+			m.Answer = append(m.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   []byte{93, 184, 216, 34},
+			})
+		}
+		_ = w.WriteMsg(m)
+	})
+	addr, cleanup := startTestDNSServer(t, handler)
+	t.Cleanup(cleanup)
+	return addr
+}
+
+func TestServerReliability(t *testing.T) {
+	var healthy atomic.Bool
+	var queries atomic.Int32
+	addr := startFlakyDNSServer(t, &healthy, &queries)
+
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(0),
+		WithCache(nil),
+	)
+
+	rate, samples := c.ServerReliability(addr)
+	assert.Equal(t, 1.0, rate)
+	assert.Zero(t, samples)
+
+	healthy.Store(true)
+	for range 3 {
+		_, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+	}
+	healthy.Store(false)
+	r, err := c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.ErrorIs(t, r.Error, ErrAllDNSFailed)
+
+	rate, samples = c.ServerReliability(addr)
+	assert.Equal(t, 0.75, rate)
+	assert.Equal(t, 4, samples)
+
+	// Other spellings of the address report the same server.
+	_, samples = c.ServerReliability(" " + addr + " ")
+	assert.Equal(t, 4, samples)
+
+	// A cancelled check is not counted against the server.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _ = c.CheckOne(ctx, "example.com")
+	_, samples = c.ServerReliability(addr)
+	assert.Equal(t, 4, samples)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var primaryHealthy, secondaryHealthy atomic.Bool
+	var primaryQueries, secondaryQueries atomic.Int32
+	primary := startFlakyDNSServer(t, &primaryHealthy, &primaryQueries)
+	secondaryHealthy.Store(true)
+	secondary := startFlakyDNSServer(t, &secondaryHealthy, &secondaryQueries)
+
+	clock := newFakeClock()
+	c := New(
+		WithServers([]DNSServer{
+			{Address: primary, Keyword: "internetpositif", QueryType: "A"},
+			{Address: secondary, Keyword: "internetpositif", QueryType: "A"},
+		}),
+		WithMaxRetries(0),
+		WithCache(nil),
+		WithClock(clock),
+		WithCircuitBreaker(0.5, time.Minute),
+	)
+
+	check := func() {
+		t.Helper()
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		require.NoError(t, r.Error)
+		assert.Equal(t, secondary, r.Server)
+	}
+
+	// The primary fails until it has enough samples to trip.
+	for range breakerMinSamples {
+		check()
+	}
+	assert.EqualValues(t, breakerMinSamples, primaryQueries.Load())
+
+	// Tripped: the primary is skipped entirely.
+	check()
+	check()
+	assert.EqualValues(t, breakerMinSamples, primaryQueries.Load())
+
+	// After the cooldown it is re-probed once, fails, and is skipped again.
+	clock.Advance(time.Minute)
+	check()
+	check()
+	assert.EqualValues(t, breakerMinSamples+1, primaryQueries.Load())
+
+	// Once healthy, the next re-probe closes the circuit.
+	primaryHealthy.Store(true)
+	clock.Advance(time.Minute)
+	r, err := c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, primary, r.Server)
+	rate, samples := c.ServerReliability(primary)
+	assert.Equal(t, 1.0, rate)
+	assert.Equal(t, 1, samples)
+
+}
+
+func TestCircuitBreakerAllTripped(t *testing.T) {
+	var healthy atomic.Bool
+	var queries atomic.Int32
+	addr := startFlakyDNSServer(t, &healthy, &queries)
+
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(0),
+		WithCache(nil),
+		WithClock(newFakeClock()),
+		WithCircuitBreaker(0.5, time.Minute),
+	)
+
+	// With every server tripped, they are all queried anyway.
+	for range breakerMinSamples + 2 {
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		require.ErrorIs(t, r.Error, ErrAllDNSFailed)
+	}
+	assert.EqualValues(t, breakerMinSamples+2, queries.Load())
+}
+
+func TestWithCircuitBreakerDisabled(t *testing.T) {
+	for _, tt := range []struct {
+		threshold float64
+		cooldown  time.Duration
+	}{
+		{0, time.Minute},
+		{1, time.Minute},
+		{0.5, 0},
+	} {
+		c := New(WithCircuitBreaker(0.5, time.Minute), WithCircuitBreaker(tt.threshold, tt.cooldown))
+		assert.Zero(t, c.health.threshold)
+	}
+}
+
+func TestServerCooldown(t *testing.T) {
+	var primaryHealthy, secondaryHealthy atomic.Bool
+	var primaryQueries, secondaryQueries atomic.Int32
+	primary := startFlakyDNSServer(t, &primaryHealthy, &primaryQueries)
+	secondaryHealthy.Store(true)
+	secondary := startFlakyDNSServer(t, &secondaryHealthy, &secondaryQueries)
+
+	clock := newFakeClock()
+	c := New(
+		WithServers([]DNSServer{
+			{Address: primary, Keyword: "internetpositif", QueryType: "A"},
+			{Address: secondary, Keyword: "internetpositif", QueryType: "A"},
+		}),
+		WithMaxRetries(0),
+		WithCache(nil),
+		WithClock(clock),
+		WithServerCooldown(3, time.Minute),
+	)
+
+	check := func() Result {
+		t.Helper()
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		require.NoError(t, r.Error)
+		return r
+	}
+
+	// Two failures, then a success resets the count.
+	check()
+	check()
+	primaryHealthy.Store(true)
+	assert.Equal(t, primary, check().Server)
+	primaryHealthy.Store(false)
+
+	// Three failures in a row put the primary on cooldown.
+	for range 3 {
+		assert.Equal(t, secondary, check().Server)
+	}
+	assert.EqualValues(t, 6, primaryQueries.Load())
+
+	check()
+	check()
+	assert.EqualValues(t, 6, primaryQueries.Load(), "skipped during the cooldown")
+
+	// Still failing after the cooldown: skipped again after one query.
+	clock.Advance(time.Minute)
+	check()
+	check()
+	assert.EqualValues(t, 7, primaryQueries.Load())
+
+	// Healthy again after the next cooldown.
+	primaryHealthy.Store(true)
+	clock.Advance(time.Minute)
+	assert.Equal(t, primary, check().Server)
+	assert.Equal(t, primary, check().Server)
+}
+
+func TestServerCooldownAllSkipped(t *testing.T) {
+	var healthy atomic.Bool
+	var queries atomic.Int32
+	addr := startFlakyDNSServer(t, &healthy, &queries)
+
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(0),
+		WithCache(nil),
+		WithClock(newFakeClock()),
+		WithServerCooldown(1, time.Minute),
+	)
+
+	// With every server skipped, they are all queried anyway.
+	for range 3 {
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		require.ErrorIs(t, r.Error, ErrAllDNSFailed)
+	}
+	assert.EqualValues(t, 3, queries.Load())
+}
+
+func TestWithServerCooldownDisabled(t *testing.T) {
+	for _, tt := range []struct {
+		failures int
+		cooldown time.Duration
+	}{
+		{0, time.Minute},
+		{-1, time.Minute},
+		{3, 0},
+	} {
+		c := New(WithServerCooldown(3, time.Minute), WithServerCooldown(tt.failures, tt.cooldown))
+		assert.Zero(t, c.health.maxFailures)
+		assert.Zero(t, c.health.failCooldown)
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import "sync/atomic"
+
+// attemptBudget is the number of DNS queries a single domain check may
+// still make across all of its servers, set by [WithMaxTotalAttempts]. It is
+// shared by concurrent probes and servers. A nil budget is unlimited.
+type attemptBudget struct {
+	left atomic.Int64
+}
+
+// newAttemptBudget returns the query budget for one domain check, or nil
+// when [WithMaxTotalAttempts] is not set.
+func (c *Checker) newAttemptBudget() *attemptBudget {
+	if c.maxTotalAttempts <= 0 {
+		return nil
+	}
+	b := new(attemptBudget)
+	b.left.Store(int64(c.maxTotalAttempts))
+	return b
+}
+
+// take reserves one query, reporting false once the budget is spent.
+func (b *attemptBudget) take() bool {
+	return b == nil || b.left.Add(-1) >= 0
+}
+
+// spent reports whether no query is left.
+func (b *attemptBudget) spent() bool {
+	return b != nil && b.left.Load() <= 0
+}
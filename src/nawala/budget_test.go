@@ -0,0 +1,147 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttemptBudget(t *testing.T) {
+	var unlimited *attemptBudget
+	for range 100 {
+		assert.True(t, unlimited.take())
+	}
+	assert.False(t, unlimited.spent())
+
+	c := New(WithMaxTotalAttempts(2))
+	b := c.newAttemptBudget()
+	assert.False(t, b.spent())
+	assert.True(t, b.take())
+	assert.True(t, b.take())
+	assert.True(t, b.spent())
+	assert.False(t, b.take())
+	assert.False(t, b.take())
+
+	assert.Nil(t, New().newAttemptBudget())
+	assert.Nil(t, New(WithMaxTotalAttempts(-1)).newAttemptBudget())
+}
+
+func TestWithMaxTotalAttempts(t *testing.T) {
+	ctx := context.Background()
+
+	// newFailing returns three always-failing servers sharing one query
+	// counter.
+	newFailing := func(t *testing.T) ([]DNSServer, *atomic.Int32) {
+		var healthy atomic.Bool
+		queries := new(atomic.Int32)
+		servers := make([]DNSServer, 3)
+		for i := range servers {
+			servers[i] = DNSServer{
+				Address:   startFlakyDNSServer(t, &healthy, queries),
+				Keyword:   "internetpositif",
+				QueryType: "A",
+			}
+		}
+		return servers, queries
+	}
+
+	t.Run("unlimited", func(t *testing.T) {
+		servers, queries := newFailing(t)
+		c := New(WithServers(servers), WithMaxRetries(2), WithClock(newFakeClock()))
+
+		result, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		assert.ErrorIs(t, result.Error, ErrAllDNSFailed)
+		assert.NotErrorIs(t, result.Error, ErrAttemptsExhausted)
+		assert.Equal(t, int32(9), queries.Load())
+	})
+
+	t.Run("capped failover", func(t *testing.T) {
+		servers, queries := newFailing(t)
+		c := New(
+			WithServers(servers),
+			WithMaxRetries(2),
+			WithMaxTotalAttempts(4),
+			WithClock(newFakeClock()),
+		)
+
+		result, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		assert.ErrorIs(t, result.Error, ErrAllDNSFailed)
+		assert.ErrorIs(t, result.Error, ErrServerFailure)
+		assert.ErrorIs(t, result.Error, ErrAttemptsExhausted, "the third server was skipped")
+		assert.Equal(t, int32(4), queries.Load())
+
+		// The budget is per check, not per checker.
+		_, err = c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		assert.Equal(t, int32(8), queries.Load())
+	})
+
+	t.Run("parallel probes", func(t *testing.T) {
+		servers, queries := newFailing(t)
+		c := New(
+			WithServers(servers),
+			WithMaxRetries(2),
+			WithParallelProbes(true),
+			WithMaxTotalAttempts(5),
+		)
+
+		result, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		assert.ErrorIs(t, result.Error, ErrAllDNSFailed)
+		assert.Equal(t, int32(5), queries.Load())
+	})
+
+	t.Run("race", func(t *testing.T) {
+		servers, queries := newFailing(t)
+		c := New(
+			WithServers(servers),
+			WithMaxRetries(2),
+			WithRaceServers(true),
+			WithMaxTotalAttempts(4),
+			WithClock(newFakeClock()),
+		)
+
+		result, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		assert.ErrorIs(t, result.Error, ErrAllDNSFailed)
+		assert.Equal(t, int32(4), queries.Load())
+	})
+
+	t.Run("verdict kept", func(t *testing.T) {
+		var queries atomic.Int32
+		addr, cleanup := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			queries.Add(1)
+			m := new(dns.Msg)
+			m.SetReply(r)
+			m.Answer = append(m.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   []byte{93, 184, 216, 34},
+			})
+			_ = w.WriteMsg(m)
+		}))
+		defer cleanup()
+
+		c := New(
+			WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithMaxRetries(4),
+			WithMaxTotalAttempts(2),
+		)
+		result, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		require.NoError(t, result.Error)
+		assert.False(t, result.Blocked)
+		assert.Equal(t, 2, result.Attempts)
+		assert.Equal(t, int32(2), queries.Load())
+	})
+}
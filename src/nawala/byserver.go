@@ -0,0 +1,119 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// CheckByServer checks every domain against every configured server
+// individually, without failover, and groups the results by
+// [DNSServer.Address]. Each server's slice is positional, matching domains.
+//
+// Where [Checker.Check] reports one verdict per domain, CheckByServer reveals
+// how the resolvers diverge (server A blocks a domain that server B does
+// not), which is the core of censorship measurement:
+//
+//	byServer := c.CheckByServer(ctx, "example.com", "reddit.com")
+//	for addr, results := range byServer {
+//	    blocked := 0
+//	    for _, r := range results {
+//	        if r.Error == nil && r.Blocked {
+//	            blocked++
+//	        }
+//	    }
+//	    fmt.Printf("%s blocks %d/%d domains\n", addr, blocked, len(results))
+//	}
+//
+// The whole domain×server matrix shares the semaphore sized by
+// [WithConcurrency]. A server that fails for a domain reports
+// [ErrAllDNSFailed] for it (or a degraded result with [WithFailOpen]), since
+// no other server is tried. If ctx is done
+// before every check has started, the remaining results carry the context
+// error, and after [Checker.Shutdown] every result carries [ErrClosing].
+// With no servers configured the map is empty.
+func (c *Checker) CheckByServer(ctx context.Context, domains ...string) map[string][]Result {
+	servers := c.snapshotServers(checkOptions{})
+
+	byServer := make(map[string][]Result, len(servers))
+	for _, srv := range servers {
+		byServer[srv.Address] = make([]Result, len(domains))
+	}
+
+	if err := c.lifecycle.begin(); err != nil {
+		for addr, results := range byServer {
+			for i, d := range domains {
+				results[i] = Result{Domain: d, Server: addr, Error: err}
+			}
+		}
+		return byServer
+	}
+	defer c.lifecycle.end()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, c.Concurrency())
+
+	// fail fills every result not yet started, from server si and domain di
+	// onwards, with the context error.
+	fail := func(si, di int) {
+		for ; si < len(servers); si++ {
+			results := byServer[servers[si].Address]
+			for ; di < len(domains); di++ {
+				results[di] = Result{
+					Domain: domains[di],
+					Server: servers[si].Address,
+					Error:  contextError(ctx.Err()),
+				}
+			}
+			di = 0
+		}
+	}
+
+Loop:
+	for si, srv := range servers {
+		results := byServer[srv.Address]
+		for di, domain := range domains {
+			// Same priority check as in [Checker.Check]: do not let select
+			// pick the semaphore when the context is already done.
+			select {
+			case <-ctx.Done():
+				fail(si, di)
+				break Loop
+			default:
+			}
+
+			select {
+			case <-ctx.Done():
+				fail(si, di)
+				break Loop
+			case sem <- struct{}{}:
+			}
+
+			wg.Add(1)
+
+			go func(idx int, d, addr string) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				defer func() {
+					if r := recover(); r != nil {
+						results[idx] = Result{
+							Domain: d,
+							Server: addr,
+							Error:  fmt.Errorf("%w: %v", ErrInternalPanic, r),
+						}
+					}
+				}()
+
+				results[idx] = c.checkSingle(ctx, d, checkOptions{addresses: []string{addr}})
+			}(di, domain, srv.Address)
+		}
+	}
+
+	wg.Wait()
+	return byServer
+}
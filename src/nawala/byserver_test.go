@@ -0,0 +1,78 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckByServer(t *testing.T) {
+	blocking, cleanupBlocking := startBlockingDNSServer(t)
+	defer cleanupBlocking()
+	normal, cleanupNormal := startNormalDNSServer(t)
+	defer cleanupNormal()
+
+	c := New(
+		WithServers([]DNSServer{
+			{Address: blocking, Keyword: "internetpositif", QueryType: "A"},
+			{Address: normal, Keyword: "internetpositif", QueryType: "A"},
+		}),
+		WithConcurrency(1), // the semaphore must cover the whole matrix
+	)
+
+	domains := []string{"example.com", "invalid", "reddit.com"}
+	byServer := c.CheckByServer(context.Background(), domains...)
+	require.Len(t, byServer, 2)
+
+	for addr, wantBlocked := range map[string]bool{blocking: true, normal: false} {
+		results := byServer[addr]
+		require.Len(t, results, len(domains), addr)
+
+		for i, r := range results {
+			if domains[i] == "invalid" {
+				assert.ErrorIs(t, r.Error, ErrInvalidDomain)
+				continue
+			}
+			require.NoError(t, r.Error, addr)
+			assert.Equal(t, domains[i], r.Domain)
+			assert.Equal(t, addr, r.Server, "no failover to the other server")
+			assert.Equal(t, wantBlocked, r.Blocked, addr)
+		}
+	}
+}
+
+func TestCheckByServerCancelled(t *testing.T) {
+	addr, cleanup := startNormalDNSServer(t)
+	defer cleanup()
+
+	c := New(WithServers([]DNSServer{
+		{Address: addr, Keyword: "internetpositif", QueryType: "A"},
+		{Address: "192.0.2.1", Keyword: "internetpositif", QueryType: "A"},
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	byServer := c.CheckByServer(ctx, "example.com", "reddit.com")
+	require.Len(t, byServer, 2)
+	for srv, results := range byServer {
+		require.Len(t, results, 2)
+		for _, r := range results {
+			assert.ErrorIs(t, r.Error, context.Canceled)
+			assert.Equal(t, srv, r.Server)
+		}
+	}
+}
+
+func TestCheckByServerNoServers(t *testing.T) {
+	c := New()
+	c.ReplaceServers(nil)
+	assert.Empty(t, c.CheckByServer(context.Background(), "example.com"))
+}
@@ -6,6 +6,8 @@
 package nawala
 
 import (
+	"context"
+	"hash/maphash"
 	"sync"
 	"time"
 )
@@ -26,46 +28,121 @@ type Cache interface {
 	Flush()
 }
 
+// ttlCache is an optional interface a [Cache] may implement to accept a
+// per-entry TTL. When the configured cache satisfies it, the checker stores
+// results through SetWithTTL when [WithCacheMinTTL] or [WithCacheMaxTTL] is
+// set, with each entry's expiration taken from the response TTL and bounded.
+// Caches that only implement [Cache] keep using their own TTL via Set.
+type ttlCache interface {
+	SetWithTTL(key string, val Result, ttl time.Duration)
+}
+
+// contextFlusher is an optional interface a [Cache] may implement to support
+// a cancellable flush, typically a network backend such as Redis. When the
+// configured cache satisfies it, [Checker.FlushContext] calls FlushContext
+// instead of Flush.
+type contextFlusher interface {
+	FlushContext(ctx context.Context) error
+}
+
+// cacheLener is an optional interface a [Cache] may implement to report its
+// number of entries, surfaced through [Checker.CacheLen].
+type cacheLener interface {
+	Len() int
+}
+
 // cacheEntry holds a cached result with its expiration time.
 type cacheEntry struct {
 	result    Result
+	storedAt  time.Time
 	expiresAt time.Time
 }
 
 // memoryCache is the default in-memory cache implementation with TTL support.
+//
+// Entries are spread over independently locked shards, picked by hashing the
+// key, so concurrent checks touching different keys rarely contend on the
+// same mutex. The shard count is set with [WithCacheShards].
 type memoryCache struct {
+	shards []cacheShard
+	seed   maphash.Seed
+	ttl    time.Duration
+	stale  time.Duration // grace period past expiry during which entries are served as stale
+	clock  Clock
+}
+
+// cacheShard is one independently locked partition of a [memoryCache].
+type cacheShard struct {
 	mu      sync.RWMutex
 	entries map[string]cacheEntry
-	ttl     time.Duration
 }
 
-// newMemoryCache creates a new in-memory cache with the given TTL.
-func newMemoryCache(ttl time.Duration) *memoryCache {
-	return &memoryCache{
-		entries: make(map[string]cacheEntry),
-		ttl:     ttl,
+// newMemoryCache creates a new in-memory cache with the given TTL, split
+// into n shards. Values of n < 1 are treated as 1.
+func newMemoryCache(ttl time.Duration, n int) *memoryCache {
+	n = max(n, 1)
+	c := &memoryCache{
+		shards: make([]cacheShard, n),
+		seed:   maphash.MakeSeed(),
+		ttl:    ttl,
+		clock:  realClock{},
+	}
+	for i := range c.shards {
+		c.shards[i].entries = make(map[string]cacheEntry)
+	}
+	return c
+}
+
+// agedTTL returns what remains of a record TTL, in seconds, after age has
+// passed, never going below zero.
+func agedTTL(ttl uint32, age time.Duration) uint32 {
+	elapsed := age / time.Second
+	if elapsed >= time.Duration(ttl) {
+		return 0
 	}
+	return ttl - uint32(elapsed)
+}
+
+// shard returns the shard responsible for key.
+func (c *memoryCache) shard(key string) *cacheShard {
+	if len(c.shards) == 1 {
+		return &c.shards[0]
+	}
+	return &c.shards[maphash.String(c.seed, key)%uint64(len(c.shards))]
 }
 
 // Get retrieves a cached result by key.
 // Returns false if the entry does not exist or has expired.
+//
+// When a stale window is configured (see [WithStaleWhileRevalidate]), an
+// entry past its expiry but still within the window is returned with
+// [Result.Stale] set.
 func (c *memoryCache) Get(key string) (Result, bool) {
-	c.mu.RLock()
-	entry, ok := c.entries[key]
-	c.mu.RUnlock()
+	s := c.shard(key)
+
+	s.mu.RLock()
+	entry, ok := s.entries[key]
+	s.mu.RUnlock()
 
 	if !ok {
 		return Result{}, false
 	}
 
-	if time.Now().After(entry.expiresAt) {
+	now := c.clock.Now()
+	entry.result.TTL = agedTTL(entry.result.TTL, now.Sub(entry.storedAt))
+	if now.After(entry.expiresAt) && !now.After(entry.expiresAt.Add(c.stale)) {
+		entry.result.Stale = true
+		return entry.result, true
+	}
+
+	if now.After(entry.expiresAt) {
 		// Lazily remove expired entries.
-		c.mu.Lock()
+		s.mu.Lock()
 		// Double-check locking: verify the entry hasn't changed while we defied the lock.
-		if currentEntry, exists := c.entries[key]; exists && currentEntry.expiresAt.Equal(entry.expiresAt) {
-			delete(c.entries, key)
+		if currentEntry, exists := s.entries[key]; exists && currentEntry.expiresAt.Equal(entry.expiresAt) {
+			delete(s.entries, key)
 		}
-		c.mu.Unlock()
+		s.mu.Unlock()
 		return Result{}, false
 	}
 
@@ -74,17 +151,42 @@ func (c *memoryCache) Get(key string) (Result, bool) {
 
 // Set stores a result in the cache with the configured TTL.
 func (c *memoryCache) Set(key string, val Result) {
-	c.mu.Lock()
-	c.entries[key] = cacheEntry{
+	c.SetWithTTL(key, val, c.ttl)
+}
+
+// SetWithTTL stores a result in the cache with an explicit TTL,
+// overriding the configured one for this entry only.
+func (c *memoryCache) SetWithTTL(key string, val Result, ttl time.Duration) {
+	s := c.shard(key)
+	s.mu.Lock()
+	now := c.clock.Now()
+	s.entries[key] = cacheEntry{
 		result:    val,
-		expiresAt: time.Now().Add(c.ttl),
+		storedAt:  now,
+		expiresAt: now.Add(ttl),
 	}
-	c.mu.Unlock()
+	s.mu.Unlock()
 }
 
-// Flush removes all entries from the cache.
+// Flush removes all entries from the cache, one shard at a time.
 func (c *memoryCache) Flush() {
-	c.mu.Lock()
-	c.entries = make(map[string]cacheEntry)
-	c.mu.Unlock()
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		s.entries = make(map[string]cacheEntry)
+		s.mu.Unlock()
+	}
+}
+
+// Len returns the number of entries currently held by the cache.
+// Expired entries that have not yet been lazily removed are included.
+func (c *memoryCache) Len() int {
+	n := 0
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.RLock()
+		n += len(s.entries)
+		s.mu.RUnlock()
+	}
+	return n
 }
@@ -9,6 +9,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"net"
 	"sync"
 	"testing"
@@ -95,7 +96,7 @@ func startSimpleDNSServer(t *testing.T) (addr string, cleanup func()) {
 func newCapturedCache(ttl time.Duration) (cacheWrapper, *capturedKeyCache) {
 	captured := &capturedKeyCache{}
 	wrapped := cacheWrapper{
-		inner: newMemoryCache(ttl),
+		inner: newMemoryCache(ttl, defaultCacheShards),
 		onSet: func(key string) {
 			captured.Lock()
 			captured.vals = append(captured.vals, key)
@@ -392,3 +393,53 @@ func TestWithDigestsDeterminism(t *testing.T) {
 			"single and double SHA-256 must produce different digests for the same input")
 	})
 }
+
+// TestCacheKeyMatchesSprintf verifies that cache keys stay byte-identical
+// to the fmt.Sprintf form they were originally built with, so entries
+// stored in a shared cache by earlier versions are still found.
+func TestCacheKeyMatchesSprintf(t *testing.T) {
+	servers := []DNSServer{
+		{Address: "180.131.144.144", Keyword: "internetpositif"},
+		{Address: "[2001:db8::1]:5353", Keyword: "trustpositif"},
+		{Address: "dns.example.com:10000", Keyword: ""},
+		{Address: "", Keyword: "ключ:ü"},
+	}
+	qtypes := []uint16{0, dns.TypeA, dns.TypeANY, dns.TypePTR, 65535}
+
+	for _, hash := range []func(string) string{nil, hashSHA256} {
+		c := New(WithDigests(hash))
+		for _, domain := range []string{"example.com", "", "xn--bcher-kva.example"} {
+			for _, srv := range servers {
+				for _, qtype := range qtypes {
+					raw := fmt.Sprintf("%s:%s:%s:%d", domain, srv.Address, srv.Keyword, qtype)
+					want := cacheKeyPrefix + raw
+					if hash != nil {
+						want = cacheKeyPrefix + hash(raw)
+					}
+					assert.Equal(t, want, c.cacheKey(domain, srv, qtype))
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkCacheKey compares building a cache key with fmt.Sprintf, as
+// checkSingle used to, with [rawCacheKey].
+func BenchmarkCacheKey(b *testing.B) {
+	srv := DNSServer{Address: "180.131.144.144", Keyword: "internetpositif"}
+
+	b.Run("sprintf", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = cacheKeyPrefix + fmt.Sprintf("%s:%s:%s:%d", "example.com", srv.Address, srv.Keyword, dns.TypeA)
+		}
+	})
+
+	b.Run("builder", func(b *testing.B) {
+		c := New()
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = c.cacheKey("example.com", srv, dns.TypeA)
+		}
+	})
+}
@@ -6,15 +6,22 @@
 package nawala
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestMemoryCacheGetSet(t *testing.T) {
-	c := newMemoryCache(5 * time.Minute)
+	c := newMemoryCache(5*time.Minute, defaultCacheShards)
 
 	// Miss on empty cache.
 	_, ok := c.Get("miss")
@@ -31,8 +38,45 @@ func TestMemoryCacheGetSet(t *testing.T) {
 	assert.Equal(t, want.Server, got.Server)
 }
 
+// fakeClock is a [Clock] whose time only moves when advanced. After fires
+// immediately, advancing the clock by the requested duration and recording
+// it, so backoff can be tested without sleeping.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	waited []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	f.waited = append(f.waited, d)
+	ch := make(chan time.Time, 1)
+	ch <- f.now
+	return ch
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
 func TestMemoryCacheExpiration(t *testing.T) {
-	c := newMemoryCache(50 * time.Millisecond)
+	clock := newFakeClock()
+	c := newMemoryCache(50*time.Millisecond, defaultCacheShards)
+	c.clock = clock
 
 	c.Set("expiring", Result{Domain: "test.com"})
 
@@ -40,21 +84,25 @@ func TestMemoryCacheExpiration(t *testing.T) {
 	_, ok := c.Get("expiring")
 	require.True(t, ok, "expected hit before expiration")
 
-	// Wait for expiration.
-	time.Sleep(100 * time.Millisecond)
+	clock.Advance(50 * time.Millisecond)
+	_, ok = c.Get("expiring")
+	require.True(t, ok, "expected hit at the expiry instant")
+
+	clock.Advance(time.Millisecond)
 
 	_, ok = c.Get("expiring")
 	assert.False(t, ok, "expected miss after expiration")
 
 	// Verify the expired entry was lazily deleted.
-	c.mu.RLock()
-	_, exists := c.entries["expiring"]
-	c.mu.RUnlock()
+	s := c.shard("expiring")
+	s.mu.RLock()
+	_, exists := s.entries["expiring"]
+	s.mu.RUnlock()
 	assert.False(t, exists, "expected expired entry to be lazily deleted")
 }
 
 func TestMemoryCacheFlush(t *testing.T) {
-	c := newMemoryCache(5 * time.Minute)
+	c := newMemoryCache(5*time.Minute, defaultCacheShards)
 
 	c.Set("a", Result{Domain: "a.com"})
 	c.Set("b", Result{Domain: "b.com"})
@@ -67,3 +115,363 @@ func TestMemoryCacheFlush(t *testing.T) {
 	_, ok = c.Get("b")
 	assert.False(t, ok, "expected miss after Flush for key 'b'")
 }
+
+func TestMemoryCacheSetWithTTL(t *testing.T) {
+	c := newMemoryCache(5*time.Minute, defaultCacheShards)
+
+	c.SetWithTTL("short", Result{Domain: "short.com"}, -time.Second)
+	_, ok := c.Get("short")
+	assert.False(t, ok, "expected miss for already-expired entry")
+
+	c.SetWithTTL("long", Result{Domain: "long.com"}, time.Hour)
+	_, ok = c.Get("long")
+	assert.True(t, ok, "expected hit for entry with explicit TTL")
+}
+
+func TestMemoryCacheStale(t *testing.T) {
+	c := newMemoryCache(5*time.Minute, defaultCacheShards)
+	c.stale = time.Hour
+
+	c.SetWithTTL("fresh", Result{Domain: "fresh.com"}, time.Hour)
+	got, ok := c.Get("fresh")
+	require.True(t, ok)
+	assert.False(t, got.Stale)
+
+	c.SetWithTTL("stale", Result{Domain: "stale.com"}, -time.Minute)
+	got, ok = c.Get("stale")
+	require.True(t, ok, "expected hit within the stale window")
+	assert.True(t, got.Stale)
+
+	c.SetWithTTL("gone", Result{Domain: "gone.com"}, -2*time.Hour)
+	_, ok = c.Get("gone")
+	assert.False(t, ok, "expected miss past the stale window")
+}
+
+func TestMemoryCacheAgesTTL(t *testing.T) {
+	c := newMemoryCache(5*time.Minute, defaultCacheShards)
+	c.Set("a", Result{Domain: "a.com", TTL: 60})
+
+	// Backdate the entry to simulate 45 seconds in the cache.
+	s := c.shard("a")
+	entry := s.entries["a"]
+	entry.storedAt = entry.storedAt.Add(-45 * time.Second)
+	s.entries["a"] = entry
+
+	got, ok := c.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, uint32(15), got.TTL)
+
+	assert.Equal(t, uint32(60), agedTTL(60, 999*time.Millisecond))
+	assert.Equal(t, uint32(59), agedTTL(60, time.Second))
+	assert.Equal(t, uint32(0), agedTTL(60, time.Hour))
+	assert.Equal(t, uint32(0), agedTTL(0, 0))
+}
+
+func TestWithClockCache(t *testing.T) {
+	clock := newFakeClock()
+	c := New(WithClock(clock), WithCacheTTL(time.Minute), WithClock(nil))
+
+	c.cache.Set("a", Result{Domain: "a.com"})
+	clock.Advance(59 * time.Second)
+	_, ok := c.cache.Get("a")
+	assert.True(t, ok)
+
+	clock.Advance(2 * time.Second)
+	_, ok = c.cache.Get("a")
+	assert.False(t, ok)
+}
+
+// ctxFlushCache records FlushContext calls on top of the built-in cache.
+type ctxFlushCache struct {
+	*memoryCache
+	flushed int
+	err     error
+}
+
+func (c *ctxFlushCache) FlushContext(ctx context.Context) error {
+	c.flushed++
+	if c.err != nil {
+		return c.err
+	}
+	c.Flush()
+	return nil
+}
+
+func TestFlushContext(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("falls back to Flush", func(t *testing.T) {
+		c := New()
+		c.cache.Set("a", Result{Domain: "a.com"})
+		require.NoError(t, c.FlushContext(ctx))
+		_, ok := c.cache.Get("a")
+		assert.False(t, ok)
+	})
+
+	t.Run("uses FlushContext when implemented", func(t *testing.T) {
+		backend := &ctxFlushCache{memoryCache: newMemoryCache(time.Minute, 1)}
+		c := New(WithCache(backend))
+		require.NoError(t, c.FlushContext(ctx))
+		assert.Equal(t, 1, backend.flushed)
+
+		backend.err = errors.New("redis: connection refused")
+		assert.ErrorIs(t, c.FlushContext(ctx), backend.err)
+	})
+
+	t.Run("done context", func(t *testing.T) {
+		c := New()
+		c.cache.Set("a", Result{Domain: "a.com"})
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.ErrorIs(t, c.FlushContext(cancelled), ErrCanceled)
+		_, ok := c.cache.Get("a")
+		assert.True(t, ok, "a cancelled flush leaves the cache intact")
+	})
+
+	t.Run("caching disabled", func(t *testing.T) {
+		assert.NoError(t, New(WithCache(nil)).FlushContext(ctx))
+	})
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	var queries atomic.Int32
+	addr, cleanup := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		queries.Add(1)
+		m := new(dns.Msg)
+		m.SetReply(r)
+		_ = w.WriteMsg(m)
+	}))
+	defer cleanup()
+
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithStaleWhileRevalidate(50*time.Millisecond, time.Hour),
+		WithMaxRetries(0),
+	)
+	ctx := context.Background()
+
+	first, err := c.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	assert.False(t, first.Stale)
+	require.Equal(t, int32(1), queries.Load())
+
+	time.Sleep(100 * time.Millisecond)
+
+	stale, err := c.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	assert.True(t, stale.Stale, "expected the expired entry to be served as stale")
+
+	// Close waits for the background refresh.
+	require.NoError(t, c.Close())
+	assert.Equal(t, int32(2), queries.Load())
+
+	refreshed, err := c.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	assert.False(t, refreshed.Stale, "expected the refreshed entry to be fresh")
+	assert.Equal(t, int32(2), queries.Load())
+}
+
+func TestCheckerClampTTL(t *testing.T) {
+	c := New(WithCacheMinTTL(time.Minute), WithCacheMaxTTL(time.Hour))
+
+	assert.Equal(t, time.Minute, c.clampTTL(0))
+	assert.Equal(t, 30*time.Minute, c.clampTTL(30*time.Minute))
+	assert.Equal(t, time.Hour, c.clampTTL(604800*time.Second))
+
+	unbounded := New(WithCacheMinTTL(-time.Second), WithCacheMaxTTL(0))
+	assert.Equal(t, time.Duration(0), unbounded.clampTTL(0))
+	assert.Equal(t, 7*24*time.Hour, unbounded.clampTTL(7*24*time.Hour))
+}
+
+func TestCheckerStoreResultClamped(t *testing.T) {
+	clock := newFakeClock()
+	c := New(WithClock(clock), WithCacheMinTTL(time.Minute), WithCacheMaxTTL(time.Hour))
+
+	// A TTL-0 answer would expire immediately; the min bound keeps it.
+	c.storeResult("zero", Result{Domain: "zero.example", TTL: 0})
+	// An absurd TTL is cut down to the max bound.
+	c.storeResult("week", Result{Domain: "week.example", TTL: 604800})
+	// A TTL within the bounds is honored.
+	c.storeResult("tenmin", Result{Domain: "tenmin.example", TTL: 600})
+
+	clock.Advance(59 * time.Second)
+	got, ok := c.cache.Get("zero")
+	require.True(t, ok, "expected entry to survive thanks to the min TTL clamp")
+	assert.Equal(t, "zero.example", got.Domain)
+
+	clock.Advance(2 * time.Second)
+	_, ok = c.cache.Get("zero")
+	assert.False(t, ok, "raised to the min bound, not beyond it")
+
+	clock.Advance(9 * time.Minute)
+	_, ok = c.cache.Get("tenmin")
+	assert.False(t, ok, "expected the response TTL, not the default cache TTL")
+	_, ok = c.cache.Get("week")
+	assert.True(t, ok)
+
+	clock.Advance(50 * time.Minute)
+	_, ok = c.cache.Get("week")
+	assert.False(t, ok, "expected the max TTL clamp")
+}
+
+func TestCacheMinTTLZeroTTLAnswer(t *testing.T) {
+	var queries atomic.Int32
+	addr, cleanup := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		queries.Add(1)
+		m := new(dns.Msg)
+		m.SetReply(r)
+		m.Answer = append(m.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 0},
+			A:   net.IPv4(93, 184, 216, 34),
+		})
+		_ = w.WriteMsg(m)
+	}))
+	defer cleanup()
+
+	clock := newFakeClock()
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(0),
+		WithClock(clock),
+		WithCacheTTL(time.Hour),
+		WithCacheMinTTL(30*time.Second),
+	)
+	ctx := context.Background()
+
+	_, err := c.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	clock.Advance(20 * time.Second)
+	_, err = c.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), queries.Load(), "the TTL-0 answer is cached for the min TTL")
+
+	clock.Advance(20 * time.Second)
+	_, err = c.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), queries.Load(), "the min TTL, not WithCacheTTL, bounds the entry")
+}
+
+func TestMemoryCacheLen(t *testing.T) {
+	c := newMemoryCache(5*time.Minute, defaultCacheShards)
+	assert.Equal(t, 0, c.Len())
+
+	c.Set("a", Result{Domain: "a.com"})
+	c.Set("b", Result{Domain: "b.com"})
+	c.Set("a", Result{Domain: "a.com"})
+	assert.Equal(t, 2, c.Len())
+
+	c.Flush()
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestCheckerCacheLen(t *testing.T) {
+	c := New()
+	n, ok := c.CacheLen()
+	require.True(t, ok)
+	assert.Equal(t, 0, n)
+
+	c.storeResult("key", Result{Domain: "example.com"})
+	n, ok = c.CacheLen()
+	require.True(t, ok)
+	assert.Equal(t, 1, n)
+
+	// Disabled cache.
+	n, ok = New(WithCache(nil)).CacheLen()
+	assert.False(t, ok)
+	assert.Equal(t, 0, n)
+
+	// Custom cache without Len.
+	_, ok = New(WithCache(&panicCache{})).CacheLen()
+	assert.False(t, ok)
+}
+
+func TestMemoryCacheShards(t *testing.T) {
+	c := newMemoryCache(5*time.Minute, 8)
+	require.Len(t, c.shards, 8)
+
+	for i := range 100 {
+		c.Set(fmt.Sprintf("key-%d", i), Result{Domain: fmt.Sprintf("%d.com", i)})
+	}
+	assert.Equal(t, 100, c.Len())
+
+	used := 0
+	for i := range c.shards {
+		if len(c.shards[i].entries) > 0 {
+			used++
+		}
+	}
+	assert.Greater(t, used, 1, "keys should spread over several shards")
+
+	got, ok := c.Get("key-42")
+	require.True(t, ok)
+	assert.Equal(t, "42.com", got.Domain)
+
+	c.Flush()
+	assert.Equal(t, 0, c.Len())
+
+	// Non-positive shard counts fall back to a single shard.
+	assert.Len(t, newMemoryCache(time.Minute, 0).shards, 1)
+}
+
+func TestWithCacheShards(t *testing.T) {
+	c := New(WithCacheShards(4))
+	mc, ok := c.cache.(*memoryCache)
+	require.True(t, ok)
+	assert.Len(t, mc.shards, 4)
+
+	mc, ok = New(WithCacheShards(-1)).cache.(*memoryCache)
+	require.True(t, ok)
+	assert.Len(t, mc.shards, 1)
+}
+
+// BenchmarkCacheConcurrent measures mixed Get/Set throughput under parallel
+// load for a single-lock cache versus the default sharded cache.
+func BenchmarkCacheConcurrent(b *testing.B) {
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%sexample-%d.com:180.131.144.144:internetpositif:1", cacheKeyPrefix, i)
+	}
+
+	for _, shards := range []int{1, defaultCacheShards} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			c := newMemoryCache(5*time.Minute, shards)
+			for _, k := range keys {
+				c.Set(k, Result{Domain: k})
+			}
+
+			var seed atomic.Int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				// Start each goroutine at a different key so they do not
+				// walk the key space in lockstep.
+				i := int(seed.Add(97))
+				for pb.Next() {
+					k := keys[i%len(keys)]
+					if i%4 == 0 {
+						c.Set(k, Result{Domain: k})
+					} else {
+						c.Get(k)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkCacheGetSet measures a single-goroutine Set followed by a Get hit
+// on the default in-memory cache.
+func BenchmarkCacheGetSet(b *testing.B) {
+	c := newMemoryCache(5*time.Minute, defaultCacheShards)
+	key := cacheKeyPrefix + "example.com:180.131.144.144:internetpositif:1"
+	want := Result{Domain: "example.com", Server: "180.131.144.144", Blocked: true}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		c.Set(key, want)
+		if _, ok := c.Get(key); !ok {
+			b.Fatal("expected hit after Set")
+		}
+	}
+}
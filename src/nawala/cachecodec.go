@@ -0,0 +1,86 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"io"
+)
+
+// compressingCache is an optional interface a [Cache] may implement when it
+// serializes results, e.g. a Redis or file backend. When the configured
+// cache satisfies it and [WithCacheCompression] is given, [New] calls
+// SetCompression so the backend can gzip its stored values. Backends
+// typically implement it by passing the flag on to [EncodeResult].
+type compressingCache interface {
+	SetCompression(enabled bool)
+}
+
+// Leading byte of an encoded result, identifying how the rest is stored.
+const (
+	codecPlain byte = iota
+	codecGzip
+)
+
+// EncodeResult serializes r for a [Cache] backend that stores bytes,
+// gzip-compressing it when compress is true. The output is decoded by
+// [DecodeResult], which detects compression on its own, so entries written
+// before and after toggling [WithCacheCompression] can coexist.
+//
+// [Result.Error] is not encoded: the checker only caches successful
+// results, so it is always nil in cached values.
+func EncodeResult(r Result, compress bool) ([]byte, error) {
+	r.Error = nil
+
+	var buf bytes.Buffer
+	if !compress {
+		buf.WriteByte(codecPlain)
+		if err := gob.NewEncoder(&buf).Encode(r); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	buf.WriteByte(codecGzip)
+	zw := gzip.NewWriter(&buf)
+	if err := gob.NewEncoder(zw).Encode(r); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeResult deserializes a value produced by [EncodeResult].
+func DecodeResult(data []byte) (Result, error) {
+	if len(data) == 0 {
+		return Result{}, ErrMalformedCacheValue
+	}
+
+	var r io.Reader = bytes.NewReader(data[1:])
+	switch data[0] {
+	case codecPlain:
+	case codecGzip:
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return Result{}, fmt.Errorf("%w: %v", ErrMalformedCacheValue, err)
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		return Result{}, fmt.Errorf("%w: unknown codec %d", ErrMalformedCacheValue, data[0])
+	}
+
+	var res Result
+	if err := gob.NewDecoder(r).Decode(&res); err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrMalformedCacheValue, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bytesCache is a Cache backend that stores encoded results, standing in for
+// a Redis or file cache.
+type bytesCache struct {
+	mu       sync.Mutex
+	entries  map[string][]byte
+	compress bool
+}
+
+func (b *bytesCache) SetCompression(enabled bool) { b.compress = enabled }
+
+func (b *bytesCache) Get(key string) (Result, bool) {
+	b.mu.Lock()
+	data, ok := b.entries[key]
+	b.mu.Unlock()
+	if !ok {
+		return Result{}, false
+	}
+	r, err := DecodeResult(data)
+	return r, err == nil
+}
+
+func (b *bytesCache) Set(key string, val Result) {
+	data, err := EncodeResult(val, b.compress)
+	if err != nil {
+		return
+	}
+	b.mu.Lock()
+	b.entries[key] = data
+	b.mu.Unlock()
+}
+
+func (b *bytesCache) Flush() {
+	b.mu.Lock()
+	clear(b.entries)
+	b.mu.Unlock()
+}
+
+func TestEncodeDecodeResult(t *testing.T) {
+	want := Result{
+		Domain:       "example.com",
+		Blocked:      true,
+		Server:       "180.131.144.144",
+		Latency:      12 * time.Millisecond,
+		BlockReason:  BlockReasonBlocked,
+		BlockListURL: "https://trustpositif.komdigi.go.id/assets/db/domains_isp",
+		ResolvedIPs:  []net.IP{net.ParseIP("36.86.63.185")},
+		Attempts:     2,
+	}
+
+	for _, compress := range []bool{false, true} {
+		data, err := EncodeResult(want, compress)
+		require.NoError(t, err)
+
+		got, err := DecodeResult(data)
+		require.NoError(t, err)
+		assert.Equal(t, want.Domain, got.Domain)
+		assert.Equal(t, want.Blocked, got.Blocked)
+		assert.Equal(t, want.Latency, got.Latency)
+		assert.Equal(t, want.BlockReason, got.BlockReason)
+		assert.Equal(t, want.BlockListURL, got.BlockListURL)
+		assert.True(t, want.ResolvedIPs[0].Equal(got.ResolvedIPs[0]))
+		assert.Equal(t, want.Attempts, got.Attempts)
+	}
+}
+
+func TestEncodeResultCompresses(t *testing.T) {
+	r := Result{Domain: "example.com", BlockListURL: string(bytes.Repeat([]byte("a"), 4096))}
+
+	plain, err := EncodeResult(r, false)
+	require.NoError(t, err)
+	gz, err := EncodeResult(r, true)
+	require.NoError(t, err)
+	assert.Less(t, len(gz), len(plain))
+}
+
+func TestEncodeResultDropsError(t *testing.T) {
+	data, err := EncodeResult(Result{Domain: "example.com", Error: errors.New("boom")}, true)
+	require.NoError(t, err)
+
+	got, err := DecodeResult(data)
+	require.NoError(t, err)
+	assert.NoError(t, got.Error)
+}
+
+func TestDecodeResultMalformed(t *testing.T) {
+	for _, data := range [][]byte{nil, {0x7f}, {codecGzip, 0x00}, {codecPlain, 0xff, 0xff}} {
+		_, err := DecodeResult(data)
+		assert.ErrorIs(t, err, ErrMalformedCacheValue, "data %x", data)
+	}
+}
+
+func TestWithCacheCompression(t *testing.T) {
+	backend := &bytesCache{entries: make(map[string][]byte)}
+	New(WithCache(backend), WithCacheCompression(true))
+	assert.True(t, backend.compress)
+
+	New(WithCacheCompression(false), WithCache(backend))
+	assert.False(t, backend.compress)
+
+	// Without the option the backend keeps its own setting.
+	backend.compress = true
+	New(WithCache(backend))
+	assert.True(t, backend.compress)
+
+	// The built-in cache ignores the option.
+	c := New(WithCacheCompression(true))
+	assert.IsType(t, &memoryCache{}, c.cache)
+}
+
+func TestCompressedCacheRoundTrip(t *testing.T) {
+	addr, cleanup := startBlockingDNSServer(t)
+	defer cleanup()
+
+	backend := &bytesCache{entries: make(map[string][]byte)}
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithCache(backend),
+		WithCacheCompression(true),
+	)
+
+	first, err := c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.Len(t, backend.entries, 1)
+	for _, data := range backend.entries {
+		assert.Equal(t, codecGzip, data[0])
+	}
+
+	cached, err := c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, first.Blocked, cached.Blocked)
+	assert.Equal(t, first.Server, cached.Server)
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import "time"
+
+// CallOption adjusts a single call to [Checker.CheckOne] without changing
+// the [Checker], layered over the options it was built with. It avoids
+// constructing a throwaway checker for one-off tweaks.
+type CallOption func(*checkOptions)
+
+// applyCallOptions returns the checkOptions set by opts. The options are
+// applied to a separate value so that, with no options, the caller's copy
+// stays on the stack.
+func applyCallOptions(opts []CallOption) checkOptions {
+	if len(opts) == 0 {
+		return checkOptions{}
+	}
+	o := new(checkOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return *o
+}
+
+// CallTimeout bounds the whole call (every probe, backoff, and server
+// failover) by d, replacing [WithPerDomainTimeout] for this call.
+// [WithTimeout] still bounds each individual query. Values ≤ 0 keep the
+// checker's setting.
+func CallTimeout(d time.Duration) CallOption {
+	return func(o *checkOptions) {
+		o.timeout = d
+	}
+}
+
+// CallServers restricts the call to the configured servers whose
+// [DNSServer.Address] is one of addresses, keeping their configured order
+// for failover. The addresses are normalized like [DNSServer.Address], and
+// those that are not configured are ignored. Calling it with no addresses
+// keeps every server.
+func CallServers(addresses ...string) CallOption {
+	return func(o *checkOptions) {
+		if len(addresses) > 0 {
+			o.addresses = make([]string, len(addresses))
+			for i, addr := range addresses {
+				o.addresses[i] = normalizeAddress(addr)
+			}
+		}
+	}
+}
+
+// CallProbes sets the total number of probes sent to each server for this
+// call, replacing [WithMaxRetries] (which allows n-1 retries). Values ≤ 0
+// keep the checker's setting.
+func CallProbes(n int) CallOption {
+	return func(o *checkOptions) {
+		o.probes = n
+	}
+}
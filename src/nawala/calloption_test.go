@@ -0,0 +1,88 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckOneCallOptions(t *testing.T) {
+	var queries atomic.Int32
+	normal, cleanupNormal := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		queries.Add(1)
+		m := new(dns.Msg)
+		m.SetReply(r)
+		_ = w.WriteMsg(m)
+	}))
+	defer cleanupNormal()
+	blocking, cleanupBlocking := startBlockingDNSServer(t)
+	defer cleanupBlocking()
+
+	c := New(
+		WithServers([]DNSServer{
+			{Address: normal, Keyword: "internetpositif", QueryType: "A"},
+			{Address: blocking, Keyword: "internetpositif", QueryType: "A"},
+		}),
+		WithMaxRetries(2),
+		WithCache(nil),
+	)
+	ctx := context.Background()
+
+	t.Run("defaults", func(t *testing.T) {
+		queries.Store(0)
+		result, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		require.NoError(t, result.Error)
+		assert.Equal(t, normal, result.Server)
+		assert.Equal(t, 3, result.Attempts)
+		assert.EqualValues(t, 3, queries.Load())
+	})
+
+	t.Run("probes", func(t *testing.T) {
+		queries.Store(0)
+		result, err := c.CheckOne(ctx, "example.com", CallProbes(1))
+		require.NoError(t, err)
+		require.NoError(t, result.Error)
+		assert.Equal(t, 1, result.Attempts)
+		assert.EqualValues(t, 1, queries.Load())
+	})
+
+	t.Run("servers", func(t *testing.T) {
+		result, err := c.CheckOne(ctx, "example.com", CallServers(blocking, "192.0.2.1"))
+		require.NoError(t, err)
+		require.NoError(t, result.Error)
+		assert.Equal(t, blocking, result.Server)
+		assert.True(t, result.Blocked)
+
+		_, err = c.CheckOne(ctx, "example.com", CallServers("192.0.2.1"))
+		assert.ErrorIs(t, err, ErrNoDNSServers)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		hang, cleanupHang := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {}))
+		defer cleanupHang()
+
+		slow := New(WithServers([]DNSServer{{Address: hang, Keyword: "internetpositif", QueryType: "A"}}))
+		start := time.Now()
+		result, err := slow.CheckOne(ctx, "example.com", CallTimeout(100*time.Millisecond))
+		require.NoError(t, err)
+		assert.ErrorIs(t, result.Error, ErrAllDNSFailed)
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+
+	t.Run("checker unchanged", func(t *testing.T) {
+		assert.Equal(t, 2, c.maxRetries)
+		assert.Len(t, c.Servers(), 2)
+		assert.Zero(t, c.domainTimeout)
+	})
+}
@@ -10,7 +10,13 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/miekg/dns"
@@ -23,6 +29,7 @@ const (
 	defaultCacheTTL    = 5 * time.Minute
 	defaultConcurrency = 100
 	defaultEDNS0Size   = 1232 // Recommended size to prevent IP fragmentation
+	defaultCacheShards = 16
 
 	// cacheKeyPrefix is prepended to every cache key to namespace all entries
 	// produced by this SDK and avoid collisions with other packages that may
@@ -44,6 +51,17 @@ var defaultServers = []DNSServer{
 	{Address: "180.131.145.145", Keyword: "internetpositif", QueryType: "A"},
 }
 
+// DefaultServers returns a copy of the built-in Nawala DNS servers that a
+// new [Checker] uses unless [WithServers] replaces them. Use it to extend
+// the defaults rather than retyping them:
+//
+//	c := nawala.New(nawala.WithServers(append(nawala.DefaultServers(), myServer)))
+//
+// The returned slice is the caller's to modify.
+func DefaultServers() []DNSServer {
+	return slices.Clone(defaultServers)
+}
+
 // Checker performs DNS-based domain blocking checks against
 // Nawala/Kominfo (now Komdigi) DNS servers.
 type Checker struct {
@@ -55,15 +73,63 @@ type Checker struct {
 	cache         Cache
 	cacheSet      bool // true when WithCache was called explicitly (even with nil)
 	cacheTTL      time.Duration
+	cacheShards   int // shard count of the built-in memory cache
 	edns0Size     uint16
 	dnsProtocol   string // dns.Client.Net value: "udp", "tcp", or "tcp-tls"
 	tlsServerName string // TLS SNI server name override (tcp-tls only)
 	tlsSkipVerify bool   // skip TLS certificate verification (tcp-tls only)
-	dnsClient     *dns.Client
+	dnsClient     atomic.Pointer[dns.Client]
 	digestHash    func(data string) string // optional; when set, cache keys are digested
 	keepAlive     bool                     // true when WithKeepAlive is configured
 	poolSize      int                      // max idle conns per server in the pool
+	idleTimeout   time.Duration            // evict pooled conns idle longer than this; 0 keeps them
 	connPools     map[string]*connPool     // keyed by server address; nil when keepAlive is false
+	serverAdded   map[string]serverAdded   // keyed by normalized address; reported by ServerInfos
+
+	parallelProbes   bool                // true when WithParallelProbes is enabled
+	httpClient       *http.Client        // optional; when set, blocked verdicts are confirmed over HTTP
+	cacheMinTTL      time.Duration       // lower bound for per-entry cache TTL; 0 means unbounded
+	cacheMaxTTL      time.Duration       // upper bound for per-entry cache TTL; 0 means unbounded
+	normalizer       func(string) string // domain normalizer applied before validation
+	recursion        bool                // RD bit on outgoing queries; default true
+	maxRespSize      int                 // max accepted response size in bytes; 0 means unlimited
+	queryHook        QueryHook           // optional; invoked after every DNS query
+	strictMatch      bool                // default to MatchScopeData for servers without a MatchScope
+	domainTimeout    time.Duration       // per-checkSingle budget; 0 means bounded only by the caller's ctx
+	defaultKeyword   string              // keyword for servers configured without one
+	anyFallback      bool                // retry refused ANY queries as A+AAAA; default true
+	remote           *remoteConfig       // optional; set by WithRemoteServerConfig
+	validator        ResponseValidator   // optional; custom verdict run before keyword matching
+	enrichers        []Enricher          // run in order on every fresh verdict before it is cached
+	ednsOptions      []func(*dns.OPT)    // OPT record mutators, applied in order to every query
+	dnsCookie        bool                // attach an RFC 7873 client cookie to every query
+	clientCookie     string              // hex client cookie; set in New when dnsCookie is true
+	domainRules      validatorConfig     // length limits applied by checkSingle
+	probeDelay       time.Duration       // pause between successful sequential probes; 0 disables
+	failOpen         bool                // report all-servers-failed as not blocked (Degraded) instead of an error
+	joinSegments     bool                // also match keywords against concatenated record segments
+	failover         func(error) bool    // reports whether to try the next server after err
+	stats            checkerStats        // lifetime counters reported by Stats
+	network          NetworkPreference   // IP family suffix applied to dnsProtocol for dns.Client.Net
+	maxDomains       int                 // max domains per Check call; 0 means unlimited
+	staleWindow      time.Duration       // serve expired cache entries this long while revalidating
+	revalidating     sync.Map            // cache keys with a background refresh in flight
+	refreshWG        sync.WaitGroup      // background refreshes, awaited by Close
+	cacheCompress    *bool               // set by WithCacheCompression; nil leaves the backend's default
+	defaultMode      string              // MatchMode for servers without one; empty means substring
+	dotPin           *[32]byte           // SHA-256 of the pinned DoT leaf certificate; nil disables pinning
+	clock            Clock               // time source for cache expiry and retry backoff
+	sinkhole         bool                // flag public domains resolving only to bogon addresses
+	lifecycle        lifecycle           // in-flight checks, drained by Shutdown
+	serverHook       ServerChangeHook    // called after runtime server list changes; nil disables
+	allMatches       bool                // report every block indicator in Result.Signals
+	cnameDepth       int                 // max follow-up queries for a dangling CNAME chain; 0 disables
+	requireAnswer    bool                // treat NOERROR responses without answers (NODATA) as failures
+	raceServers      bool                // query every server at once instead of failing over in order
+	maxTotalAttempts int                 // cap on DNS queries per domain check across all servers; 0 = unlimited
+	sysResolver      *net.Resolver       // fallback when every server fails; nil disables
+	sysBlockIPs      []net.IP            // block-page addresses for the system resolver fallback
+	health           serverHealth        // per-server outcomes for ServerReliability and WithCircuitBreaker
 }
 
 // New creates a new [Checker] with the default Nawala DNS server
@@ -79,48 +145,70 @@ type Checker struct {
 //	)
 func New(opts ...Option) *Checker {
 	c := &Checker{
-		servers:     make([]DNSServer, len(defaultServers)),
+		servers:     DefaultServers(),
 		timeout:     defaultTimeout,
 		maxRetries:  defaultRetries,
 		concurrency: defaultConcurrency,
 		edns0Size:   defaultEDNS0Size,
 		cacheTTL:    defaultCacheTTL,
+		cacheShards: defaultCacheShards,
 		dnsProtocol: "udp",
+		normalizer:  normalizeDomain,
+		recursion:   true,
+		anyFallback: true,
+		domainRules: defaultValidator,
+		failover:    defaultFailover,
+		clock:       realClock{},
 	}
-	copy(c.servers, defaultServers)
 
 	for _, opt := range opts {
 		opt(c)
 	}
 
+	// Record the initial server set only now, once WithClock has been applied.
+	for _, srv := range c.servers {
+		c.trackServersLocked([]DNSServer{srv}, isBuiltinServer(srv))
+	}
+
 	// Initialize cache only when WithCache was not explicitly called.
 	// If WithCache(nil) was called, cacheSet is true and cache stays nil (disabled).
 	if !c.cacheSet {
-		c.cache = newMemoryCache(c.cacheTTL)
+		mc := newMemoryCache(c.cacheTTL, c.cacheShards)
+		mc.stale = c.staleWindow
+		mc.clock = c.clock
+		c.cache = mc
+	}
+	if cc, ok := c.cache.(compressingCache); ok && c.cacheCompress != nil {
+		cc.SetCompression(*c.cacheCompress)
 	}
 
 	// Initialize shared DNS client if not set by WithDNSClient option.
-	if c.dnsClient == nil {
+	if c.dnsClient.Load() == nil {
 		client := &dns.Client{
 			Timeout: c.timeout,
 		}
 
+		// The family suffix pins IPv4 or IPv6 (e.g. "udp4", "tcp6-tls").
+		family := c.network.family()
 		switch c.dnsProtocol {
 		case "tcp-tls":
 			// Build TLS config only for tcp-tls and only when explicitly requested,
 			// so UDP/TCP paths have zero overhead.
-			client.Net = "tcp-tls"
+			client.Net = "tcp" + family + "-tls"
 			client.TLSConfig = &tls.Config{
 				ServerName:         c.tlsServerName,
 				InsecureSkipVerify: c.tlsSkipVerify,
 			}
+			if c.dotPin != nil {
+				client.TLSConfig.VerifyConnection = verifyPin(*c.dotPin)
+			}
 		case "tcp":
-			client.Net = "tcp"
+			client.Net = "tcp" + family
 		default:
-			client.Net = "udp"
+			client.Net = "udp" + family
 		}
 
-		c.dnsClient = client
+		c.dnsClient.Store(client)
 	}
 
 	// Initialise connection pool for TCP / TCP-TLS when keep-alive is requested.
@@ -133,11 +221,24 @@ func New(opts ...Option) *Checker {
 		c.connPools = make(map[string]*connPool, len(c.servers))
 		for _, srv := range c.servers {
 			if _, exists := c.connPools[srv.Address]; !exists {
-				c.connPools[srv.Address] = newConnPool(c.dnsClient, srv.Address, size)
+				client := c.clientFor(srv)
+				c.connPools[srv.Address] = newConnPool(client, withDefaultPort(srv.Address, client), size, c.idleTimeout)
 			}
 		}
 	}
 
+	if c.dnsCookie {
+		c.clientCookie = newClientCookie()
+		c.ednsOptions = append(c.ednsOptions, func(opt *dns.OPT) {
+			opt.Option = append(opt.Option, &dns.EDNS0_COOKIE{
+				Code:   dns.EDNS0COOKIE,
+				Cookie: c.clientCookie,
+			})
+		})
+	}
+
+	c.startRemoteRefresh()
+
 	return c
 }
 
@@ -153,14 +254,47 @@ func New(opts ...Option) *Checker {
 // Domains that do not exist on the internet are returned with
 // [ErrNXDOMAIN] in the Result's Error field.
 func (c *Checker) Check(ctx context.Context, domains ...string) ([]Result, error) {
+	return c.check(ctx, nil, domains)
+}
+
+// CheckWithTags is like [Checker.Check], but only queries the configured
+// servers that carry at least one of the given [DNSServer.Tags]. This lets a
+// single [Checker] serve several detection profiles, for example:
+//
+//	results, err := c.CheckWithTags(ctx, []string{"komdigi"}, "example.com")
+//
+// Tags are matched exactly (case-sensitive). If tags is empty, every server
+// is used, exactly as with [Checker.Check]. If no server carries any of the
+// tags, [ErrNoDNSServers] is returned.
+func (c *Checker) CheckWithTags(ctx context.Context, tags []string, domains ...string) ([]Result, error) {
+	return c.check(ctx, tags, domains)
+}
+
+// check implements [Checker.Check] and [Checker.CheckWithTags]. A nil or
+// empty tags slice selects every server.
+func (c *Checker) check(ctx context.Context, tags []string, domains []string) ([]Result, error) {
+	if err := c.lifecycle.begin(); err != nil {
+		return nil, err
+	}
+	defer c.lifecycle.end()
+
 	c.mu.RLock()
-	n := len(c.servers)
+	n := 0
+	for _, srv := range c.servers {
+		if srv.hasAnyTag(tags) {
+			n++
+		}
+	}
 	c.mu.RUnlock()
 
 	if n == 0 {
 		return nil, ErrNoDNSServers
 	}
 
+	if c.maxDomains > 0 && len(domains) > c.maxDomains {
+		return nil, fmt.Errorf("%w: %d domains exceeds limit of %d", ErrTooManyDomains, len(domains), c.maxDomains)
+	}
+
 	results := make([]Result, len(domains))
 	var wg sync.WaitGroup
 
@@ -180,7 +314,7 @@ Loop:
 			for j := i; j < len(domains); j++ {
 				results[j] = Result{
 					Domain: domains[j],
-					Error:  ctx.Err(),
+					Error:  contextError(ctx.Err()),
 				}
 			}
 			// Do not return immediately! We must wait for active goroutines.
@@ -196,7 +330,7 @@ Loop:
 			for j := i; j < len(domains); j++ {
 				results[j] = Result{
 					Domain: domains[j],
-					Error:  ctx.Err(),
+					Error:  contextError(ctx.Err()),
 				}
 			}
 			break Loop
@@ -217,21 +351,120 @@ Loop:
 				}
 			}()
 
-			results[idx] = c.checkSingle(ctx, d)
+			results[idx] = c.checkSingle(ctx, d, checkOptions{tags: tags})
 		}(i, domain)
 	}
 
 	wg.Wait()
 	// Check context one last time to return correct error if we broke early
 	if ctx.Err() != nil {
-		return results, ctx.Err()
+		return results, contextError(ctx.Err())
 	}
 	return results, nil
 }
 
 // CheckOne checks a single domain against the configured Nawala DNS servers.
 // This is a convenience wrapper around [Checker.Check].
-func (c *Checker) CheckOne(ctx context.Context, domain string) (Result, error) {
+//
+// Optional [CallOption] values adjust this call only, layered over the
+// checker's configuration:
+//
+//	r, err := c.CheckOne(ctx, "example.com",
+//	    nawala.CallTimeout(2*time.Second),
+//	    nawala.CallServers("180.131.144.144"),
+//	    nawala.CallProbes(1),
+//	)
+//
+// If no configured server remains after [CallServers], [ErrNoDNSServers]
+// is returned.
+func (c *Checker) CheckOne(ctx context.Context, domain string, opts ...CallOption) (Result, error) {
+	if err := c.lifecycle.begin(); err != nil {
+		return Result{}, err
+	}
+	defer c.lifecycle.end()
+
+	o := applyCallOptions(opts)
+	if !c.hasServers(o) {
+		return Result{}, ErrNoDNSServers
+	}
+	return c.checkSingle(ctx, domain, o), nil
+}
+
+// CheckOneServer checks domain against the configured server whose
+// [DNSServer.Address] is serverAddr, and no other: there is no failover, so
+// a failure of that server is reported in [Result.Error] as
+// [ErrAllDNSFailed]. It is meant for debugging an individual resolver:
+//
+//	r, err := c.CheckOneServer(ctx, "example.com", "180.131.144.144")
+//
+// The server is used with its configured keyword and query type, and the
+// cache is shared with [Checker.CheckOne]. If serverAddr is not configured,
+// [ErrServerNotFound] is returned.
+func (c *Checker) CheckOneServer(ctx context.Context, domain, serverAddr string) (Result, error) {
+	if !c.HasServer(serverAddr) {
+		return Result{}, fmt.Errorf("%w: %s", ErrServerNotFound, serverAddr)
+	}
+	return c.CheckOne(ctx, domain, CallServers(serverAddr))
+}
+
+// IsBlocked reports whether domain is blocked. It is shorthand for
+// [Checker.CheckOne] that folds [Result.Error] into the returned error, for
+// callers that only need the verdict:
+//
+//	blocked, err := c.IsBlocked(ctx, "example.com")
+//
+// The result is false whenever err is non-nil. Use [Checker.CheckOne] when
+// the rest of the [Result], such as the server or the block reason, matters.
+func (c *Checker) IsBlocked(ctx context.Context, domain string, opts ...CallOption) (bool, error) {
+	r, err := c.CheckOne(ctx, domain, opts...)
+	if err != nil {
+		return false, err
+	}
+	if r.Error != nil {
+		return false, r.Error
+	}
+	return r.Blocked, nil
+}
+
+// Check checks domain against a single server, matching keyword in its
+// A-record responses. It is shorthand for building a one-server [Checker]
+// with default options and calling [Checker.CheckOne]:
+//
+//	r, err := nawala.Check(ctx, "180.131.144.144", "internetpositif", "example.com")
+//
+// As with CheckOne, per-domain failures are reported in [Result.Error]; an
+// empty server yields [ErrNoDNSServers].
+//
+// Every call creates and discards its own checker, so nothing is cached or
+// reused between calls. It is meant for scripts and tests; use a long-lived
+// [Checker] on hot paths.
+func Check(ctx context.Context, server, keyword, domain string) (Result, error) {
+	if server == "" {
+		return Result{}, ErrNoDNSServers
+	}
+
+	c := New(
+		WithServers([]DNSServer{{Address: server, Keyword: keyword, QueryType: "A"}}),
+		WithCache(nil),
+	)
+	defer c.Close()
+	return c.CheckOne(ctx, domain)
+}
+
+// CheckIP checks whether ip is blocked at the reverse-DNS level. It builds
+// the reverse name for ip (e.g. "1.0.0.127.in-addr.arpa" for 127.0.0.1, or
+// the nibble form under "ip6.arpa" for IPv6) and runs it through the same
+// detection pipeline as [Checker.CheckOne], querying every configured server
+// for PTR records regardless of its [DNSServer.QueryType].
+//
+// The returned [Result.Domain] is the IP in its textual form rather than the
+// reverse name. An invalid ip yields a Result with [ErrInvalidDomain].
+func (c *Checker) CheckIP(ctx context.Context, ip net.IP) (Result, error) {
+	if err := c.lifecycle.begin(); err != nil {
+		return Result{}, err
+	}
+	defer c.lifecycle.end()
+
 	c.mu.RLock()
 	n := len(c.servers)
 	c.mu.RUnlock()
@@ -239,7 +472,19 @@ func (c *Checker) CheckOne(ctx context.Context, domain string) (Result, error) {
 	if n == 0 {
 		return Result{}, ErrNoDNSServers
 	}
-	return c.checkSingle(ctx, domain), nil
+
+	addr := ip.String()
+	reverse, err := dns.ReverseAddr(addr)
+	if err != nil {
+		return Result{
+			Domain: addr,
+			Error:  fmt.Errorf("%w: invalid IP address %s", ErrInvalidDomain, addr),
+		}, nil
+	}
+
+	result := c.checkSingle(ctx, reverse, checkOptions{queryType: "PTR"})
+	result.Domain = addr
+	return result, nil
 }
 
 // Stream represents a bidirectional stream of domains and their check results.
@@ -257,9 +502,15 @@ type Stream struct {
 // are complete. It does not close the 'Out' channel, giving callers
 // the flexibility to multiplex multiple streams into a single output channel.
 //
-// If the context is canceled, it returns the context error immediately without
-// processing remaining domains in the channel.
+// If the context is canceled, it returns the context error (wrapped in
+// [ErrCanceled] or [ErrDNSTimeout]) immediately without processing remaining
+// domains in the channel.
 func (c *Checker) CheckStream(ctx context.Context, stream Stream) error {
+	if err := c.lifecycle.begin(); err != nil {
+		return err
+	}
+	defer c.lifecycle.end()
+
 	c.mu.RLock()
 	n := len(c.servers)
 	c.mu.RUnlock()
@@ -321,7 +572,7 @@ Loop:
 					}
 				}()
 
-				res = c.checkSingle(ctx, d)
+				res = c.checkSingle(ctx, d, checkOptions{})
 				// Send result, respecting context cancellation
 				select {
 				case <-ctx.Done():
@@ -332,7 +583,7 @@ Loop:
 	}
 
 	wg.Wait()
-	return ctx.Err()
+	return contextError(ctx.Err())
 }
 
 // DNSStatus checks the health of all configured DNS servers.
@@ -364,7 +615,7 @@ Loop:
 			for j := i; j < len(servers); j++ {
 				statuses[j] = ServerStatus{
 					Server: servers[j].Address,
-					Error:  ctx.Err(),
+					Error:  contextError(ctx.Err()),
 				}
 			}
 			break Loop
@@ -378,7 +629,7 @@ Loop:
 			for j := i; j < len(servers); j++ {
 				statuses[j] = ServerStatus{
 					Server: servers[j].Address,
-					Error:  ctx.Err(),
+					Error:  contextError(ctx.Err()),
 				}
 			}
 			break Loop
@@ -400,28 +651,71 @@ Loop:
 			}()
 
 			statuses[idx] = checkDNSHealth(ctx, dnsQuery{
-				client:    c.dnsClient,
-				pool:      c.connPools[server.Address],
-				server:    server.Address,
-				edns0Size: c.edns0Size,
+				client:      c.clientFor(server),
+				pool:        c.connPools[server.Address],
+				server:      server.Address,
+				edns0Size:   c.edns0Size,
+				noRecursion: !c.recursion,
+				ednsOptions: c.ednsOptions,
 			})
 		}(i, srv)
 	}
 
 	wg.Wait()
 	if ctx.Err() != nil {
-		return statuses, ctx.Err()
+		return statuses, contextError(ctx.Err())
 	}
 	return statuses, nil
 }
 
+// Ping checks the health of a single DNS server, which need not be part of
+// the configured set, using the same probe and client settings as
+// [Checker.DNSStatus]. Use it to validate a server before adding it with
+// [Checker.SetServers]:
+//
+//	srv := nawala.DNSServer{Address: "203.0.113.1", Keyword: "blocked", QueryType: "A"}
+//	if st := c.Ping(ctx, srv); st.Online {
+//	    c.SetServers(srv)
+//	}
+//
+// Only server.Address and server.Dialer are used. Failures, including a done
+// context, are reported in [ServerStatus.Error].
+func (c *Checker) Ping(ctx context.Context, server DNSServer) (status ServerStatus) {
+	if err := ctx.Err(); err != nil {
+		return ServerStatus{Server: server.Address, Error: contextError(err)}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			status = ServerStatus{
+				Server: server.Address,
+				Error:  fmt.Errorf("%w: %v", ErrInternalPanic, r),
+			}
+		}
+	}()
+
+	return checkDNSHealth(ctx, dnsQuery{
+		client:      c.clientFor(server),
+		pool:        c.connPools[server.Address],
+		server:      server.Address,
+		edns0Size:   c.edns0Size,
+		noRecursion: !c.recursion,
+		ednsOptions: c.ednsOptions,
+	})
+}
+
 // Close releases resources held by the checker — specifically it drains and
 // closes all idle connections in the keep-alive pool, if one was configured
-// via [WithKeepAlive].
+// via [WithKeepAlive], and stops the periodic refresh started by
+// [WithRemoteServerConfig]. It first waits for background cache refreshes
+// started by [WithStaleWhileRevalidate] to finish.
 //
-// Callers using the default UDP protocol or without [WithKeepAlive] do not
-// need to call Close; it is a no-op in those cases.
+// Callers using the default UDP protocol without [WithKeepAlive] or
+// [WithRemoteServerConfig] do not need to call Close; it is a no-op in
+// those cases.
 func (c *Checker) Close() error {
+	c.stopRemoteRefresh()
+	c.refreshWG.Wait()
 	for _, p := range c.connPools {
 		p.close()
 	}
@@ -435,6 +729,46 @@ func (c *Checker) FlushCache() {
 	}
 }
 
+// FlushContext clears all cached DNS check results like [Checker.FlushCache],
+// but lets ctx bound the flush. If the configured [Cache] implements the
+// optional method
+//
+//	FlushContext(ctx context.Context) error
+//
+// as a network backend such as Redis should, it is called and its error
+// returned. Otherwise the cache's Flush is called, which for the built-in
+// in-memory cache cannot block; ctx is then only checked beforehand.
+//
+// A ctx that is already done yields its error (see [ErrCanceled]) without
+// flushing. With caching disabled FlushContext is a no-op.
+func (c *Checker) FlushContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return contextError(err)
+	}
+	if c.cache == nil {
+		return nil
+	}
+	if cf, ok := c.cache.(contextFlusher); ok {
+		return cf.FlushContext(ctx)
+	}
+	c.cache.Flush()
+	return nil
+}
+
+// CacheLen returns the number of entries in the result cache. It reports
+// false when caching is disabled or when the configured [Cache] does not
+// implement an optional Len() int method.
+//
+// For the built-in in-memory cache the count includes expired entries that
+// have not yet been lazily removed, so it is an upper bound useful for
+// capacity planning.
+func (c *Checker) CacheLen() (int, bool) {
+	if l, ok := c.cache.(cacheLener); ok {
+		return l.Len(), true
+	}
+	return 0, false
+}
+
 // Servers returns a copy of the currently configured DNS servers.
 func (c *Checker) Servers() []DNSServer {
 	c.mu.RLock()
@@ -449,55 +783,101 @@ func (c *Checker) Servers() []DNSServer {
 // number of in-flight results.
 func (c *Checker) Concurrency() int { return c.concurrency }
 
+// Timeout returns the timeout of each DNS query: the [WithTimeout] value, or
+// the Timeout of a client installed with [WithDNSClient] or
+// [Checker.SetDNSClient] (0 there means the dns package's default). It is
+// safe to call concurrently with SetDNSClient.
+func (c *Checker) Timeout() time.Duration { return c.client().Timeout }
+
+// PerDomainTimeout returns the per-domain budget set with
+// [WithPerDomainTimeout], or 0 when there is none.
+func (c *Checker) PerDomainTimeout() time.Duration { return c.domainTimeout }
+
+// MaxRetries returns the number of retries per DNS query, as set with
+// [WithMaxRetries]; each server is probed MaxRetries()+1 times.
+func (c *Checker) MaxRetries() int { return c.maxRetries }
+
+// CacheTTL returns the TTL results are cached for: the [WithCacheTTL] value,
+// or the soft TTL of [WithStaleWhileRevalidate]. It is reported even when
+// caching is disabled with a nil [WithCache], or a custom cache ignores it.
+func (c *Checker) CacheTTL() time.Duration { return c.cacheTTL }
+
+// checkOptions narrows or adjusts a single check. The zero value checks
+// against every configured server as configured.
+type checkOptions struct {
+	tags      []string      // only query servers carrying any of these; nil selects all
+	addresses []string      // only query servers with one of these addresses; nil selects all
+	queryType string        // overrides every server's QueryType when non-empty
+	fresh     bool          // skip cache lookups (results are still stored)
+	timeout   time.Duration // overrides the per-domain timeout when positive
+	probes    int           // overrides the probes per server (maxRetries+1) when positive
+}
+
 // checkSingle performs the DNS check for a single domain.
 // It handles normalization, validation, caching, and failover.
-func (c *Checker) checkSingle(ctx context.Context, domain string) Result {
-	domain = normalizeDomain(domain)
+func (c *Checker) checkSingle(ctx context.Context, domain string, opts checkOptions) Result {
+	// Keep the caller's context to tell a cancellation by the caller apart
+	// from the per-domain budget expiring.
+	parent := ctx
+	timeout := c.domainTimeout
+	if opts.timeout > 0 {
+		timeout = opts.timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	retries := c.maxRetries
+	if opts.probes > 0 {
+		retries = opts.probes - 1
+	}
+
+	domain = c.normalizer(domain)
 
-	if !IsValidDomain(domain) {
+	if err := c.domainRules.validate(domain); err != nil {
 		return Result{
 			Domain: domain,
-			Error:  fmt.Errorf("%w: %s", ErrInvalidDomain, domain),
+			Error:  err,
 		}
 	}
 
-	// Snapshot the server list under a read lock so that a concurrent
-	// SetServers call cannot modify the slice mid-iteration.
-	c.mu.RLock()
-	servers := make([]DNSServer, len(c.servers))
-	copy(servers, c.servers)
-	c.mu.RUnlock()
+	servers := c.health.filter(c.snapshotServers(opts), c.clock.Now())
+	budget := c.newAttemptBudget()
+	if c.raceServers && len(servers) > 1 {
+		return c.checkRace(ctx, parent, domain, servers, retries, budget, opts)
+	}
+
+	var (
+		serverErrs []error // per-server errors from queryWithRetries, in failover order
+		lastServer string  // address of the last server that was tried
+	)
 
 	// Try each server in order (primary with failover).
-	for _, srv := range servers {
+	for i, srv := range servers {
 		qtype := parseQueryType(srv.QueryType)
-		// Cache key deliberately includes the server address; different
-		// servers may return different blocking verdicts for the same domain
-		// (e.g., only one resolver applies a block list). This trades a lower
-		// cache hit rate for correctness — a cached "not blocked" from server A
-		// must not short-circuit a probe against server B.
-		//
-		// All keys are prefixed with cacheKeyPrefix to namespace SDK entries
-		// from other packages that may share the same cache backend.
-		// When WithDigests is configured, the raw components are hashed first
-		// and the digest itself becomes the key body (e.g. nawala_checker:<digest>).
-		rawKey := fmt.Sprintf("%s:%s:%s:%d", domain, srv.Address, srv.Keyword, qtype)
-		var cacheKey string
-		if c.digestHash != nil {
-			cacheKey = cacheKeyPrefix + c.digestHash(rawKey)
-		} else {
-			cacheKey = cacheKeyPrefix + rawKey
-		}
+		cacheKey := c.cacheKey(domain, srv, qtype)
 
 		// Check cache first.
-		if c.cache != nil {
+		if c.cache != nil && !opts.fresh {
 			if cached, ok := c.cache.Get(cacheKey); ok {
+				if cached.Stale {
+					c.revalidate(ctx, cacheKey, domain, opts)
+				}
 				return cached
 			}
 		}
 
+		// With WithMaxTotalAttempts, stop querying once the budget is
+		// spent; later servers may still have a cached result.
+		if budget.spent() {
+			serverErrs = append(serverErrs, fmt.Errorf("%s: %w", srv.Address, ErrAttemptsExhausted))
+			continue
+		}
+
 		// Attempt DNS query with retries.
-		result, err := c.queryWithRetries(ctx, domain, srv, qtype)
+		result, err := c.queryWithRetries(ctx, domain, srv, qtype, retries, budget)
 		if err != nil {
 			// If the domain strictly does not exist (NXDOMAIN) or query rejected by server (QueryRejected), return immediately.
 			// This is a definitive answer from the DNS server, so we shouldn't failover over it.
@@ -508,64 +888,353 @@ func (c *Checker) checkSingle(ctx context.Context, domain string) Result {
 					Error:  err,
 				}
 			}
-			// Other errors (timeouts, network issues), try next server.
+			// Other errors (timeouts, network issues), try next server
+			// unless the failover predicate says otherwise.
+			serverErrs = append(serverErrs, fmt.Errorf("%s: %w", srv.Address, err))
+			lastServer = srv.Address
+
+			// No point failing over once the context is done; every
+			// remaining server would fail the same way.
+			if ctx.Err() != nil || !c.failover(err) {
+				break
+			}
+			if i < len(servers)-1 {
+				c.stats.failovers.Add(1)
+			}
 			continue
 		}
 
-		// Cache the result.
-		if c.cache != nil {
-			c.cache.Set(cacheKey, result)
+		// Optionally confirm the block by fetching the landing page.
+		if result.Blocked && c.httpClient != nil {
+			result.HTTPConfirmed = confirmBlockPage(ctx, c.httpClient, domain, srv.Keyword, result.ResolvedIPs)
+		}
+
+		// Enrich and cache the result.
+		if c.enrich(ctx, &result) {
+			c.storeResult(cacheKey, result)
 		}
 
 		return result
 	}
 
-	// All servers failed.
+	return c.allFailed(ctx, parent, domain, lastServer, serverErrs)
+}
+
+// cacheKey returns the cache key for the result of checking domain against
+// srv with qtype queries.
+func (c *Checker) cacheKey(domain string, srv DNSServer, qtype uint16) string {
+	// Cache key deliberately includes the server address; different
+	// servers may return different blocking verdicts for the same domain
+	// (e.g., only one resolver applies a block list). This trades a lower
+	// cache hit rate for correctness — a cached "not blocked" from server A
+	// must not short-circuit a probe against server B.
+	//
+	// All keys are prefixed with cacheKeyPrefix to namespace SDK entries
+	// from other packages that may share the same cache backend.
+	// When WithDigests is configured, the raw components are hashed first
+	// and the digest itself becomes the key body (e.g. nawala_checker:<digest>).
+	if c.digestHash != nil {
+		return cacheKeyPrefix + c.digestHash(rawCacheKey("", domain, srv.Address, srv.Keyword, qtype))
+	}
+	return rawCacheKey(cacheKeyPrefix, domain, srv.Address, srv.Keyword, qtype)
+}
+
+// rawCacheKey returns prefix followed by the cache key components, as
+// "domain:address:keyword:qtype". It is built in a single allocation since
+// every check computes a key before consulting the cache, and must stay
+// byte-identical to the fmt.Sprintf("%s:%s:%s:%d", ...) form used before so
+// that keys already stored in a shared cache remain valid.
+func rawCacheKey(prefix, domain, address, keyword string, qtype uint16) string {
+	var num [5]byte // a uint16 has at most five digits
+	digits := strconv.AppendUint(num[:0], uint64(qtype), 10)
+
+	var b strings.Builder
+	b.Grow(len(prefix) + len(domain) + len(address) + len(keyword) + len(digits) + 3)
+	b.WriteString(prefix)
+	b.WriteString(domain)
+	b.WriteByte(':')
+	b.WriteString(address)
+	b.WriteByte(':')
+	b.WriteString(keyword)
+	b.WriteByte(':')
+	b.Write(digits)
+	return b.String()
+}
+
+// allFailed builds the result of a check of domain on which every server
+// failed, with serverErrs the per-server causes and lastServer the address
+// of the last server tried. ctx is the check's context and parent the
+// caller's.
+func (c *Checker) allFailed(ctx, parent context.Context, domain, lastServer string, serverErrs []error) Result {
+	// All servers failed. Keep the sentinel matchable via errors.Is while
+	// carrying the domain, server, and every per-server cause (joined, so
+	// each one stays inspectable) for errors.As.
+	//
+	// When the caller cancelled, the context error is the sentinel instead,
+	// so callers can tell "aborted" apart from "every server failed".
+	sentinel := ErrAllDNSFailed
+	if err := parent.Err(); err != nil {
+		sentinel = contextError(err)
+	} else {
+		// Last resort: a best-effort, IP-only verdict from the system
+		// resolver. Like fail-open results, it is degraded and never cached.
+		if c.sysResolver != nil {
+			result, err := c.lookupSystem(ctx, domain)
+			if err == nil {
+				return result
+			}
+			serverErrs = append(serverErrs, fmt.Errorf("%s: %w", SystemResolverServer, err))
+		}
+		if c.failOpen {
+			// Fail open: an outage is reported as "not blocked", flagged as
+			// degraded and never cached.
+			return Result{Domain: domain, Server: lastServer, Degraded: true}
+		}
+	}
+	err := sentinel
+	if len(serverErrs) > 0 {
+		err = fmt.Errorf("%w: %w", sentinel, errors.Join(serverErrs...))
+	}
 	return Result{
 		Domain: domain,
-		Error:  ErrAllDNSFailed,
+		Error: &CheckError{
+			Domain: domain,
+			Server: lastServer,
+			Err:    err,
+		},
+	}
+}
+
+// defaultFailover is the default [WithFailoverPredicate]: fail over on every
+// error except a cancelled or expired context.
+func defaultFailover(err error) bool {
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// snapshotServers returns a copy of the servers matching opts.tags and
+// opts.addresses, with the default keyword and the query type override from
+// opts applied.
+//
+// The list is copied under a read lock so that a concurrent SetServers call
+// cannot modify the slice mid-iteration; the stored configuration is never
+// mutated.
+func (c *Checker) snapshotServers(opts checkOptions) []DNSServer {
+	c.mu.RLock()
+	servers := make([]DNSServer, 0, len(c.servers))
+	for _, srv := range c.servers {
+		if opts.selects(srv) {
+			servers = append(servers, srv)
+		}
+	}
+	c.mu.RUnlock()
+
+	for i := range servers {
+		if servers[i].Keyword == "" && c.defaultKeyword != "" {
+			servers[i].Keyword = c.defaultKeyword
+		}
+		if opts.queryType != "" {
+			servers[i].QueryType = opts.queryType
+		}
+	}
+	return servers
+}
+
+// hasServers reports whether any configured server matches opts.tags and
+// opts.addresses. Unlike len([Checker.snapshotServers]) it does not copy
+// the server list.
+func (c *Checker) hasServers(opts checkOptions) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.ContainsFunc(c.servers, opts.selects)
+}
+
+// selects reports whether srv matches opts.tags and opts.addresses.
+func (o checkOptions) selects(srv DNSServer) bool {
+	if o.addresses != nil && !slices.Contains(o.addresses, normalizeAddress(srv.Address)) {
+		return false
+	}
+	return srv.hasAnyTag(o.tags)
+}
+
+// matchScope returns the keyword match scope for srv: its own
+// [DNSServer.MatchScope] when set, otherwise the checker-wide default.
+func (c *Checker) matchScope(srv DNSServer) string {
+	if srv.MatchScope != "" {
+		return srv.MatchScope
+	}
+	if c.strictMatch {
+		return MatchScopeData
+	}
+	return MatchScopeRecord
+}
+
+// matchMode returns the keyword match mode for srv: its own
+// [DNSServer.MatchMode] when set, otherwise the checker-wide default.
+func (c *Checker) matchMode(srv DNSServer) string {
+	if srv.MatchMode != "" {
+		return srv.MatchMode
+	}
+	if c.defaultMode != "" {
+		return c.defaultMode
 	}
+	return MatchModeSubstring
+}
+
+// storeResult writes result to the cache under key. When the cache accepts a
+// per-entry TTL (see [ttlCache]) and a TTL bound is configured, the entry
+// expires after the response's TTL ([Result.TTL]) clamped into
+// [cacheMinTTL, cacheMaxTTL].
+func (c *Checker) storeResult(key string, result Result) {
+	if c.cache == nil {
+		return
+	}
+
+	if tc, ok := c.cache.(ttlCache); ok && (c.cacheMinTTL > 0 || c.cacheMaxTTL > 0) {
+		ttl := time.Duration(result.TTL) * time.Second
+		tc.SetWithTTL(key, result, c.clampTTL(ttl))
+		return
+	}
+
+	c.cache.Set(key, result)
+}
+
+// enrich runs the [WithEnricher] functions on result, reporting whether
+// they all succeeded and the result may be cached.
+func (c *Checker) enrich(ctx context.Context, result *Result) bool {
+	if len(c.enrichers) == 0 {
+		return true
+	}
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]any)
+	}
+	ok := true
+	for _, fn := range c.enrichers {
+		if err := fn(ctx, result); err != nil {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// revalidate refreshes the stale cache entry key in the background by
+// re-running the check for domain with the cache lookup skipped, at most once
+// at a time per key. The refresh outlives ctx's cancellation but keeps its
+// values, and a panic in it is recovered and dropped.
+func (c *Checker) revalidate(ctx context.Context, key, domain string, opts checkOptions) {
+	if _, busy := c.revalidating.LoadOrStore(key, struct{}{}); busy {
+		return
+	}
+
+	opts.fresh = true
+	c.refreshWG.Go(func() {
+		defer c.revalidating.Delete(key)
+		defer func() { _ = recover() }()
+		c.checkSingle(context.WithoutCancel(ctx), domain, opts)
+	})
+}
+
+// clampTTL bounds ttl by the configured [WithCacheMinTTL] and
+// [WithCacheMaxTTL] values. A zero bound is treated as unset.
+func (c *Checker) clampTTL(ttl time.Duration) time.Duration {
+	if c.cacheMinTTL > 0 && ttl < c.cacheMinTTL {
+		ttl = c.cacheMinTTL
+	}
+	if c.cacheMaxTTL > 0 && ttl > c.cacheMaxTTL {
+		ttl = c.cacheMaxTTL
+	}
+	return ttl
+}
+
+// maxRetryBackoff caps the wait between retries after an error.
+const maxRetryBackoff = 30 * time.Second
+
+// retryBackoff returns the wait before the given retry attempt (1-based)
+// after a failed query: 1s, 2s, 4s, ... capped at [maxRetryBackoff].
+func retryBackoff(attempt int) time.Duration {
+	if attempt < 1 {
+		return 0
+	}
+	// Cap the shift too, so large attempt counts cannot overflow.
+	if attempt > 6 {
+		return maxRetryBackoff
+	}
+	return min(time.Duration(1<<uint(attempt-1))*time.Second, maxRetryBackoff)
 }
 
 // queryWithRetries sends a DNS query with retry logic.
 //
 // Because Nawala/Kominfo (now Komdigi) DNS servers can return inconsistent responses
 // (the blocking CNAME may appear intermittently), this function
-// probes the server multiple times. If ANY probe detects blocking,
+// probes the server retries+1 times. If ANY probe detects blocking,
 // it returns immediately with Blocked=true. Only after all probes
 // return non-blocked does it report the domain as not blocked.
 //
-// Exponential backoff is applied only after query errors, not
-// between successful probes.
-func (c *Checker) queryWithRetries(ctx context.Context, domain string, srv DNSServer, qtype uint16) (Result, error) {
+// Exponential backoff is applied only after query errors; successful
+// probes are only spaced by [WithProbeDelay]. When [WithParallelProbes] is
+// enabled the probes are delegated to [Checker.queryParallel] instead.
+func (c *Checker) queryWithRetries(ctx context.Context, domain string, srv DNSServer, qtype uint16, retries int, budget *attemptBudget) (result Result, err error) {
+	defer func() {
+		// Outcomes of queries the caller aborted, or never sent for lack
+		// of budget, say nothing about the server.
+		if errors.Is(err, ErrAttemptsExhausted) {
+			return
+		}
+		if ctx.Err() == nil || err == nil {
+			ok := err == nil || errors.Is(err, ErrNXDOMAIN) || errors.Is(err, ErrQueryRejected) ||
+				errors.Is(err, ErrNoAnswer)
+			c.health.record(srv.Address, ok, c.clock.Now())
+		}
+	}()
+
+	if c.parallelProbes {
+		return c.queryParallel(ctx, domain, srv, qtype, retries, budget)
+	}
+
 	var (
 		lastErr    error
 		bestResult Result
 		responded  bool
+		attempts   int // probes actually sent
 	)
 
-	for attempt := 0; attempt <= c.maxRetries; attempt++ {
-		if attempt > 0 && lastErr != nil {
-			// Exponential backoff only after errors: 1s, 2s, 4s, ...
-			backoff := min(
-				// Cap backoff to prevent overflow or excessive waits.
-				time.Duration(1<<uint(attempt-1))*time.Second, 30*time.Second)
+	for attempt := 0; attempt <= retries; attempt++ {
+		if !budget.take() {
+			if attempt == 0 {
+				return Result{}, ErrAttemptsExhausted
+			}
+			break
+		}
+		attempts++
+		if attempt > 0 {
+			c.stats.retries.Add(1)
+		}
+
+		var wait time.Duration
+		switch {
+		case attempt > 0 && lastErr != nil:
+			// Exponential backoff only after errors.
+			wait = retryBackoff(attempt)
+		case attempt > 0:
+			// Optional spacing between successful probes, for servers
+			// that rate-limit bursts from a single source.
+			wait = c.probeDelay
+		}
 
+		if wait > 0 {
 			select {
 			case <-ctx.Done():
-				return Result{}, ctx.Err()
-			case <-time.After(backoff):
+				// Degrade gracefully: an earlier probe's answer is still
+				// a valid verdict for this server.
+				if responded {
+					bestResult.Attempts = attempt
+					return bestResult, nil
+				}
+				return Result{}, contextError(ctx.Err())
+			case <-c.clock.After(wait):
 			}
 		}
 
-		resp, err := queryDNS(ctx, dnsQuery{
-			client:    c.dnsClient,
-			pool:      c.connPools[srv.Address],
-			domain:    domain,
-			server:    srv.Address,
-			qtype:     qtype,
-			edns0Size: c.edns0Size,
-		})
+		resp, rtt, err := c.probe(ctx, domain, srv, qtype)
 		if err != nil {
 			// If the domain strictly does not exist, or the server explicitly rejected the query, do not retry.
 			if errors.Is(err, ErrNXDOMAIN) || errors.Is(err, ErrQueryRejected) {
@@ -577,29 +1246,286 @@ func (c *Checker) queryWithRetries(ctx context.Context, domain string, srv DNSSe
 		}
 
 		// If blocking detected on any probe, return immediately.
-		if containsKeyword(resp, srv.Keyword) {
-			return Result{
-				Domain:  domain,
-				Blocked: true,
-				Server:  srv.Address,
-			}, nil
+		result, stop := c.evaluate(ctx, domain, srv, qtype, resp, rtt)
+		if result.Blocked {
+			result.Attempts = attempt + 1
+			result.BlockedOnAttempt = attempt + 1
+			return result, nil
+		}
+
+		// A response validator asked for this verdict to be final.
+		if stop {
+			result.Attempts = attempt + 1
+			return result, nil
+		}
+
+		// With WithRequireAnswer, NODATA is retried like a failed query.
+		if err := c.noAnswer(domain, resp, qtype); err != nil {
+			lastErr = err
+			continue
 		}
 
 		// Track first successful non-blocked result.
 		if !responded {
-			bestResult = Result{
-				Domain:  domain,
-				Blocked: false,
-				Server:  srv.Address,
-			}
+			bestResult = result
 			responded = true
 		}
 	}
 
 	// All probes succeeded without detecting blocking.
 	if responded {
+		bestResult.Attempts = attempts
 		return bestResult, nil
 	}
 
 	return Result{}, lastErr
 }
+
+// queryParallel is the concurrent counterpart of [Checker.queryWithRetries].
+//
+// All maxRetries+1 probes are launched at once. The first probe that detects
+// blocking wins and the remaining in-flight probes are cancelled. Otherwise it
+// waits for every probe to return and reports the first non-blocked result.
+// No backoff is applied, since the probes do not run one after another.
+func (c *Checker) queryParallel(ctx context.Context, domain string, srv DNSServer, qtype uint16, retries int, budget *attemptBudget) (Result, error) {
+	n := 0
+	for n <= retries && budget.take() {
+		n++
+	}
+	if n == 0 {
+		return Result{}, ErrAttemptsExhausted
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type probeResult struct {
+		result Result
+		stop   bool // the response validator made the verdict final
+		err    error
+	}
+
+	ch := make(chan probeResult, n) // Buffered so late probes never block after cancel.
+	c.stats.retries.Add(uint64(n - 1))
+	for range n {
+		go func() {
+			resp, rtt, err := c.probe(ctx, domain, srv, qtype)
+			if err != nil {
+				ch <- probeResult{err: err}
+				return
+			}
+			result, stop := c.evaluate(ctx, domain, srv, qtype, resp, rtt)
+			if !result.Blocked && !stop {
+				if err := c.noAnswer(domain, resp, qtype); err != nil {
+					ch <- probeResult{err: err}
+					return
+				}
+			}
+			ch <- probeResult{result: result, stop: stop}
+		}()
+	}
+
+	var (
+		lastErr    error
+		finalErr   error // definitive answer (NXDOMAIN / rejected) from any probe
+		bestResult Result
+		responded  bool
+	)
+
+	for i := range n {
+		pr := <-ch
+		if pr.err != nil {
+			if errors.Is(pr.err, ErrNXDOMAIN) || errors.Is(pr.err, ErrQueryRejected) {
+				finalErr = pr.err
+			}
+			lastErr = pr.err
+			continue
+		}
+
+		// First block-detecting response wins; cancel the rest.
+		if pr.result.Blocked {
+			pr.result.Attempts = i + 1
+			pr.result.BlockedOnAttempt = i + 1
+			return pr.result, nil
+		}
+
+		// A final clean verdict from the response validator also wins.
+		if pr.stop {
+			pr.result.Attempts = i + 1
+			return pr.result, nil
+		}
+
+		if !responded {
+			bestResult = pr.result
+			responded = true
+		}
+	}
+
+	switch {
+	case responded:
+		bestResult.Attempts = n
+		return bestResult, nil
+	case finalErr != nil:
+		return Result{}, finalErr
+	default:
+		return Result{}, lastErr
+	}
+}
+
+// probe sends a single DNS query for domain to srv and reports it to the
+// query hook, if one is configured.
+//
+// It also returns the wall-clock duration of the query. A SERVFAIL answer is
+// returned as an [ErrServerFailure] error alongside the response, so that it
+// is retried and failed over instead of being evaluated as a clean result.
+func (c *Checker) probe(ctx context.Context, domain string, srv DNSServer, qtype uint16) (*dns.Msg, time.Duration, error) {
+	start := time.Now()
+	resp, err := c.exchange(ctx, domain, srv, qtype)
+	elapsed := time.Since(start)
+
+	switch {
+	case errors.Is(err, ErrIDMismatch):
+		c.stats.idMismatches.Add(1)
+	case errors.Is(err, ErrQuestionMismatch):
+		c.stats.questionMismatches.Add(1)
+	case err == nil && c.clientCookie != "" && !verifyServerCookie(resp, c.clientCookie):
+		c.stats.cookieFailures.Add(1)
+	}
+	if err == nil && resp != nil && resp.Rcode == dns.RcodeServerFailure {
+		err = fmt.Errorf("%w: (rcode: %s)", ErrServerFailure, dns.RcodeToString[resp.Rcode])
+	}
+	if err == nil {
+		if named, mismatch := edeDomainMismatch(resp, domain); mismatch {
+			err = fmt.Errorf("%w: got %s, queried %s", ErrEDEDomainMismatch, named, domain)
+		}
+	}
+
+	if c.queryHook == nil {
+		return resp, elapsed, err
+	}
+
+	rcode := -1
+	if resp != nil {
+		rcode = resp.Rcode
+	}
+	c.queryHook(ctx, QueryEvent{
+		Domain:    domain,
+		Server:    srv.Address,
+		QueryType: qtype,
+		Rcode:     rcode,
+		Duration:  elapsed,
+		Err:       err,
+	})
+	return resp, elapsed, err
+}
+
+// exchange sends a qtype query for domain to srv.
+//
+// When [WithANYFallback] is enabled and an ANY query is refused (RFC 8482),
+// it transparently re-queries as A and AAAA and returns the merged response.
+func (c *Checker) exchange(ctx context.Context, domain string, srv DNSServer, qtype uint16) (*dns.Msg, error) {
+	resp, err := c.send(ctx, domain, srv, qtype)
+	if qtype != dns.TypeANY || !c.anyFallback || !isANYRefusal(resp, err) {
+		return resp, err
+	}
+
+	respA, errA := c.send(ctx, domain, srv, dns.TypeA)
+	if errors.Is(errA, ErrNXDOMAIN) {
+		return nil, errA
+	}
+	respAAAA, errAAAA := c.send(ctx, domain, srv, dns.TypeAAAA)
+
+	switch {
+	case errA == nil && errAAAA == nil:
+		return mergeResponses(respA, respAAAA), nil
+	case errA == nil:
+		return respA, nil
+	case errAAAA == nil:
+		return respAAAA, nil
+	default:
+		return nil, errA
+	}
+}
+
+// client returns the DNS client used for queries, which
+// [Checker.SetDNSClient] may replace at any time. It is read without taking
+// mu so that concurrent queries do not serialize on the lock.
+func (c *Checker) client() *dns.Client {
+	return c.dnsClient.Load()
+}
+
+// clientFor returns the DNS client for queries to srv: the checker's client,
+// or a copy of it dialing through [DNSServer.Dialer] when one is set.
+func (c *Checker) clientFor(srv DNSServer) *dns.Client {
+	client := c.client()
+	if srv.Dialer == nil {
+		return client
+	}
+	cp := *client
+	cp.Dialer = srv.Dialer
+	return &cp
+}
+
+// send builds the [dnsQuery] for domain and srv and sends it.
+func (c *Checker) send(ctx context.Context, domain string, srv DNSServer, qtype uint16) (*dns.Msg, error) {
+	return queryDNS(ctx, dnsQuery{
+		client:          c.clientFor(srv),
+		pool:            c.connPools[srv.Address],
+		domain:          domain,
+		server:          srv.Address,
+		qtype:           qtype,
+		edns0Size:       c.edns0Size,
+		noRecursion:     !c.recursion,
+		maxResponseSize: c.maxRespSize,
+		ednsOptions:     c.ednsOptions,
+		stats:           &c.stats,
+	})
+}
+
+// judge converts a successful DNS response into a [Result], consulting the
+// [ResponseValidator] first when one is configured. It reports true when the
+// validator made the verdict final, in which case the keyword check is
+// skipped and no further probes should be sent.
+func (c *Checker) judge(domain string, srv DNSServer, resp *dns.Msg, rtt time.Duration) (Result, bool) {
+	result := c.newResult(domain, srv, resp, rtt)
+
+	if c.validator != nil {
+		if blocked, stop := c.validator(domain, srv, resp); stop {
+			result.Blocked = blocked
+			if blocked {
+				result.BlockReason = classifyBlock(resp)
+			}
+			return result, true
+		}
+	}
+
+	blocked, details := DetectBlock(resp, DetectOptions{
+		Keywords:          []string{srv.Keyword},
+		MatchScope:        c.matchScope(srv),
+		MatchMode:         c.matchMode(srv),
+		JoinSegments:      c.joinSegments,
+		SinkholeDetection: c.sinkhole,
+		AllMatches:        c.allMatches,
+	})
+	result.Blocked = blocked
+	result.BlockReason = details.Reason
+	result.MatchedSection = details.Section
+	result.Signals = details.Signals
+	return result, false
+}
+
+// newResult builds the [Result] for a successful DNS response from srv,
+// received after rtt, with every field derived from the response itself
+// filled in. The block verdict is left to the caller.
+func (c *Checker) newResult(domain string, srv DNSServer, resp *dns.Msg, rtt time.Duration) Result {
+	return Result{
+		Domain:         domain,
+		Server:         srv.Address,
+		Latency:        rtt,
+		TTL:            minAnswerTTL(resp),
+		Authoritative:  resp.Authoritative,
+		ResolvedIPs:    resolvedIPs(resp),
+		CookieVerified: c.clientCookie != "" && verifyServerCookie(resp, c.clientCookie),
+		BlockListURL:   edeFields(resp)["blocklisturl"],
+	}
+}
@@ -9,13 +9,17 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
+	"fmt"
 	"math/big"
 	"net"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -259,6 +263,26 @@ func TestDNSStatusWithLocalServer(t *testing.T) {
 	assert.GreaterOrEqual(t, statuses[0].LatencyMs, int64(0))
 }
 
+func TestPing(t *testing.T) {
+	addr, cleanup := startNormalDNSServer(t)
+	defer cleanup()
+
+	// The pinged server does not have to be configured.
+	c := New(WithServers([]DNSServer{{Address: "127.0.0.1:19998", Keyword: "test", QueryType: "A"}}))
+
+	status := c.Ping(context.Background(), DNSServer{Address: addr})
+	assert.Equal(t, addr, status.Server)
+	assert.True(t, status.Online)
+	assert.NoError(t, status.Error)
+	assert.False(t, c.HasServer(addr), "Ping must not add the server")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	status = c.Ping(ctx, DNSServer{Address: addr})
+	assert.False(t, status.Online)
+	assert.ErrorIs(t, status.Error, ErrCanceled)
+}
+
 func TestFailover(t *testing.T) {
 	goodAddr, cleanup := startNormalDNSServer(t)
 	defer cleanup()
@@ -299,6 +323,111 @@ func TestAllServersFail(t *testing.T) {
 	assert.ErrorIs(t, result.Error, ErrAllDNSFailed)
 }
 
+func TestAllServersFailCheckError(t *testing.T) {
+	c := New(
+		WithServers([]DNSServer{
+			{Address: "127.0.0.1:19998", Keyword: "test", QueryType: "A"},
+			{Address: "127.0.0.1:19999", Keyword: "test", QueryType: "A"},
+		}),
+		WithTimeout(300*time.Millisecond),
+		WithMaxRetries(0),
+	)
+
+	result, err := c.CheckOne(context.Background(), "Example.COM")
+	require.NoError(t, err)
+	require.ErrorIs(t, result.Error, ErrAllDNSFailed)
+
+	var ce *CheckError
+	require.ErrorAs(t, result.Error, &ce)
+	assert.Equal(t, "example.com", ce.Domain)
+	assert.Equal(t, "127.0.0.1:19999", ce.Server, "expected the last server tried")
+	require.NotNil(t, ce.Err)
+	assert.NotEqual(t, ErrAllDNSFailed, ce.Err, "expected the underlying cause to be preserved")
+
+	// Every per-server error is preserved in the joined detail.
+	assert.Contains(t, ce.Err.Error(), "127.0.0.1:19998: ")
+	assert.Contains(t, ce.Err.Error(), "127.0.0.1:19999: ")
+	assert.Contains(t, ce.Error(), "example.com")
+	assert.Contains(t, ce.Error(), "127.0.0.1:19999")
+}
+
+func TestAllServersFailJoinedTimeouts(t *testing.T) {
+	// Two servers that never answer: both per-server errors must be timeouts.
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {})
+
+	addr1, cleanup1 := startTestDNSServer(t, handler)
+	defer cleanup1()
+	addr2, cleanup2 := startTestDNSServer(t, handler)
+	defer cleanup2()
+
+	c := New(
+		WithServers([]DNSServer{
+			{Address: addr1, Keyword: "test", QueryType: "A"},
+			{Address: addr2, Keyword: "test", QueryType: "A"},
+		}),
+		WithTimeout(200*time.Millisecond),
+		WithMaxRetries(0),
+	)
+
+	result, err := c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.ErrorIs(t, result.Error, ErrAllDNSFailed)
+	assert.ErrorIs(t, result.Error, ErrDNSTimeout, "joined per-server causes must be inspectable")
+	assert.Contains(t, result.Error.Error(), addr1)
+	assert.Contains(t, result.Error.Error(), addr2)
+}
+
+func TestContextError(t *testing.T) {
+	err := contextError(context.Canceled)
+	assert.ErrorIs(t, err, ErrCanceled)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	err = contextError(context.DeadlineExceeded)
+	assert.ErrorIs(t, err, ErrDNSTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.NoError(t, contextError(nil))
+	assert.Equal(t, ErrNXDOMAIN, contextError(ErrNXDOMAIN))
+}
+
+func TestContextErrorsConsistent(t *testing.T) {
+	addr, cleanup := startNormalDNSServer(t)
+	defer cleanup()
+
+	c := New(WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := c.Check(ctx, "example.com")
+	assert.ErrorIs(t, err, ErrCanceled)
+	assert.ErrorIs(t, err, context.Canceled)
+	require.Len(t, results, 1)
+	assert.ErrorIs(t, results[0].Error, ErrCanceled)
+	assert.ErrorIs(t, results[0].Error, context.Canceled)
+
+	_, err = queryDNS(ctx, dnsQuery{client: c.client(), domain: "example.com", server: addr, qtype: dns.TypeA})
+	assert.ErrorIs(t, err, ErrCanceled)
+
+	_, err = c.DNSStatus(ctx)
+	assert.ErrorIs(t, err, ErrCanceled)
+
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	results, err = c.Check(expired, "example.com")
+	assert.ErrorIs(t, err, ErrDNSTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, results[0].Error, ErrDNSTimeout)
+}
+
+func TestCheckErrorUnwrap(t *testing.T) {
+	ce := &CheckError{Domain: "example.com", Server: "1.2.3.4", Err: ErrAllDNSFailed}
+	assert.ErrorIs(t, ce, ErrAllDNSFailed)
+	assert.Equal(t, ErrAllDNSFailed, ce.Unwrap())
+	assert.Equal(t, "nawala: all DNS servers failed to respond (domain: example.com, server: 1.2.3.4)", ce.Error())
+}
+
 func TestQueryWithRetriesSuccess(t *testing.T) {
 	var attempts atomic.Int32
 
@@ -328,7 +457,7 @@ func TestQueryWithRetriesSuccess(t *testing.T) {
 
 	ctx := context.Background()
 	srv := DNSServer{Address: addr, Keyword: "test", QueryType: "A"}
-	result, err := c.queryWithRetries(ctx, "example.com", srv, dns.TypeA)
+	result, err := c.queryWithRetries(ctx, "example.com", srv, dns.TypeA, c.maxRetries, nil)
 	require.NoError(t, err)
 	assert.Equal(t, "example.com", result.Domain)
 	assert.Equal(t, int32(3), attempts.Load(), "expected 3 attempts (probes all retries for consistency)")
@@ -361,18 +490,18 @@ func TestQueryWithRetriesRetry(t *testing.T) {
 	addr, cleanup := startTestDNSServer(t, handler)
 	defer cleanup()
 
+	clock := newFakeClock()
 	c := New(
 		WithTimeout(300*time.Millisecond),
 		WithMaxRetries(2),
+		WithClock(clock),
 	)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	srv := DNSServer{Address: addr, Keyword: "test", QueryType: "A"}
-	result, err := c.queryWithRetries(ctx, "example.com", srv, dns.TypeA)
+	result, err := c.queryWithRetries(context.Background(), "example.com", srv, dns.TypeA, c.maxRetries, nil)
 	require.NoError(t, err, "expected success after retries")
 	assert.Equal(t, "example.com", result.Domain)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, clock.waited)
 }
 
 func TestQueryWithRetriesContextCancel(t *testing.T) {
@@ -392,10 +521,128 @@ func TestQueryWithRetriesContextCancel(t *testing.T) {
 	defer cancel()
 
 	srv := DNSServer{Address: addr, Keyword: "test", QueryType: "A"}
-	_, err := c.queryWithRetries(ctx, "example.com", srv, dns.TypeA)
+	_, err := c.queryWithRetries(ctx, "example.com", srv, dns.TypeA, c.maxRetries, nil)
 	assert.Error(t, err, "expected error for cancelled context")
 }
 
+func TestQueryWithRetriesProbeDelay(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		times []time.Time
+	)
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+
+		m := new(dns.Msg)
+		m.SetReply(r)
+		_ = w.WriteMsg(m)
+	})
+
+	addr, cleanup := startTestDNSServer(t, handler)
+	defer cleanup()
+
+	const delay = 50 * time.Millisecond
+	c := New(WithMaxRetries(2), WithProbeDelay(delay))
+	srv := DNSServer{Address: addr, Keyword: "test", QueryType: "A"}
+
+	result, err := c.queryWithRetries(context.Background(), "example.com", srv, dns.TypeA, c.maxRetries, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Attempts)
+
+	mu.Lock()
+	require.Len(t, times, 3)
+	for i := 1; i < len(times); i++ {
+		assert.GreaterOrEqual(t, times[i].Sub(times[i-1]), delay, "probe %d sent too early", i+1)
+	}
+	mu.Unlock()
+
+	// Cancellation during the delay returns the verdict already obtained.
+	slow := New(WithMaxRetries(2), WithProbeDelay(10*time.Second))
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	result, err = slow.queryWithRetries(ctx, "example.com", srv, dns.TypeA, slow.maxRetries, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Attempts)
+
+	assert.Zero(t, New(WithProbeDelay(-time.Second)).probeDelay)
+}
+
+func TestWithFailOpen(t *testing.T) {
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetRcode(r, dns.RcodeServerFailure)
+		_ = w.WriteMsg(m)
+	})
+	addr, cleanup := startTestDNSServer(t, handler)
+	defer cleanup()
+
+	servers := []DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}
+	ctx := context.Background()
+
+	// Default: fail closed.
+	closed := New(WithServers(servers), WithMaxRetries(0))
+	result, err := closed.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	assert.ErrorIs(t, result.Error, ErrAllDNSFailed)
+	assert.False(t, result.Degraded)
+
+	c := New(WithServers(servers), WithMaxRetries(0), WithFailOpen(true))
+	result, err = c.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	assert.False(t, result.Blocked)
+	assert.True(t, result.Degraded)
+	assert.Equal(t, "example.com", result.Domain)
+	assert.Equal(t, addr, result.Server)
+
+	n, ok := c.CacheLen()
+	require.True(t, ok)
+	assert.Zero(t, n, "degraded results must not be cached")
+
+	// A cancelled caller is still reported as an error.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	result, err = c.CheckOne(cancelled, "example.com")
+	require.NoError(t, err)
+	assert.ErrorIs(t, result.Error, context.Canceled)
+	assert.False(t, result.Degraded)
+}
+
+func TestWithTruncatedKeywordSafety(t *testing.T) {
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		m.SetEdns0(1232, false)
+		opt := m.IsEdns0()
+		opt.Option = append(opt.Option,
+			&dns.EDNS0_EDE{InfoCode: dns.ExtendedErrorCodeBlocked, ExtraText: "trustpos"},
+			&dns.EDNS0_EDE{InfoCode: dns.ExtendedErrorCodeBlocked, ExtraText: "itif.komdigi.go.id"},
+		)
+		_ = w.WriteMsg(m)
+	})
+	addr, cleanup := startTestDNSServer(t, handler)
+	defer cleanup()
+
+	servers := []DNSServer{{Address: addr, Keyword: "trustpositif", QueryType: "A"}}
+	ctx := context.Background()
+
+	result, err := New(WithServers(servers), WithMaxRetries(0)).CheckOne(ctx, "reddit.com")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	assert.False(t, result.Blocked, "split keyword is missed by default")
+
+	c := New(WithServers(servers), WithMaxRetries(0), WithTruncatedKeywordSafety(true))
+	result, err = c.CheckOne(ctx, "reddit.com")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	assert.True(t, result.Blocked)
+	assert.Equal(t, BlockReasonBlocked, result.BlockReason)
+	assert.Equal(t, SectionAdditional, result.MatchedSection)
+}
+
 func TestCheckDomainNormalization(t *testing.T) {
 	addr, cleanup := startNormalDNSServer(t)
 	defer cleanup()
@@ -413,6 +660,143 @@ func TestCheckDomainNormalization(t *testing.T) {
 	assert.Equal(t, "example.com", result.Domain)
 }
 
+func TestCheckEmptyDomain(t *testing.T) {
+	c := New()
+	ctx := context.Background()
+
+	for _, d := range []string{"", "   ", "\t\n"} {
+		result, err := c.CheckOne(ctx, d)
+		require.NoError(t, err)
+		require.ErrorIs(t, result.Error, ErrInvalidDomain, "%q", d)
+		assert.EqualError(t, result.Error, "nawala: invalid domain name: empty domain name", "%q", d)
+	}
+
+	// A custom normalizer that keeps whitespace gets the same message.
+	keep := New(WithNormalizer(func(d string) string { return d }))
+	result, err := keep.CheckOne(ctx, "  ")
+	require.NoError(t, err)
+	assert.EqualError(t, result.Error, "nawala: invalid domain name: empty domain name")
+
+	// Non-empty invalid input still names the domain.
+	result, err = c.CheckOne(ctx, "invalid")
+	require.NoError(t, err)
+	assert.EqualError(t, result.Error, "nawala: invalid domain name: invalid")
+}
+
+func TestWithMaxDomains(t *testing.T) {
+	addr, cleanup := startNormalDNSServer(t)
+	defer cleanup()
+
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxDomains(2),
+	)
+	ctx := context.Background()
+
+	results, err := c.Check(ctx, "example.com", "example.org")
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	results, err = c.Check(ctx, "a.com", "b.com", "c.com")
+	require.ErrorIs(t, err, ErrTooManyDomains)
+	assert.Nil(t, results)
+	assert.EqualError(t, err, "nawala: too many domains: 3 domains exceeds limit of 2")
+
+	_, err = c.CheckWithTags(ctx, nil, "a.com", "b.com", "c.com")
+	assert.ErrorIs(t, err, ErrTooManyDomains)
+
+	assert.Zero(t, New(WithMaxDomains(-1)).maxDomains)
+}
+
+func TestWithNormalizer(t *testing.T) {
+	addr, cleanup := startNormalDNSServer(t)
+	defer cleanup()
+
+	c := New(
+		WithServers([]DNSServer{
+			{Address: addr, Keyword: "internetpositif", QueryType: "A"},
+		}),
+		WithNormalizer(func(d string) string {
+			d = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(d)), ".")
+			return strings.TrimPrefix(d, "www.")
+		}),
+	)
+
+	ctx := context.Background()
+	result, err := c.CheckOne(ctx, " WWW.Example.COM. ")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	assert.Equal(t, "example.com", result.Domain)
+
+	// The custom normalizer runs before validation.
+	bad := New(
+		WithServers([]DNSServer{
+			{Address: addr, Keyword: "internetpositif", QueryType: "A"},
+		}),
+		WithNormalizer(func(d string) string { return "not a domain" }),
+	)
+	result, err = bad.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	assert.ErrorIs(t, result.Error, ErrInvalidDomain)
+
+	// nil keeps the built-in normalizer.
+	def := New(WithNormalizer(nil))
+	require.NotNil(t, def.normalizer)
+	assert.Equal(t, "example.com", def.normalizer("  EXAMPLE.COM "))
+}
+
+func TestWithDomainLimits(t *testing.T) {
+	addr, cleanup := startNormalDNSServer(t)
+	defer cleanup()
+
+	c := New(
+		WithServers([]DNSServer{
+			{Address: addr, Keyword: "internetpositif", QueryType: "A"},
+		}),
+		WithDomainLimits(16, 8),
+	)
+	assert.Equal(t, validatorConfig{maxTotal: 16, maxLabel: 8}, c.domainRules)
+
+	ctx := context.Background()
+	result, err := c.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+
+	// Valid per RFC 1035 but over the configured label limit.
+	result, err = c.CheckOne(ctx, "longerlabel.com")
+	require.NoError(t, err)
+	assert.ErrorIs(t, result.Error, ErrInvalidDomain)
+	assert.True(t, IsValidDomain("longerlabel.com"))
+
+	// Non-positive values keep the defaults.
+	def := New(WithDomainLimits(0, -1))
+	assert.Equal(t, defaultValidator, def.domainRules)
+	partial := New(WithDomainLimits(100, 0))
+	assert.Equal(t, validatorConfig{maxTotal: 100, maxLabel: 63}, partial.domainRules)
+}
+
+func TestWithAllowSingleLabel(t *testing.T) {
+	addr, cleanup := startNormalDNSServer(t)
+	defer cleanup()
+
+	servers := []DNSServer{
+		{Address: addr, Keyword: "internetpositif", QueryType: "A"},
+	}
+	ctx := context.Background()
+
+	strict := New(WithServers(servers))
+	result, err := strict.CheckOne(ctx, "localhost")
+	require.NoError(t, err)
+	assert.ErrorIs(t, result.Error, ErrInvalidDomain)
+
+	c := New(WithServers(servers), WithAllowSingleLabel(true))
+	result, err = c.CheckOne(ctx, "localhost")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	assert.Equal(t, "localhost", result.Domain)
+	assert.False(t, result.Blocked)
+}
+
 func TestCheckWithNilCache(t *testing.T) {
 	addr, cleanup := startNormalDNSServer(t)
 	defer cleanup()
@@ -443,57 +827,163 @@ func TestCheckOneNoServers(t *testing.T) {
 	assert.ErrorIs(t, err, ErrNoDNSServers)
 }
 
-func TestDNSStatusNoServers(t *testing.T) {
-	c := New(WithServers(nil))
-	ctx := context.Background()
+func TestResultTTL(t *testing.T) {
+	// The normal server answers with a TTL of 60 seconds.
+	addr, cleanup := startNormalDNSServer(t)
+	defer cleanup()
 
-	_, err := c.DNSStatus(ctx)
-	assert.ErrorIs(t, err, ErrNoDNSServers)
-}
+	c := New(WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}))
+	result, err := c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	assert.Equal(t, uint32(60), result.TTL)
 
-func TestCheckConcurrencyLimit(t *testing.T) {
-	// This test verifies that we don't spawn a goroutine for every domain
-	// immediately, but strictly bound it by the semaphore size (100).
+	cached, err := c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.LessOrEqual(t, cached.TTL, uint32(60))
+	assert.GreaterOrEqual(t, cached.TTL, uint32(59))
+}
 
-	// Create a slow mock server to ensure goroutines stack up.
-	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
-		time.Sleep(100 * time.Millisecond) // Slow response
+func TestWithClockBackoff(t *testing.T) {
+	addr, cleanup := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
 		m := new(dns.Msg)
-		m.SetReply(r)
+		m.SetRcode(r, dns.RcodeServerFailure)
 		_ = w.WriteMsg(m)
-	})
-	addr, cleanup := startTestDNSServer(t, handler)
+	}))
 	defer cleanup()
 
+	clock := newFakeClock()
 	c := New(
-		WithServers([]DNSServer{
-			{Address: addr, Keyword: "test", QueryType: "A"},
-		}),
-		WithTimeout(1*time.Second),
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(3),
+		WithClock(clock),
 	)
 
-	ctx := context.Background()
-	count := 500 // Significantly more than semaphore size (100)
-	domains := make([]string, count)
-	for i := range domains {
-		domains[i] = "example.com"
-	}
-
-	var wg sync.WaitGroup
-	wg.Add(1)
+	start := time.Now()
+	result, err := c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.ErrorIs(t, result.Error, ErrServerFailure)
 
-	// Measure baseline goroutines
-	startGoroutines := runtime.NumGoroutine()
+	// 1s + 2s + 4s of backoff were requested from the clock, not slept.
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}, clock.waited)
+	assert.Less(t, time.Since(start), time.Second)
+}
 
-	go func() {
-		defer wg.Done()
-		_, _ = c.Check(ctx, domains...)
-	}()
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 0},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 16 * time.Second},
+		{6, 30 * time.Second},
+		{7, 30 * time.Second},
+		{64, 30 * time.Second},
+		{1000, 30 * time.Second},
+	}
 
-	// Give time for the loop to spin up
-	time.Sleep(100 * time.Millisecond)
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, retryBackoff(tt.attempt), "attempt %d", tt.attempt)
+	}
+}
 
-	currentGoroutines := runtime.NumGoroutine()
+func TestWithClockBackoffCap(t *testing.T) {
+	addr, cleanup := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetRcode(r, dns.RcodeServerFailure)
+		_ = w.WriteMsg(m)
+	}))
+	defer cleanup()
+
+	clock := newFakeClock()
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(8),
+		WithClock(clock),
+	)
+
+	result, err := c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.ErrorIs(t, result.Error, ErrServerFailure)
+
+	s := time.Second
+	assert.Equal(t, []time.Duration{s, 2 * s, 4 * s, 8 * s, 16 * s, 30 * s, 30 * s, 30 * s}, clock.waited)
+}
+
+func TestPackageCheck(t *testing.T) {
+	addr, cleanup := startBlockingDNSServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	result, err := Check(ctx, addr, "internetpositif", "example.com")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	assert.True(t, result.Blocked)
+	assert.Equal(t, addr, result.Server)
+
+	result, err = Check(ctx, addr, "internetpositif", "invalid")
+	require.NoError(t, err)
+	assert.ErrorIs(t, result.Error, ErrInvalidDomain)
+
+	_, err = Check(ctx, "", "internetpositif", "example.com")
+	assert.ErrorIs(t, err, ErrNoDNSServers)
+}
+
+func TestDNSStatusNoServers(t *testing.T) {
+	c := New(WithServers(nil))
+	ctx := context.Background()
+
+	_, err := c.DNSStatus(ctx)
+	assert.ErrorIs(t, err, ErrNoDNSServers)
+}
+
+func TestCheckConcurrencyLimit(t *testing.T) {
+	// This test verifies that we don't spawn a goroutine for every domain
+	// immediately, but strictly bound it by the semaphore size (100).
+
+	// Create a slow mock server to ensure goroutines stack up.
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		time.Sleep(100 * time.Millisecond) // Slow response
+		m := new(dns.Msg)
+		m.SetReply(r)
+		_ = w.WriteMsg(m)
+	})
+	addr, cleanup := startTestDNSServer(t, handler)
+	defer cleanup()
+
+	c := New(
+		WithServers([]DNSServer{
+			{Address: addr, Keyword: "test", QueryType: "A"},
+		}),
+		WithTimeout(1*time.Second),
+	)
+
+	ctx := context.Background()
+	count := 500 // Significantly more than semaphore size (100)
+	domains := make([]string, count)
+	for i := range domains {
+		domains[i] = "example.com"
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	// Measure baseline goroutines
+	startGoroutines := runtime.NumGoroutine()
+
+	go func() {
+		defer wg.Done()
+		_, _ = c.Check(ctx, domains...)
+	}()
+
+	// Give time for the loop to spin up
+	time.Sleep(100 * time.Millisecond)
+
+	currentGoroutines := runtime.NumGoroutine()
 	totalSpawned := currentGoroutines - startGoroutines
 
 	// With the fix:
@@ -633,7 +1123,7 @@ func TestWithDNSClient(t *testing.T) {
 		)
 
 		// Verify the custom client is used (not the default).
-		if c.dnsClient != customClient {
+		if c.dnsClient.Load() != customClient {
 			t.Error("expected custom DNS client to be set")
 		}
 
@@ -651,10 +1141,10 @@ func TestWithDNSClient(t *testing.T) {
 		)
 
 		// Should fall back to default client.
-		if c.dnsClient == nil {
+		if c.dnsClient.Load() == nil {
 			t.Error("expected default DNS client when nil is passed")
 		}
-		assert.Equal(t, "udp", c.dnsClient.Net, "expected default UDP transport")
+		assert.Equal(t, "udp", c.dnsClient.Load().Net, "expected default UDP transport")
 	})
 
 	t.Run("custom client overrides timeout", func(t *testing.T) {
@@ -669,7 +1159,7 @@ func TestWithDNSClient(t *testing.T) {
 		)
 
 		// The custom client's timeout should be preserved.
-		assert.Equal(t, 42*time.Second, c.dnsClient.Timeout)
+		assert.Equal(t, 42*time.Second, c.dnsClient.Load().Timeout)
 	})
 
 	t.Run("TCP transport works", func(t *testing.T) {
@@ -728,6 +1218,62 @@ func TestWithDNSClient(t *testing.T) {
 	})
 }
 
+func TestSetDNSClient(t *testing.T) {
+	addr, cleanup := startNormalDNSServer(t)
+	defer cleanup()
+
+	// The test server only listens on UDP, so a TCP client cannot reach it.
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithDNSClient(&dns.Client{Net: "tcp", Timeout: time.Second}),
+		WithMaxRetries(0),
+		WithCache(nil),
+	)
+	ctx := context.Background()
+
+	result, err := c.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	assert.Error(t, result.Error)
+
+	udp := &dns.Client{Net: "udp", Timeout: time.Second}
+	c.SetDNSClient(udp)
+	c.SetDNSClient(nil) // ignored
+	assert.Same(t, udp, c.client())
+
+	result, err = c.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	assert.NoError(t, result.Error)
+}
+
+func TestSetDNSClientConcurrent(t *testing.T) {
+	addr, cleanup := startNormalDNSServer(t)
+	defer cleanup()
+
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(0),
+		WithCache(nil),
+	)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for range 20 {
+				result, err := c.CheckOne(ctx, "example.com")
+				assert.NoError(t, err)
+				assert.NoError(t, result.Error)
+			}
+		})
+	}
+	wg.Go(func() {
+		for i := range 50 {
+			c.SetDNSClient(&dns.Client{Net: "udp", Timeout: time.Duration(i+1) * time.Second})
+		}
+	})
+	wg.Wait()
+}
+
 func TestWithNegativeMaxRetries(t *testing.T) {
 	c := New(WithMaxRetries(-5))
 
@@ -1034,6 +1580,34 @@ func TestNawalaRPZStyleBlockingDoT(t *testing.T) {
 
 	ctx := context.Background()
 
+	t.Run("WithDoT builds an equivalent client", func(t *testing.T) {
+		dot := New(
+			WithServers([]DNSServer{
+				{Address: listener.Addr().String(), Keyword: "internetpositif", QueryType: "A"},
+			}),
+			WithDoT("", true), // Trust our self-signed cert
+		)
+		result, err := dot.CheckOne(ctx, "blocked0.test")
+		require.NoError(t, err)
+		require.NoError(t, result.Error)
+		assert.True(t, result.Blocked)
+	})
+
+	t.Run("WithDoTPin accepts the pinned certificate only", func(t *testing.T) {
+		servers := []DNSServer{{Address: listener.Addr().String(), Keyword: "internetpositif", QueryType: "A"}}
+
+		pinned := New(WithServers(servers), WithDoT("", true), WithDoTPin(sha256.Sum256(derBytes)))
+		result, err := pinned.CheckOne(ctx, "blocked0.test")
+		require.NoError(t, err)
+		require.NoError(t, result.Error)
+		assert.True(t, result.Blocked)
+
+		wrong := New(WithServers(servers), WithDoT("", true), WithDoTPin([32]byte{1}), WithMaxRetries(0))
+		result, err = wrong.CheckOne(ctx, "blocked0.test")
+		require.NoError(t, err)
+		assert.ErrorIs(t, result.Error, ErrCertificatePinMismatch)
+	})
+
 	t.Run("blocked domains detected over DoT", func(t *testing.T) {
 		blockedDomains := []string{"blocked0.test", "blocked1.test", "blocked2.test", "blocked3.test"}
 
@@ -1604,28 +2178,85 @@ func TestWithProtocol(t *testing.T) {
 	}
 }
 
+func TestWithNetworkPreference(t *testing.T) {
+	cases := []struct {
+		proto string
+		pref  NetworkPreference
+		want  string
+	}{
+		{"udp", NetworkAuto, "udp"},
+		{"udp", PreferIPv4, "udp4"},
+		{"udp", PreferIPv6, "udp6"},
+		{"tcp", PreferIPv4, "tcp4"},
+		{"tcp", PreferIPv6, "tcp6"},
+		{"tcp-tls", NetworkAuto, "tcp-tls"},
+		{"tcp-tls", PreferIPv4, "tcp4-tls"},
+		{"tcp-tls", PreferIPv6, "tcp6-tls"},
+		{"udp", NetworkPreference(99), "udp"},
+	}
+	for _, tc := range cases {
+		c := New(WithProtocol(tc.proto), WithNetworkPreference(tc.pref))
+		assert.Equal(t, tc.want, c.dnsClient.Load().Net, "proto=%q pref=%d", tc.proto, tc.pref)
+	}
+
+	// A custom client is left untouched.
+	custom := &dns.Client{Net: "udp"}
+	c := New(WithDNSClient(custom), WithNetworkPreference(PreferIPv6))
+	assert.Equal(t, "udp", c.dnsClient.Load().Net)
+
+	// IPv4 pinning still reaches an IPv4 server.
+	addr, cleanup := startNormalDNSServer(t)
+	defer cleanup()
+
+	v4 := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithNetworkPreference(PreferIPv4),
+	)
+	result, err := v4.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	assert.False(t, result.Blocked)
+}
+
 // TestWithTLSServerName verifies the field is stored and TLS config is populated.
 func TestWithTLSServerName(t *testing.T) {
 	c := New(WithProtocol("tcp-tls"), WithTLSServerName("dns.example.com"))
 	assert.Equal(t, "dns.example.com", c.tlsServerName)
-	require.NotNil(t, c.dnsClient.TLSConfig, "TLSConfig should be set for tcp-tls + server name")
-	assert.Equal(t, "dns.example.com", c.dnsClient.TLSConfig.ServerName)
-	assert.False(t, c.dnsClient.TLSConfig.InsecureSkipVerify)
+	require.NotNil(t, c.dnsClient.Load().TLSConfig, "TLSConfig should be set for tcp-tls + server name")
+	assert.Equal(t, "dns.example.com", c.dnsClient.Load().TLSConfig.ServerName)
+	assert.False(t, c.dnsClient.Load().TLSConfig.InsecureSkipVerify)
 }
 
 // TestWithTLSSkipVerify verifies InsecureSkipVerify is propagated to dns.Client.TLSConfig.
 func TestWithTLSSkipVerify(t *testing.T) {
 	c := New(WithProtocol("tcp-tls"), WithTLSSkipVerify())
 	assert.True(t, c.tlsSkipVerify)
-	require.NotNil(t, c.dnsClient.TLSConfig, "TLSConfig should be set for tcp-tls + skip-verify")
-	assert.True(t, c.dnsClient.TLSConfig.InsecureSkipVerify)
+	require.NotNil(t, c.dnsClient.Load().TLSConfig, "TLSConfig should be set for tcp-tls + skip-verify")
+	assert.True(t, c.dnsClient.Load().TLSConfig.InsecureSkipVerify)
+}
+
+// TestWithDoT verifies WithDoT configures tcp-tls with both TLS settings.
+func TestWithDoT(t *testing.T) {
+	c := New(WithDoT("dns.example.com", false))
+	assert.Equal(t, "tcp-tls", c.dnsClient.Load().Net)
+	require.NotNil(t, c.dnsClient.Load().TLSConfig)
+	assert.Equal(t, "dns.example.com", c.dnsClient.Load().TLSConfig.ServerName)
+	assert.False(t, c.dnsClient.Load().TLSConfig.InsecureSkipVerify)
+
+	// WithDoT replaces an earlier skip-verify setting.
+	c = New(WithTLSSkipVerify(), WithDoT("dns.example.com", false))
+	assert.False(t, c.dnsClient.Load().TLSConfig.InsecureSkipVerify)
+
+	c = New(WithDoT("", true), WithNetworkPreference(PreferIPv4))
+	assert.Equal(t, "tcp4-tls", c.dnsClient.Load().Net)
+	assert.True(t, c.dnsClient.Load().TLSConfig.InsecureSkipVerify)
 }
 
 // TestWithTLSOptions_NoEffectWithoutTCPTLS verifies that TLS options do not
 // create a TLSConfig when the protocol is udp or tcp.
 func TestWithTLSOptions_NoEffectWithoutTCPTLS(t *testing.T) {
 	c := New(WithProtocol("udp"), WithTLSSkipVerify(), WithTLSServerName("example.com"))
-	assert.Nil(t, c.dnsClient.TLSConfig, "TLSConfig must be nil for UDP even with TLS options set")
+	assert.Nil(t, c.dnsClient.Load().TLSConfig, "TLSConfig must be nil for UDP even with TLS options set")
 }
 
 // TestWithProtocol_TCP runs an actual DNS query over TCP to exercise that
@@ -1662,7 +2293,7 @@ func TestWithProtocol_TCP(t *testing.T) {
 		}),
 		WithTimeout(3*time.Second),
 	)
-	assert.Equal(t, "tcp", c.dnsClient.Net)
+	assert.Equal(t, "tcp", c.dnsClient.Load().Net)
 
 	result, err := c.CheckOne(context.Background(), "example.com")
 	require.NoError(t, err)
@@ -1676,9 +2307,9 @@ func TestWithProtocol_TLSSkipVerifyAndServerName(t *testing.T) {
 		WithTLSSkipVerify(),
 		WithTLSServerName("custom.dns"),
 	)
-	require.NotNil(t, c.dnsClient.TLSConfig)
-	assert.True(t, c.dnsClient.TLSConfig.InsecureSkipVerify)
-	assert.Equal(t, "custom.dns", c.dnsClient.TLSConfig.ServerName)
+	require.NotNil(t, c.dnsClient.Load().TLSConfig)
+	assert.True(t, c.dnsClient.Load().TLSConfig.InsecureSkipVerify)
+	assert.Equal(t, "custom.dns", c.dnsClient.Load().TLSConfig.ServerName)
 }
 
 // TestQueryDNS_CustomPort verifies that if a user provides a custom port in the server address,
@@ -1752,3 +2383,839 @@ func TestCheckStreamPanicRecovery(t *testing.T) {
 	assert.ErrorIs(t, results[0].Error, ErrInternalPanic,
 		"expected ErrInternalPanic from recovered goroutine, got: %v", results[0].Error)
 }
+
+func TestQueryWithRetriesParallelProbes(t *testing.T) {
+	t.Run("intermittent block wins", func(t *testing.T) {
+		var attempts atomic.Int32
+
+		// Only the third probe observes the blocking CNAME.
+		handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(r)
+			if attempts.Add(1) == 3 {
+				m.Answer = append(m.Answer, &dns.CNAME{
+					Hdr:    dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 60},
+					Target: "internetpositif.id.",
+				})
+			}
+			_ = w.WriteMsg(m)
+		})
+
+		addr, cleanup := startTestDNSServer(t, handler)
+		defer cleanup()
+
+		c := New(WithMaxRetries(2), WithParallelProbes(true))
+
+		srv := DNSServer{Address: addr, Keyword: "internetpositif", QueryType: "A"}
+		result, err := c.queryWithRetries(context.Background(), "example.com", srv, dns.TypeA, c.maxRetries, nil)
+		require.NoError(t, err)
+		assert.True(t, result.Blocked, "expected the blocking probe to win")
+		assert.Equal(t, addr, result.Server)
+	})
+
+	t.Run("all probes not blocked", func(t *testing.T) {
+		var attempts atomic.Int32
+
+		handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			attempts.Add(1)
+			m := new(dns.Msg)
+			m.SetReply(r)
+			_ = w.WriteMsg(m)
+		})
+
+		addr, cleanup := startTestDNSServer(t, handler)
+		defer cleanup()
+
+		c := New(WithMaxRetries(2), WithParallelProbes(true))
+
+		srv := DNSServer{Address: addr, Keyword: "internetpositif", QueryType: "A"}
+		result, err := c.queryWithRetries(context.Background(), "example.com", srv, dns.TypeA, c.maxRetries, nil)
+		require.NoError(t, err)
+		assert.False(t, result.Blocked)
+		assert.Equal(t, int32(3), attempts.Load(), "expected every probe to be sent")
+	})
+
+	t.Run("definitive error", func(t *testing.T) {
+		handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetRcode(r, dns.RcodeNameError)
+			_ = w.WriteMsg(m)
+		})
+
+		addr, cleanup := startTestDNSServer(t, handler)
+		defer cleanup()
+
+		c := New(WithMaxRetries(2), WithParallelProbes(true))
+
+		srv := DNSServer{Address: addr, Keyword: "internetpositif", QueryType: "A"}
+		_, err := c.queryWithRetries(context.Background(), "example.com", srv, dns.TypeA, c.maxRetries, nil)
+		assert.ErrorIs(t, err, ErrNXDOMAIN)
+	})
+
+	t.Run("all probes fail", func(t *testing.T) {
+		c := New(
+			WithTimeout(200*time.Millisecond),
+			WithMaxRetries(1),
+			WithParallelProbes(true),
+		)
+
+		srv := DNSServer{Address: "127.0.0.1:1", Keyword: "internetpositif", QueryType: "A"}
+		_, err := c.queryWithRetries(context.Background(), "example.com", srv, dns.TypeA, c.maxRetries, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestResultAuthoritative(t *testing.T) {
+	for _, aa := range []bool{true, false} {
+		t.Run(fmt.Sprintf("aa=%v", aa), func(t *testing.T) {
+			handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+				m := new(dns.Msg)
+				m.SetReply(r)
+				m.Authoritative = aa
+				_ = w.WriteMsg(m)
+			})
+
+			addr, cleanup := startTestDNSServer(t, handler)
+			defer cleanup()
+
+			c := New(
+				WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+				WithMaxRetries(0),
+			)
+
+			result, err := c.CheckOne(context.Background(), "example.com")
+			require.NoError(t, err)
+			require.NoError(t, result.Error)
+			assert.Equal(t, aa, result.Authoritative)
+		})
+	}
+}
+
+func TestWithRecursionDesired(t *testing.T) {
+	for _, rd := range []bool{true, false} {
+		t.Run(fmt.Sprintf("rd=%v", rd), func(t *testing.T) {
+			var gotRD atomic.Bool
+			gotRD.Store(!rd)
+
+			handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+				gotRD.Store(r.RecursionDesired)
+				m := new(dns.Msg)
+				m.SetReply(r)
+				_ = w.WriteMsg(m)
+			})
+
+			addr, cleanup := startTestDNSServer(t, handler)
+			defer cleanup()
+
+			c := New(
+				WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+				WithMaxRetries(0),
+				WithRecursionDesired(rd),
+			)
+
+			result, err := c.CheckOne(context.Background(), "example.com")
+			require.NoError(t, err)
+			require.NoError(t, result.Error)
+			assert.Equal(t, rd, gotRD.Load(), "RD bit on check query")
+
+			gotRD.Store(!rd)
+			_, err = c.DNSStatus(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, rd, gotRD.Load(), "RD bit on health probe")
+		})
+	}
+}
+
+func TestWithStrictMatch(t *testing.T) {
+	// The response owner name contains the keyword but the data does not.
+	addr, cleanup := startNormalDNSServer(t)
+	defer cleanup()
+
+	broad := New(WithServers([]DNSServer{{Address: addr, Keyword: "example", QueryType: "A"}}))
+	result, err := broad.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.True(t, result.Blocked, "broad match hits the owner name")
+
+	strict := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "example", QueryType: "A"}}),
+		WithStrictMatch(true),
+	)
+	result, err = strict.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.False(t, result.Blocked, "strict match ignores the owner name")
+
+	// An explicit per-server scope wins over the checker-wide default.
+	override := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "example", QueryType: "A", MatchScope: MatchScopeRecord}}),
+		WithStrictMatch(true),
+	)
+	result, err = override.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.True(t, result.Blocked)
+}
+
+func TestWithMatchMode(t *testing.T) {
+	// The blocking server redirects to "internetpositif.id.".
+	addr, cleanup := startBlockingDNSServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	substring := New(WithServers([]DNSServer{{Address: addr, Keyword: "positif", QueryType: "A"}}))
+	result, err := substring.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	assert.True(t, result.Blocked, "substring match hits part of a label")
+
+	label := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "positif", QueryType: "A"}}),
+		WithMatchMode(MatchModeLabel),
+	)
+	result, err = label.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	assert.False(t, result.Blocked, "label match needs a whole label")
+
+	whole := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMatchMode(MatchModeLabel),
+	)
+	result, err = whole.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	assert.True(t, result.Blocked)
+
+	// An explicit per-server mode wins over the checker-wide default.
+	override := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "positif", QueryType: "A", MatchMode: MatchModeSubstring}}),
+		WithMatchMode(MatchModeLabel),
+	)
+	result, err = override.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	assert.True(t, result.Blocked)
+}
+
+func TestWithMatchModeInvalid(t *testing.T) {
+	for _, mode := range []string{"", "exact", "substring "} {
+		c := New(WithMatchMode(MatchModeLabel), WithMatchMode(mode))
+		assert.Equal(t, MatchModeLabel, c.defaultMode, "mode %q must be ignored", mode)
+		assert.Equal(t, MatchModeLabel, c.matchMode(DNSServer{}))
+	}
+
+	c := New(WithMatchMode("exact"))
+	assert.Empty(t, c.defaultMode)
+	assert.Equal(t, MatchModeSubstring, c.matchMode(DNSServer{}))
+
+	// Case is ignored, as it is for DNSServer.MatchMode.
+	c = New(WithMatchMode("Label"))
+	assert.Equal(t, MatchModeLabel, c.defaultMode)
+}
+
+func TestWithPerDomainTimeout(t *testing.T) {
+	// A server that never answers; without a per-domain budget this check
+	// would take maxRetries+1 query timeouts plus backoff.
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {})
+	addr, cleanup := startTestDNSServer(t, handler)
+	defer cleanup()
+
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "test", QueryType: "A"}}),
+		WithTimeout(5*time.Second),
+		WithMaxRetries(3),
+		WithPerDomainTimeout(200*time.Millisecond),
+	)
+
+	start := time.Now()
+	results, err := c.Check(context.Background(), "slow1.com", "slow2.com")
+	elapsed := time.Since(start)
+
+	require.NoError(t, err, "the batch context itself must not expire")
+	for _, r := range results {
+		assert.ErrorIs(t, r.Error, ErrAllDNSFailed)
+	}
+	assert.Less(t, elapsed, 2*time.Second, "each domain should be bounded by its own budget")
+}
+
+func TestWithDefaultKeyword(t *testing.T) {
+	addr, cleanup := startBlockingDNSServer(t)
+	defer cleanup()
+
+	c := New(
+		WithServers([]DNSServer{{Address: addr, QueryType: "A"}}),
+		WithDefaultKeyword("internetpositif"),
+	)
+
+	result, err := c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	assert.True(t, result.Blocked)
+
+	// Stored configuration is not mutated.
+	assert.Empty(t, c.Servers()[0].Keyword)
+
+	// An explicit per-server keyword wins over the default.
+	explicit := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "never-matches", QueryType: "A"}}),
+		WithDefaultKeyword("internetpositif"),
+	)
+	result, err = explicit.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.False(t, result.Blocked)
+}
+
+func TestWithANYFallback(t *testing.T) {
+	// newHandler replies to A/AAAA with a keyword-bearing CNAME chain,
+	// and to ANY according to refuse.
+	newHandler := func(refuse func(w dns.ResponseWriter, r *dns.Msg)) dns.HandlerFunc {
+		return func(w dns.ResponseWriter, r *dns.Msg) {
+			q := r.Question[0]
+			if q.Qtype == dns.TypeANY {
+				refuse(w, r)
+				return
+			}
+			m := new(dns.Msg)
+			m.SetReply(r)
+			switch q.Qtype {
+			case dns.TypeA:
+				rr, _ := dns.NewRR(q.Name + " 60 IN CNAME internetpositif.id.")
+				m.Answer = append(m.Answer, rr)
+			case dns.TypeAAAA:
+				rr, _ := dns.NewRR(q.Name + " 60 IN AAAA 2001:db8::1")
+				m.Answer = append(m.Answer, rr)
+			}
+			_ = w.WriteMsg(m)
+		}
+	}
+
+	refused := func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetRcode(r, dns.RcodeRefused)
+		_ = w.WriteMsg(m)
+	}
+	hinfo := func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		rr, _ := dns.NewRR(r.Question[0].Name + ` 3600 IN HINFO "RFC8482" ""`)
+		m.Answer = append(m.Answer, rr)
+		_ = w.WriteMsg(m)
+	}
+
+	for name, refuse := range map[string]func(dns.ResponseWriter, *dns.Msg){
+		"refused": refused,
+		"hinfo":   hinfo,
+	} {
+		t.Run(name, func(t *testing.T) {
+			addr, cleanup := startTestDNSServer(t, newHandler(refuse))
+			defer cleanup()
+
+			servers := []DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "ANY"}}
+
+			c := New(WithServers(servers), WithMaxRetries(0))
+			result, err := c.CheckOne(context.Background(), "example.com")
+			require.NoError(t, err)
+			require.NoError(t, result.Error)
+			assert.True(t, result.Blocked, "keyword from the A fallback response")
+			require.Len(t, result.ResolvedIPs, 1, "AAAA fallback response is merged")
+			assert.Equal(t, "2001:db8::1", result.ResolvedIPs[0].String())
+
+			off := New(WithServers(servers), WithMaxRetries(0), WithANYFallback(false))
+			result, err = off.CheckOne(context.Background(), "example.com")
+			require.NoError(t, err)
+			assert.False(t, result.Blocked)
+		})
+	}
+}
+
+func TestIsANYRefusal(t *testing.T) {
+	assert.True(t, isANYRefusal(nil, fmt.Errorf("%w: (rcode: REFUSED)", ErrQueryRejected)))
+	assert.False(t, isANYRefusal(nil, ErrDNSTimeout))
+	assert.False(t, isANYRefusal(nil, nil))
+	assert.False(t, isANYRefusal(new(dns.Msg), nil))
+}
+
+func TestCheckWithTags(t *testing.T) {
+	blockAddr, cleanupBlock := startBlockingDNSServer(t)
+	defer cleanupBlock()
+	normalAddr, cleanupNormal := startNormalDNSServer(t)
+	defer cleanupNormal()
+
+	c := New(
+		WithServers([]DNSServer{
+			{Address: blockAddr, Keyword: "internetpositif", QueryType: "A", Tags: []string{"nawala"}},
+			{Address: normalAddr, Keyword: "internetpositif", QueryType: "A", Tags: []string{"reference", "control"}},
+		}),
+		WithCache(nil),
+	)
+	ctx := context.Background()
+
+	results, err := c.CheckWithTags(ctx, []string{"reference"}, "example.com")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, normalAddr, results[0].Server)
+	assert.False(t, results[0].Blocked)
+
+	results, err = c.CheckWithTags(ctx, []string{"missing", "nawala"}, "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, blockAddr, results[0].Server)
+	assert.True(t, results[0].Blocked)
+
+	_, err = c.CheckWithTags(ctx, []string{"missing"}, "example.com")
+	assert.ErrorIs(t, err, ErrNoDNSServers)
+
+	// Empty tags behave like Check and use every server.
+	results, err = c.CheckWithTags(ctx, nil, "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, blockAddr, results[0].Server)
+}
+
+func TestCheckWithTagsDuplicateServer(t *testing.T) {
+	addr, cleanup := startNormalDNSServer(t)
+	defer cleanup()
+
+	// The same resolver registered once per role keeps both roles.
+	// Spare capacity would let a careless append write into the caller's array.
+	nawalaTags := append(make([]string, 0, 4), "nawala")
+	c := New(
+		WithServers([]DNSServer{
+			{Address: addr, Keyword: "internetpositif", QueryType: "A", Tags: nawalaTags},
+			{Address: addr, Keyword: "internetpositif", QueryType: "A", Tags: []string{"reference", "nawala"}},
+		}),
+		WithCache(nil),
+	)
+	servers := c.Servers()
+	require.Len(t, servers, 1)
+	assert.Equal(t, []string{"nawala", "reference"}, servers[0].Tags)
+	assert.Equal(t, []string{"nawala", ""}, nawalaTags[:2], "the caller's array is not modified")
+
+	ctx := context.Background()
+	for _, tag := range []string{"nawala", "reference"} {
+		results, err := c.CheckWithTags(ctx, []string{tag}, "example.com")
+		require.NoError(t, err, tag)
+		assert.Equal(t, addr, results[0].Server)
+	}
+}
+
+func TestServFailTriggersFailover(t *testing.T) {
+	var servfails atomic.Int32
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		servfails.Add(1)
+		m := new(dns.Msg)
+		m.SetRcode(r, dns.RcodeServerFailure)
+		_ = w.WriteMsg(m)
+	})
+	failAddr, cleanupFail := startTestDNSServer(t, handler)
+	defer cleanupFail()
+
+	// A lone SERVFAIL server must not yield a clean "not blocked" verdict.
+	c := New(
+		WithServers([]DNSServer{{Address: failAddr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(1),
+		WithTimeout(time.Second),
+	)
+	result, err := c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.Error(t, result.Error, "SERVFAIL must not be reported as not blocked")
+	assert.ErrorIs(t, result.Error, ErrAllDNSFailed)
+	assert.ErrorIs(t, result.Error, ErrServerFailure)
+	assert.EqualValues(t, 2, servfails.Load(), "SERVFAIL is retried")
+
+	// With a healthy secondary, the check fails over to it.
+	blockAddr, cleanupBlock := startBlockingDNSServer(t)
+	defer cleanupBlock()
+
+	c = New(
+		WithServers([]DNSServer{
+			{Address: failAddr, Keyword: "internetpositif", QueryType: "A"},
+			{Address: blockAddr, Keyword: "internetpositif", QueryType: "A"},
+		}),
+		WithMaxRetries(0),
+	)
+	result, err = c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	assert.True(t, result.Blocked)
+	assert.Equal(t, blockAddr, result.Server)
+}
+
+func TestWithFailoverPredicate(t *testing.T) {
+	var secondary atomic.Int32
+	countAddr, cleanupCount := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		secondary.Add(1)
+		m := new(dns.Msg)
+		m.SetReply(r)
+		_ = w.WriteMsg(m)
+	}))
+	defer cleanupCount()
+
+	t.Run("cancellation short-circuits failover", func(t *testing.T) {
+		secondary.Store(0)
+		hangAddr, cleanupHang := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {}))
+		defer cleanupHang()
+
+		c := New(
+			WithServers([]DNSServer{
+				{Address: hangAddr, Keyword: "internetpositif", QueryType: "A"},
+				{Address: countAddr, Keyword: "internetpositif", QueryType: "A"},
+			}),
+			WithTimeout(5*time.Second),
+		)
+
+		// The deadline cancels the context while the primary is hanging.
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		result, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		assert.ErrorIs(t, result.Error, context.DeadlineExceeded)
+		assert.Zero(t, secondary.Load(), "no query after cancellation")
+
+		assert.False(t, defaultFailover(context.Canceled))
+		assert.False(t, defaultFailover(fmt.Errorf("wrapped: %w", context.DeadlineExceeded)))
+		assert.True(t, defaultFailover(ErrDNSTimeout))
+	})
+
+	t.Run("custom predicate stops failover", func(t *testing.T) {
+		secondary.Store(0)
+		failAddr, cleanupFail := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetRcode(r, dns.RcodeServerFailure)
+			_ = w.WriteMsg(m)
+		}))
+		defer cleanupFail()
+
+		var seen []error
+		c := New(
+			WithServers([]DNSServer{
+				{Address: failAddr, Keyword: "internetpositif", QueryType: "A"},
+				{Address: countAddr, Keyword: "internetpositif", QueryType: "A"},
+			}),
+			WithMaxRetries(0),
+			WithFailoverPredicate(func(err error) bool {
+				seen = append(seen, err)
+				return !errors.Is(err, ErrServerFailure)
+			}),
+		)
+
+		result, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.ErrorIs(t, result.Error, ErrAllDNSFailed)
+		assert.ErrorIs(t, result.Error, ErrServerFailure)
+		assert.Zero(t, secondary.Load(), "secondary must not be tried")
+		require.Len(t, seen, 1)
+		assert.ErrorIs(t, seen[0], ErrServerFailure)
+	})
+
+	t.Run("nil restores default", func(t *testing.T) {
+		c := New(WithFailoverPredicate(nil))
+		require.NotNil(t, c.failover)
+		assert.False(t, c.failover(context.Canceled))
+	})
+}
+
+func TestResultAttempts(t *testing.T) {
+	// The block is only served from the second query onwards.
+	var queries atomic.Int32
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		if queries.Add(1) >= 2 {
+			rr, _ := dns.NewRR(r.Question[0].Name + " 60 IN CNAME internetpositif.id.")
+			m.Answer = append(m.Answer, rr)
+		}
+		_ = w.WriteMsg(m)
+	})
+	flakyAddr, cleanupFlaky := startTestDNSServer(t, handler)
+	defer cleanupFlaky()
+
+	c := New(
+		WithServers([]DNSServer{{Address: flakyAddr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(2),
+	)
+	result, err := c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	assert.True(t, result.Blocked)
+	assert.Equal(t, 2, result.Attempts)
+	assert.Equal(t, 2, result.BlockedOnAttempt)
+
+	normalAddr, cleanupNormal := startNormalDNSServer(t)
+	defer cleanupNormal()
+
+	c = New(
+		WithServers([]DNSServer{{Address: normalAddr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(2),
+	)
+	result, err = c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	assert.False(t, result.Blocked)
+	assert.Equal(t, 3, result.Attempts, "a clean verdict uses every probe")
+	assert.Zero(t, result.BlockedOnAttempt)
+
+	c = New(
+		WithServers([]DNSServer{{Address: normalAddr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(2),
+		WithParallelProbes(true),
+	)
+	result, err = c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Attempts)
+	assert.Zero(t, result.BlockedOnAttempt)
+}
+
+func TestCheckIP(t *testing.T) {
+	var gotQtype atomic.Uint32
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		q := r.Question[0]
+		gotQtype.Store(uint32(q.Qtype))
+		m := new(dns.Msg)
+		m.SetReply(r)
+		if q.Name == "1.2.0.192.in-addr.arpa." || strings.HasSuffix(q.Name, ".8.b.d.0.1.0.0.2.ip6.arpa.") {
+			rr, _ := dns.NewRR(q.Name + " 60 IN PTR blocked.internetpositif.id.")
+			m.Answer = append(m.Answer, rr)
+		}
+		_ = w.WriteMsg(m)
+	})
+	addr, cleanup := startTestDNSServer(t, handler)
+	defer cleanup()
+
+	c := New(WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}))
+
+	for _, ip := range []string{"192.0.2.1", "2001:db8::1"} {
+		result, err := c.CheckIP(context.Background(), net.ParseIP(ip))
+		require.NoError(t, err)
+		require.NoError(t, result.Error, ip)
+		assert.Equal(t, ip, result.Domain)
+		assert.True(t, result.Blocked, ip)
+		assert.EqualValues(t, dns.TypePTR, gotQtype.Load(), "PTR overrides the server query type")
+	}
+
+	result, err := c.CheckIP(context.Background(), net.ParseIP("192.0.2.2"))
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	assert.False(t, result.Blocked)
+
+	result, err = c.CheckIP(context.Background(), nil)
+	require.NoError(t, err)
+	assert.ErrorIs(t, result.Error, ErrInvalidDomain)
+
+	_, err = New(WithServers(nil)).CheckIP(context.Background(), net.ParseIP("192.0.2.1"))
+	assert.ErrorIs(t, err, ErrNoDNSServers)
+}
+
+func TestCheckSingleBestEffortOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The first query gets a clean answer; the caller then cancels while
+	// the second probe is in flight.
+	var queries atomic.Int32
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		if queries.Add(1) > 1 {
+			cancel()
+			return
+		}
+		m := new(dns.Msg)
+		m.SetReply(r)
+		_ = w.WriteMsg(m)
+	})
+	addr, cleanup := startTestDNSServer(t, handler)
+	defer cleanup()
+
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(2),
+		WithTimeout(500*time.Millisecond),
+	)
+
+	result, err := c.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	require.NoError(t, result.Error, "the earlier clean answer must survive the cancellation")
+	assert.False(t, result.Blocked)
+	assert.Equal(t, addr, result.Server)
+}
+
+func TestCheckSingleCanceledIsNotAllFailed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The primary never answers and the caller cancels meanwhile.
+	var secondaryQueries atomic.Int32
+	primary, cleanupPrimary := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		cancel()
+	}))
+	defer cleanupPrimary()
+	secondary, cleanupSecondary := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		secondaryQueries.Add(1)
+	}))
+	defer cleanupSecondary()
+
+	c := New(
+		WithServers([]DNSServer{
+			{Address: primary, Keyword: "internetpositif", QueryType: "A"},
+			{Address: secondary, Keyword: "internetpositif", QueryType: "A"},
+		}),
+		WithMaxRetries(0),
+		WithTimeout(500*time.Millisecond),
+	)
+
+	result, err := c.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	require.Error(t, result.Error)
+	assert.ErrorIs(t, result.Error, context.Canceled)
+	assert.NotErrorIs(t, result.Error, ErrAllDNSFailed)
+
+	var ce *CheckError
+	require.ErrorAs(t, result.Error, &ce)
+	assert.Equal(t, primary, ce.Server)
+	assert.Zero(t, secondaryQueries.Load(), "no failover after cancellation")
+}
+
+func TestWithEDNS0Options(t *testing.T) {
+	type seenOPT struct {
+		do      bool
+		size    uint16
+		options int
+	}
+	var (
+		mu   sync.Mutex
+		seen []seenOPT
+	)
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		if opt := r.IsEdns0(); opt != nil {
+			mu.Lock()
+			seen = append(seen, seenOPT{do: opt.Do(), size: opt.UDPSize(), options: len(opt.Option)})
+			mu.Unlock()
+		}
+		m := new(dns.Msg)
+		m.SetReply(r)
+		_ = w.WriteMsg(m)
+	})
+	addr, cleanup := startTestDNSServer(t, handler)
+	defer cleanup()
+
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(0),
+		WithEDNS0Size(4096),
+		WithEDNS0Options(func(opt *dns.OPT) { opt.SetDo() }),
+		WithEDNS0Options(nil),
+		WithEDNS0Options(func(opt *dns.OPT) {
+			opt.Option = append(opt.Option, &dns.EDNS0_PADDING{Padding: make([]byte, 8)})
+		}),
+	)
+
+	_, err := c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	_, err = c.DNSStatus(context.Background())
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, seen, 2, "check query and health probe")
+	for _, s := range seen {
+		assert.Equal(t, seenOPT{do: true, size: 4096, options: 1}, s)
+	}
+
+	// Pooled OPT records must not leak options into queries without the hook.
+	seen = nil
+	mu.Unlock()
+	plain := New(WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}), WithMaxRetries(0))
+	_, err = plain.CheckOne(context.Background(), "another.com")
+	require.NoError(t, err)
+	mu.Lock()
+	require.Len(t, seen, 1)
+	assert.Equal(t, seenOPT{size: defaultEDNS0Size}, seen[0])
+}
+
+func TestCheckOneServer(t *testing.T) {
+	blocking, cleanupBlocking := startBlockingDNSServer(t)
+	defer cleanupBlocking()
+	normal, cleanupNormal := startNormalDNSServer(t)
+	defer cleanupNormal()
+	dead := "127.0.0.1:1" // nothing listens here
+
+	c := New(
+		WithServers([]DNSServer{
+			{Address: blocking, Keyword: "internetpositif", QueryType: "A"},
+			{Address: normal, Keyword: "internetpositif", QueryType: "A"},
+			{Address: dead, Keyword: "internetpositif", QueryType: "A"},
+		}),
+		WithTimeout(200*time.Millisecond),
+		WithMaxRetries(0),
+	)
+	ctx := context.Background()
+
+	r, err := c.CheckOneServer(ctx, "example.com", normal)
+	require.NoError(t, err)
+	require.NoError(t, r.Error)
+	assert.False(t, r.Blocked, "the blocking primary must not be consulted")
+	assert.Equal(t, normal, r.Server)
+
+	r, err = c.CheckOneServer(ctx, "example.com", blocking)
+	require.NoError(t, err)
+	require.NoError(t, r.Error)
+	assert.True(t, r.Blocked)
+	assert.Equal(t, blocking, r.Server)
+
+	// No failover: the dead server's failure is the verdict.
+	r, err = c.CheckOneServer(ctx, "example.com", dead)
+	require.NoError(t, err)
+	assert.ErrorIs(t, r.Error, ErrAllDNSFailed)
+
+	_, err = c.CheckOneServer(ctx, "example.com", "192.0.2.1")
+	assert.ErrorIs(t, err, ErrServerNotFound)
+}
+
+func TestIsBlocked(t *testing.T) {
+	blocking, cleanupBlocking := startBlockingDNSServer(t)
+	defer cleanupBlocking()
+	normal, cleanupNormal := startNormalDNSServer(t)
+	defer cleanupNormal()
+	dead := "127.0.0.1:1" // nothing listens here
+
+	ctx := context.Background()
+	newChecker := func(addr string) *Checker {
+		return New(
+			WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithTimeout(200*time.Millisecond),
+			WithMaxRetries(0),
+		)
+	}
+
+	blocked, err := newChecker(blocking).IsBlocked(ctx, "example.com")
+	require.NoError(t, err)
+	assert.True(t, blocked)
+
+	blocked, err = newChecker(normal).IsBlocked(ctx, "example.com")
+	require.NoError(t, err)
+	assert.False(t, blocked)
+
+	// Per-domain failures surface as the returned error.
+	blocked, err = newChecker(dead).IsBlocked(ctx, "example.com")
+	assert.ErrorIs(t, err, ErrAllDNSFailed)
+	assert.False(t, blocked)
+
+	blocked, err = newChecker(normal).IsBlocked(ctx, "not a domain")
+	assert.ErrorIs(t, err, ErrInvalidDomain)
+	assert.False(t, blocked)
+
+	// Call options are passed through to CheckOne.
+	_, err = newChecker(normal).IsBlocked(ctx, "example.com", CallServers("192.0.2.1"))
+	assert.ErrorIs(t, err, ErrNoDNSServers)
+}
+
+// BenchmarkCheckOneCached measures the cache-hit path of CheckOne, which
+// sends no query and should stay cheap.
+func BenchmarkCheckOneCached(b *testing.B) {
+	c := New(
+		WithServers([]DNSServer{{Address: "180.131.144.144", Keyword: "internetpositif", QueryType: "A"}}),
+	)
+	srv := c.Servers()[0]
+	c.cache.Set(c.cacheKey("example.com", srv, dns.TypeA), Result{Domain: "example.com", Server: srv.Address, Blocked: true})
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		r, err := c.CheckOne(ctx, "example.com")
+		if err != nil || !r.Blocked {
+			b.Fatalf("unexpected result %+v, %v", r, err)
+		}
+	}
+}
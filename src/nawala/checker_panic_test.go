@@ -68,7 +68,7 @@ func TestDNSStatusPanicRecovery(t *testing.T) {
 		}),
 	)
 	// Sabotage the client to force a nil pointer panic inside the goroutine.
-	c.dnsClient = nil
+	c.dnsClient.Store(nil)
 
 	ctx := context.Background()
 	assert.NotPanics(t, func() {
@@ -83,6 +83,17 @@ func TestDNSStatusPanicRecovery(t *testing.T) {
 	})
 }
 
+func TestPingPanicRecovery(t *testing.T) {
+	c := New()
+	c.dnsClient.Store(nil)
+
+	assert.NotPanics(t, func() {
+		status := c.Ping(context.Background(), DNSServer{Address: "127.0.0.1:19998"})
+		assert.False(t, status.Online)
+		assert.ErrorIs(t, status.Error, ErrInternalPanic)
+	})
+}
+
 func TestCheckContextCancellationEarly(t *testing.T) {
 	// Test that if context is cancelled, we stop processing.
 	c := New()
@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -69,6 +70,21 @@ func TestNewDefaults(t *testing.T) {
 	}
 }
 
+func TestDefaultServers(t *testing.T) {
+	defaults := nawala.DefaultServers()
+	assert.Equal(t, nawala.New().Servers(), defaults)
+
+	// The copy is the caller's: mutating it leaves the defaults intact.
+	defaults[0].Address = "203.0.113.1"
+	assert.Equal(t, "180.131.144.144", nawala.DefaultServers()[0].Address)
+
+	mine := nawala.DNSServer{Address: "203.0.113.2", Keyword: "blocked", QueryType: "A"}
+	c := nawala.New(nawala.WithServers(append(nawala.DefaultServers(), mine)))
+	servers := c.Servers()
+	require.Len(t, servers, 3)
+	assert.Equal(t, mine, servers[2])
+}
+
 func TestConcurrency(t *testing.T) {
 	t.Run("default", func(t *testing.T) {
 		c := nawala.New()
@@ -81,6 +97,40 @@ func TestConcurrency(t *testing.T) {
 	})
 }
 
+func TestConfigGetters(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		c := nawala.New()
+		assert.Equal(t, 5*time.Second, c.Timeout())
+		assert.Zero(t, c.PerDomainTimeout())
+		assert.Equal(t, 2, c.MaxRetries())
+		assert.Equal(t, 5*time.Minute, c.CacheTTL())
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		c := nawala.New(
+			nawala.WithTimeout(3*time.Second),
+			nawala.WithPerDomainTimeout(10*time.Second),
+			nawala.WithMaxRetries(4),
+			nawala.WithCacheTTL(time.Minute),
+		)
+		assert.Equal(t, 3*time.Second, c.Timeout())
+		assert.Equal(t, 10*time.Second, c.PerDomainTimeout())
+		assert.Equal(t, 4, c.MaxRetries())
+		assert.Equal(t, time.Minute, c.CacheTTL())
+	})
+
+	t.Run("stale while revalidate", func(t *testing.T) {
+		c := nawala.New(nawala.WithStaleWhileRevalidate(time.Minute, time.Hour))
+		assert.Equal(t, time.Minute, c.CacheTTL())
+	})
+
+	t.Run("custom client", func(t *testing.T) {
+		c := nawala.New(nawala.WithTimeout(3 * time.Second))
+		c.SetDNSClient(&dns.Client{Timeout: 7 * time.Second})
+		assert.Equal(t, 7*time.Second, c.Timeout())
+	})
+}
+
 func TestWithOptions(t *testing.T) {
 	customServers := []nawala.DNSServer{
 		{Address: "1.1.1.1", Keyword: "test", QueryType: "A"},
@@ -288,6 +338,113 @@ func TestDeleteServersConcurrency(t *testing.T) {
 	assert.GreaterOrEqual(t, len(c.Servers()), 52)
 }
 
+func TestDeleteServersFuncRuntime(t *testing.T) {
+	c := nawala.New()
+
+	c.SetServers(
+		nawala.DNSServer{Address: "1.1.1.1", Keyword: "stale", QueryType: "A"},
+		nawala.DNSServer{Address: "8.8.8.8", Keyword: "stale", QueryType: "A"},
+		nawala.DNSServer{Address: "9.9.9.9", Keyword: "fresh", QueryType: "A"},
+	)
+	require.Len(t, c.Servers(), 5)
+
+	// Delete every server with the stale keyword.
+	c.DeleteServersFunc(func(s nawala.DNSServer) bool {
+		return s.Keyword == "stale"
+	})
+	servers := c.Servers()
+	require.Len(t, servers, 3)
+	for _, s := range servers {
+		assert.NotEqual(t, "stale", s.Keyword)
+	}
+
+	// Predicate matching nothing (no-op).
+	c.DeleteServersFunc(func(nawala.DNSServer) bool { return false })
+	require.Len(t, c.Servers(), 3)
+
+	// Nil predicate (no-op).
+	c.DeleteServersFunc(nil)
+	require.Len(t, c.Servers(), 3)
+
+	// Predicate matching everything empties the list.
+	c.DeleteServersFunc(func(nawala.DNSServer) bool { return true })
+	assert.Empty(t, c.Servers())
+}
+
+func TestReplaceServersRuntime(t *testing.T) {
+	c := nawala.New()
+	require.Len(t, c.Servers(), 2)
+
+	newSet := []nawala.DNSServer{
+		{Address: "1.1.1.1", Keyword: "cf", QueryType: "A"},
+		{Address: "8.8.8.8", Keyword: "google", QueryType: "A"},
+		{Address: "8.8.8.8", Keyword: "google", QueryType: "A"}, // duplicate
+	}
+	c.ReplaceServers(newSet)
+
+	servers := c.Servers()
+	require.Len(t, servers, 2)
+	assert.Equal(t, "1.1.1.1", servers[0].Address)
+	assert.Equal(t, "8.8.8.8", servers[1].Address)
+	assert.False(t, c.HasServer("180.131.144.144"), "default servers must be dropped")
+
+	// Mutating the caller's slice must not affect the checker.
+	newSet[0].Address = "9.9.9.9"
+	assert.Equal(t, "1.1.1.1", c.Servers()[0].Address)
+
+	// Empty set clears the servers.
+	c.ReplaceServers(nil)
+	assert.Empty(t, c.Servers())
+}
+
+func TestResetServers(t *testing.T) {
+	c := nawala.New()
+	defaults := c.Servers()
+
+	c.SetServers(nawala.DNSServer{Address: "1.1.1.1", Keyword: "cf", QueryType: "A"})
+	c.DeleteServers("180.131.144.144")
+	require.NotEqual(t, defaults, c.Servers())
+
+	c.ResetServers()
+	assert.Equal(t, defaults, c.Servers())
+	assert.False(t, c.HasServer("1.1.1.1"))
+
+	// The restored list is a copy; mutating it must not leak into later resets.
+	c.SetServers(nawala.DNSServer{Address: "180.131.144.144", Keyword: "changed", QueryType: "A"})
+	c.ResetServers()
+	assert.Equal(t, defaults, c.Servers())
+
+	// A checker built with custom servers still resets to the package defaults.
+	custom := nawala.New(nawala.WithServers([]nawala.DNSServer{
+		{Address: "8.8.8.8", Keyword: "google", QueryType: "A"},
+	}))
+	custom.ResetServers()
+	assert.Equal(t, defaults, custom.Servers())
+}
+
+func TestReplaceServersConcurrency(t *testing.T) {
+	c := nawala.New()
+
+	const workers = 50
+	done := make(chan struct{})
+
+	for i := range workers {
+		go func(n int) {
+			c.ReplaceServers([]nawala.DNSServer{
+				{Address: fmt.Sprintf("10.0.0.%d", n), Keyword: "test", QueryType: "A"},
+			})
+			done <- struct{}{}
+		}(i)
+	}
+
+	for range workers {
+		<-done
+	}
+
+	// Whichever swap landed last wins; the set is never partially applied.
+	assert.Len(t, c.Servers(), 1)
+}
+
 func TestCheckInvalidDomain(t *testing.T) {
 	c := nawala.New()
 	ctx := context.Background()
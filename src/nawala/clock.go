@@ -0,0 +1,26 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import "time"
+
+// Clock is the source of time for a [Checker]'s cache expiration and retry
+// backoff. Replace it with [WithClock] to test TTL and backoff behavior
+// deterministically, without sleeping.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After waits for d to elapse and then sends the current time on the
+	// returned channel, like [time.After].
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the default [Clock], backed by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
@@ -0,0 +1,114 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// evaluate judges a probe's response like [Checker.judge] and, when it is
+// not blocked, follows a dangling CNAME chain as configured by
+// [WithMaxCNAMEDepth].
+func (c *Checker) evaluate(ctx context.Context, domain string, srv DNSServer, qtype uint16, resp *dns.Msg, rtt time.Duration) (Result, bool) {
+	result, stop, _ := c.evaluateChain(ctx, domain, srv, qtype, resp, rtt)
+	return result, stop
+}
+
+// evaluateChain is [Checker.evaluate] that also returns the names queried
+// while following the CNAME chain, in order, for [Checker.Explain].
+func (c *Checker) evaluateChain(ctx context.Context, domain string, srv DNSServer, qtype uint16, resp *dns.Msg, rtt time.Duration) (Result, bool, []string) {
+	result, stop := c.judge(domain, srv, resp, rtt)
+	if stop || result.Blocked || c.cnameDepth <= 0 {
+		return result, stop, nil
+	}
+
+	followed, hops, ok := c.followCNAME(ctx, domain, srv, qtype, resp)
+	if ok {
+		followed.Latency += rtt
+		return followed, false, hops
+	}
+	return result, false, hops
+}
+
+// followCNAME re-queries srv for the end of a CNAME chain that resp leaves
+// unresolved, hop by hop, up to c.cnameDepth queries. It returns the result
+// of the first follow-up response judged blocked, reported for domain, and
+// false when no hop is blocked, a query fails, or the chain loops back on a
+// name already seen. The names queried are returned either way.
+func (c *Checker) followCNAME(ctx context.Context, domain string, srv DNSServer, qtype uint16, resp *dns.Msg) (Result, []string, bool) {
+	seen := map[string]struct{}{dns.CanonicalName(domain): {}}
+	name := domain
+	var (
+		elapsed time.Duration
+		hops    []string
+	)
+
+	for range c.cnameDepth {
+		target, ok := danglingCNAME(resp, name, seen)
+		if !ok {
+			return Result{}, hops, false
+		}
+
+		hops = append(hops, target)
+		next, rtt, err := c.probe(ctx, strings.TrimSuffix(target, "."), srv, qtype)
+		if err != nil {
+			return Result{}, hops, false
+		}
+		elapsed += rtt
+
+		result, stop := c.judge(domain, srv, next, elapsed)
+		if result.Blocked {
+			return result, hops, true
+		}
+		if stop {
+			return Result{}, hops, false
+		}
+		resp, name = next, target
+	}
+	return Result{}, hops, false
+}
+
+// danglingCNAME follows the CNAME chain for name through the Answer section
+// of msg and returns its final target when the response does not resolve
+// it, i.e. when no other record is owned by that target. It returns false
+// when name has no CNAME, when the chain is resolved, or when it reaches a
+// name in seen (a cycle); every name visited is added to seen.
+func danglingCNAME(msg *dns.Msg, name string, seen map[string]struct{}) (string, bool) {
+	cur := dns.CanonicalName(name)
+	followed := false
+	for {
+		var target string
+		for _, rr := range msg.Answer {
+			if cname, ok := rr.(*dns.CNAME); ok && dns.CanonicalName(cname.Hdr.Name) == cur {
+				target = dns.CanonicalName(cname.Target)
+				break
+			}
+		}
+		if target == "" {
+			break
+		}
+		if _, loop := seen[target]; loop {
+			return "", false
+		}
+		seen[target] = struct{}{}
+		cur = target
+		followed = true
+	}
+	if !followed {
+		return "", false
+	}
+
+	for _, rr := range msg.Answer {
+		if _, isCNAME := rr.(*dns.CNAME); !isCNAME && dns.CanonicalName(rr.Header().Name) == cur {
+			return "", false
+		}
+	}
+	return cur, true
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDanglingCNAME(t *testing.T) {
+	msg := func(records ...string) *dns.Msg {
+		m := new(dns.Msg)
+		for _, s := range records {
+			rr, err := dns.NewRR(s)
+			require.NoError(t, err)
+			m.Answer = append(m.Answer, rr)
+		}
+		return m
+	}
+
+	tests := []struct {
+		name   string
+		msg    *dns.Msg
+		want   string
+		wantOK bool
+	}{
+		{"no CNAME", msg("example.com. 60 IN A 192.0.2.1"), "", false},
+		{"dangling", msg("example.com. 60 IN CNAME cdn.example.net."), "cdn.example.net.", true},
+		{"dangling chain", msg("example.com. 60 IN CNAME a.example.net.", "a.example.net. 60 IN CNAME B.example.net."), "b.example.net.", true},
+		{"resolved", msg("example.com. 60 IN CNAME cdn.example.net.", "cdn.example.net. 60 IN A 192.0.2.1"), "", false},
+		{"cycle", msg("example.com. 60 IN CNAME a.example.net.", "a.example.net. 60 IN CNAME example.com."), "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen := map[string]struct{}{"example.com.": {}}
+			got, ok := danglingCNAME(tt.msg, "example.com", seen)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// startCNAMEChainDNSServer answers every query with the CNAME listed for the
+// queried name in chain, or with an A record when there is none. Names whose
+// CNAME target is "internetpositif.id." are thus blocked only once the chain
+// is followed to its end.
+func startCNAMEChainDNSServer(t *testing.T, chain map[string]string, queries *atomic.Int32) string {
+	t.Helper()
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		queries.Add(1)
+		m := new(dns.Msg)
+		m.SetReply(r)
+		name := r.Question[0].Name
+		if target, ok := chain[name]; ok {
+			m.Answer = append(m.Answer, &dns.CNAME{
+				Hdr:    dns.RR_Header{Name: name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 60},
+				Target: target,
+			})
+		} else {
+			m.Answer = append(m.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   []byte{93, 184, 216, 34},
+			})
+		}
+		_ = w.WriteMsg(m)
+	})
+	addr, cleanup := startTestDNSServer(t, handler)
+	t.Cleanup(cleanup)
+	return addr
+}
+
+func TestWithMaxCNAMEDepth(t *testing.T) {
+	chain := map[string]string{
+		"example.com.":      "cdn.example.net.",
+		"cdn.example.net.":  "edge.example.org.",
+		"edge.example.org.": "internetpositif.id.",
+		"loop.example.com.": "a.example.net.",
+		"a.example.net.":    "loop.example.com.",
+	}
+
+	newChecker := func(t *testing.T, depth int) (*Checker, *atomic.Int32) {
+		var queries atomic.Int32
+		addr := startCNAMEChainDNSServer(t, chain, &queries)
+		return New(
+			WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithMaxRetries(0),
+			WithCache(nil),
+			WithMaxCNAMEDepth(depth),
+		), &queries
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		c, queries := newChecker(t, 0)
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.False(t, r.Blocked)
+		assert.EqualValues(t, 1, queries.Load())
+	})
+
+	t.Run("followed", func(t *testing.T) {
+		c, queries := newChecker(t, 3)
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		require.NoError(t, r.Error)
+		assert.True(t, r.Blocked)
+		assert.Equal(t, "example.com", r.Domain)
+		assert.Equal(t, BlockReasonRedirect, r.BlockReason)
+		assert.EqualValues(t, 3, queries.Load())
+	})
+
+	t.Run("depth exhausted", func(t *testing.T) {
+		c, queries := newChecker(t, 1)
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.False(t, r.Blocked)
+		assert.EqualValues(t, 2, queries.Load())
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		c, queries := newChecker(t, 10)
+		r, err := c.CheckOne(context.Background(), "loop.example.com")
+		require.NoError(t, err)
+		require.NoError(t, r.Error)
+		assert.False(t, r.Blocked)
+		// loop → a.example.net, then a.example.net → loop, which was seen.
+		assert.EqualValues(t, 2, queries.Load())
+	})
+}
@@ -22,38 +22,57 @@ import (
 //     connection is non-blocking: if the channel is empty a new connection is
 //     dialled immediately. Returning a connection is also non-blocking: if the
 //     channel is full the connection is closed and discarded.
-//   - There are no background goroutines. Connections that have gone stale
-//     (e.g. the server enforced an idle timeout) will surface as an [io.EOF]
-//     or similar error on the next [connPool.exchange] call. The broken
-//     connection is discarded and the caller's existing retry / failover logic
-//     handles the rest.
+//   - There are no background goroutines. When an idle timeout is set,
+//     connections idle for longer are evicted lazily by [connPool.get]
+//     instead of being reused. Connections that have gone stale anyway
+//     (e.g. the server enforced a shorter idle timeout) will surface as an
+//     [io.EOF] or similar error on the next [connPool.exchange] call. The
+//     broken connection is discarded and the caller's existing retry /
+//     failover logic handles the rest.
 //   - [connPool.close] drains and closes every idle connection in the pool.
 //     It is called from [Checker.Close].
 type connPool struct {
-	client *dns.Client
-	addr   string
-	pool   chan *dns.Conn
+	client      *dns.Client
+	addr        string
+	idleTimeout time.Duration // 0 means idle connections never expire
+	pool        chan idleConn
+}
+
+// idleConn is a pooled connection together with the time it was returned.
+type idleConn struct {
+	conn  *dns.Conn
+	since time.Time
 }
 
 // newConnPool constructs a [connPool] for the given client and server address.
-// size is the maximum number of idle connections to keep open simultaneously.
-func newConnPool(client *dns.Client, addr string, size int) *connPool {
+// size is the maximum number of idle connections to keep open simultaneously,
+// and idleTimeout, when positive, is how long a connection may sit idle in the
+// pool before it is evicted rather than reused.
+func newConnPool(client *dns.Client, addr string, size int, idleTimeout time.Duration) *connPool {
 	return &connPool{
-		client: client,
-		addr:   addr,
-		pool:   make(chan *dns.Conn, size),
+		client:      client,
+		addr:        addr,
+		idleTimeout: idleTimeout,
+		pool:        make(chan idleConn, size),
 	}
 }
 
 // get returns an idle connection from the pool, dialling a new one when the
-// pool is empty. The returned connection must be passed back to [connPool.put]
+// pool is empty. Connections idle for longer than the idle timeout are closed
+// and skipped. The returned connection must be passed back to [connPool.put]
 // after use if it is still healthy.
 func (p *connPool) get(ctx context.Context) (*dns.Conn, error) {
-	select {
-	case conn := <-p.pool:
-		return conn, nil
-	default:
-		return p.client.DialContext(ctx, p.addr)
+	for {
+		select {
+		case ic := <-p.pool:
+			if p.idleTimeout > 0 && time.Since(ic.since) > p.idleTimeout {
+				_ = ic.conn.Close()
+				continue
+			}
+			return ic.conn, nil
+		default:
+			return p.client.DialContext(ctx, p.addr)
+		}
 	}
 }
 
@@ -64,7 +83,7 @@ func (p *connPool) put(conn *dns.Conn) {
 		return
 	}
 	select {
-	case p.pool <- conn:
+	case p.pool <- idleConn{conn: conn, since: time.Now()}:
 	default:
 		_ = conn.Close()
 	}
@@ -83,7 +102,7 @@ func (p *connPool) exchange(ctx context.Context, msg *dns.Msg) (*dns.Msg, time.D
 		return nil, 0, err
 	}
 
-	r, rtt, err := p.client.ExchangeWithConnContext(ctx, msg, conn)
+	r, rtt, err := exchangeConn(ctx, p.client, msg, conn)
 	if err == nil {
 		p.put(conn)
 		return r, rtt, nil
@@ -106,7 +125,7 @@ func (p *connPool) exchange(ctx context.Context, msg *dns.Msg) (*dns.Msg, time.D
 		return nil, 0, err // return the original error
 	}
 
-	r2, rtt2, err2 := p.client.ExchangeWithConnContext(ctx, msg, conn2)
+	r2, rtt2, err2 := exchangeConn(ctx, p.client, msg, conn2)
 	if err2 != nil {
 		_ = conn2.Close()
 		return nil, 0, err2
@@ -121,8 +140,8 @@ func (p *connPool) exchange(ctx context.Context, msg *dns.Msg) (*dns.Msg, time.D
 func (p *connPool) close() {
 	for {
 		select {
-		case conn := <-p.pool:
-			_ = conn.Close()
+		case ic := <-p.pool:
+			_ = ic.conn.Close()
 		default:
 			return
 		}
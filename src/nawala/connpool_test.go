@@ -236,7 +236,7 @@ func TestConnPoolGetPutClose(t *testing.T) {
 	defer cleanup()
 
 	client := &dns.Client{Net: "tcp", Timeout: 5 * time.Second}
-	pool := newConnPool(client, addr, 2)
+	pool := newConnPool(client, addr, 2, 0)
 
 	ctx := context.Background()
 
@@ -286,14 +286,14 @@ func TestConnPoolExchange_StaleConnRedial(t *testing.T) {
 	defer cleanup()
 
 	client := &dns.Client{Net: "tcp", Timeout: 5 * time.Second}
-	pool := newConnPool(client, addr, 2)
+	pool := newConnPool(client, addr, 2, 0)
 
 	// Inject a closed (stale) connection into the pool to simulate an idle
 	// connection that expired on the server side.
 	stale, err := client.DialContext(context.Background(), addr)
 	require.NoError(t, err)
-	_ = stale.Close()  // close it so the next ExchangeWithConnContext returns io.EOF
-	pool.pool <- stale // put the stale conn directly into the pool channel
+	_ = stale.Close() // close it so the next ExchangeWithConnContext returns io.EOF
+	pool.put(stale)   // put the stale conn directly into the pool
 
 	// exchange should detect the EOF, discard the stale conn, redial, and succeed.
 	ctx := context.Background()
@@ -324,8 +324,8 @@ func TestConnPoolExchange_StaleConnRedialFails(t *testing.T) {
 
 	cleanup() // server gone — redial will fail
 
-	pool := newConnPool(client, addr, 2)
-	pool.pool <- stalConn // inject the stale conn
+	pool := newConnPool(client, addr, 2, 0)
+	pool.put(stalConn) // inject the stale conn
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
@@ -352,7 +352,7 @@ func TestConnPoolExchange_NonEOFError(t *testing.T) {
 	defer cleanup()
 
 	client := &dns.Client{Net: "tcp", Timeout: 5 * time.Second}
-	pool := newConnPool(client, addr, 1)
+	pool := newConnPool(client, addr, 1, 0)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
@@ -371,7 +371,7 @@ func TestConnPoolExchange_NonEOFError(t *testing.T) {
 func TestConnPoolExchange_GetDialFails(t *testing.T) {
 	client := &dns.Client{Net: "tcp", Timeout: 200 * time.Millisecond}
 	// Point the pool at an address nothing is listening on.
-	pool := newConnPool(client, "127.0.0.1:19977", 1)
+	pool := newConnPool(client, "127.0.0.1:19977", 1, 0)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
 	defer cancel()
@@ -512,8 +512,8 @@ func TestConnPoolExchange_RedialExchangeFails(t *testing.T) {
 	// also dropped immediately, causing ExchangeWithConnContext to fail.
 	dropConns <- struct{}{}
 
-	pool := newConnPool(client, addr, 1)
-	pool.pool <- stale // inject stale conn
+	pool := newConnPool(client, addr, 1, 0)
+	pool.put(stale) // inject stale conn
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -526,3 +526,103 @@ func TestConnPoolExchange_RedialExchangeFails(t *testing.T) {
 	// The redial succeeded but the exchange on the fresh conn failed → err2 != nil.
 	assert.Error(t, err, "expected error when fresh redial exchange also fails")
 }
+
+func TestConnPoolIdleTimeoutEviction(t *testing.T) {
+	addr, cleanup := startTCPDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		_ = w.WriteMsg(m)
+	}))
+	defer cleanup()
+
+	client := &dns.Client{Net: "tcp", Timeout: 5 * time.Second}
+	pool := newConnPool(client, addr, 2, 50*time.Millisecond)
+	defer pool.close()
+
+	ctx := context.Background()
+
+	conn, err := pool.get(ctx)
+	require.NoError(t, err)
+	pool.put(conn)
+
+	// Within the idle timeout the same connection is reused.
+	reused, err := pool.get(ctx)
+	require.NoError(t, err)
+	assert.Same(t, conn, reused)
+	pool.put(reused)
+
+	// Past the idle timeout it is evicted and a fresh one is dialled.
+	time.Sleep(100 * time.Millisecond)
+	fresh, err := pool.get(ctx)
+	require.NoError(t, err)
+	assert.NotSame(t, conn, fresh)
+	assert.Empty(t, pool.pool, "evicted connection must not be returned to the pool")
+	pool.put(fresh)
+}
+
+func TestWithConnectionPool(t *testing.T) {
+	c := New(
+		WithProtocol("tcp"),
+		WithServers([]DNSServer{{Address: "127.0.0.1:5353", Keyword: "test", QueryType: "A"}}),
+		WithConnectionPool(3, 30*time.Second),
+	)
+	defer c.Close()
+
+	p := c.connPools["127.0.0.1:5353"]
+	require.NotNil(t, p)
+	assert.Equal(t, 3, cap(p.pool))
+	assert.Equal(t, 30*time.Second, p.idleTimeout)
+
+	// UDP stays unpooled, exactly like WithKeepAlive.
+	udp := New(WithConnectionPool(3, time.Second))
+	assert.Nil(t, udp.connPools)
+}
+
+// TestKeepAlivePort53Address verifies that a server configured with an
+// explicit ":53", or with no port at all, is dialed on port 53 by the
+// keep-alive pool. It needs to bind the privileged port and is skipped
+// where that is not allowed.
+func TestKeepAlivePort53Address(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:53")
+	if err != nil {
+		t.Skipf("cannot listen on 127.0.0.1:53: %v", err)
+	}
+	server := &dns.Server{
+		Listener: listener,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(r)
+			m.Answer = append(m.Answer, &dns.CNAME{
+				Hdr:    dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 60},
+				Target: "internetpositif.id.",
+			})
+			_ = w.WriteMsg(m)
+		}),
+	}
+	started := make(chan struct{})
+	server.NotifyStartedFunc = func() { close(started) }
+	go func() { _ = server.ActivateAndServe() }()
+	<-started
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	for _, addr := range []string{"127.0.0.1:53", "127.0.0.1"} {
+		t.Run(addr, func(t *testing.T) {
+			c := New(
+				WithProtocol("tcp"),
+				WithKeepAlive(2),
+				WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+				WithMaxRetries(0),
+				WithCache(nil),
+			)
+			defer c.Close()
+			assert.Equal(t, addr, c.Servers()[0].Address, "the configured address is kept")
+
+			for range 2 {
+				r, err := c.CheckOne(context.Background(), "example.com")
+				require.NoError(t, err)
+				require.NoError(t, r.Error)
+				assert.True(t, r.Blocked)
+			}
+		})
+	}
+}
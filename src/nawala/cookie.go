@@ -0,0 +1,54 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// Cookie lengths defined by RFC 7873, in hex characters.
+const (
+	clientCookieLen    = 16 // 8-byte client cookie
+	minServerCookieLen = 16 // 8-byte minimum server cookie
+	maxServerCookieLen = 64 // 32-byte maximum server cookie
+)
+
+// newClientCookie returns a random 8-byte client cookie, hex-encoded as
+// expected by [dns.EDNS0_COOKIE].
+func newClientCookie() string {
+	var b [clientCookieLen / 2]byte
+	_, _ = rand.Read(b[:]) // crypto/rand.Read never returns an error
+	return hex.EncodeToString(b[:])
+}
+
+// verifyServerCookie reports whether resp carries a COOKIE option that
+// echoes clientCookie followed by a server cookie of valid length.
+func verifyServerCookie(resp *dns.Msg, clientCookie string) bool {
+	opt := resp.IsEdns0()
+	if opt == nil {
+		return false
+	}
+
+	for _, o := range opt.Option {
+		cookie, ok := o.(*dns.EDNS0_COOKIE)
+		if !ok {
+			continue
+		}
+
+		n := len(cookie.Cookie) - clientCookieLen
+		if n < minServerCookieLen || n > maxServerCookieLen {
+			continue
+		}
+		if strings.EqualFold(cookie.Cookie[:clientCookieLen], clientCookie) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCookieReply builds a reply to r that carries cookie in its OPT record.
+func newCookieReply(r *dns.Msg, cookie string) *dns.Msg {
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.SetEdns0(1232, false)
+	if cookie != "" {
+		opt := m.IsEdns0()
+		opt.Option = append(opt.Option, &dns.EDNS0_COOKIE{Code: dns.EDNS0COOKIE, Cookie: cookie})
+	}
+	return m
+}
+
+// requestCookie returns the client cookie sent with r, if any.
+func requestCookie(r *dns.Msg) string {
+	if opt := r.IsEdns0(); opt != nil {
+		for _, o := range opt.Option {
+			if c, ok := o.(*dns.EDNS0_COOKIE); ok {
+				return c.Cookie
+			}
+		}
+	}
+	return ""
+}
+
+func TestNewClientCookie(t *testing.T) {
+	a, b := newClientCookie(), newClientCookie()
+	assert.Len(t, a, clientCookieLen)
+	assert.NotEqual(t, a, b)
+}
+
+func TestVerifyServerCookie(t *testing.T) {
+	client := "0123456789abcdef"
+	server := "fedcba9876543210"
+	q := new(dns.Msg)
+	q.SetQuestion("example.com.", dns.TypeA)
+
+	tests := []struct {
+		name string
+		resp *dns.Msg
+		want bool
+	}{
+		{"valid", newCookieReply(q, client+server), true},
+		{"valid upper-case", newCookieReply(q, strings.ToUpper(client+server)), true},
+		{"wrong client cookie", newCookieReply(q, "aaaaaaaaaaaaaaaa"+server), false},
+		{"client cookie only", newCookieReply(q, client), false},
+		{"server cookie too long", newCookieReply(q, client+strings.Repeat("ab", 33)), false},
+		{"no cookie", newCookieReply(q, ""), false},
+		{"no OPT", new(dns.Msg).SetReply(q), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, verifyServerCookie(tt.resp, client))
+		})
+	}
+}
+
+func TestWithDNSCookie(t *testing.T) {
+	var echo atomic.Bool
+	var lastCookie atomic.Value
+	lastCookie.Store("")
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		client := requestCookie(r)
+		lastCookie.Store(client)
+		reply := ""
+		if echo.Load() && client != "" {
+			reply = client + "fedcba9876543210"
+		}
+		_ = w.WriteMsg(newCookieReply(r, reply))
+	})
+	addr, cleanup := startTestDNSServer(t, handler)
+	defer cleanup()
+
+	servers := []DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}
+	c := New(WithServers(servers), WithCache(nil), WithDNSCookie(true))
+
+	echo.Store(true)
+	result, err := c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	assert.True(t, result.CookieVerified)
+	assert.Equal(t, c.clientCookie, lastCookie.Load())
+
+	// A server that drops the cookie is reported as unverified.
+	echo.Store(false)
+	result, err = c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	assert.False(t, result.CookieVerified)
+
+	// Without the option no cookie is sent.
+	echo.Store(true)
+	plain := New(WithServers(servers), WithCache(nil))
+	result, err = plain.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.Empty(t, lastCookie.Load())
+	assert.False(t, result.CookieVerified)
+}
@@ -5,6 +5,8 @@
 
 package nawala
 
+import "strings"
+
 // WithServer adds or replaces a DNS server in the checker's configuration.
 // If a server with the same address already exists, it will be replaced.
 //
@@ -12,8 +14,9 @@ package nawala
 // same add-or-replace behaviour and is safe to call after construction.
 func WithServer(server DNSServer) Option {
 	return func(c *Checker) {
+		server.Address = strings.TrimSpace(server.Address)
 		for i, s := range c.servers {
-			if s.Address == server.Address {
+			if sameAddress(s.Address, server.Address) {
 				c.servers[i] = server
 				return
 			}
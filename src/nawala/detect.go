@@ -0,0 +1,230 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"net"
+	"slices"
+
+	"github.com/miekg/dns"
+)
+
+// DetectOptions configures [DetectBlock].
+type DetectOptions struct {
+	// Keywords are block indicators searched for in the response records.
+	// Matching is case-insensitive and, unless MatchMode says otherwise,
+	// a substring match; the first keyword found wins. Note that with
+	// substring matching an empty keyword matches any record.
+	Keywords []string
+
+	// BlockIPs are sinkhole addresses. A response whose Answer section
+	// contains an A or AAAA record resolving to one of them is blocked.
+	BlockIPs []net.IP
+
+	// MatchScope selects how much of each record the keywords are matched
+	// against: [MatchScopeRecord] (the default when empty) or
+	// [MatchScopeData].
+	MatchScope string
+
+	// MatchMode selects how keywords are compared: [MatchModeSubstring]
+	// (the default when empty) or [MatchModeLabel].
+	MatchMode string
+
+	// JoinSegments additionally matches keywords against the concatenated
+	// segments of each record (TXT character-strings, EDE texts across OPT
+	// options), catching a keyword split across segment boundaries.
+	JoinSegments bool
+
+	// SinkholeDetection additionally treats a response as blocked, with
+	// [BlockReasonSinkhole], when the queried name is a public domain and
+	// every address in the Answer section is a loopback, private, or
+	// reserved one (see [WithSinkholeDetection]).
+	SinkholeDetection bool
+
+	// AllMatches makes a blocked verdict also report every block indicator
+	// in the response in [BlockDetails.Signals], rather than stopping at the
+	// first one. It costs a full scan of the response, and does not change
+	// the verdict or the other [BlockDetails] fields.
+	AllMatches bool
+}
+
+// BlockDetails describes why [DetectBlock] considered a response blocked.
+type BlockDetails struct {
+	// Reason classifies the block; [BlockReasonNone] when not blocked.
+	Reason BlockReason
+
+	// Keyword is the entry of [DetectOptions.Keywords] that matched, if any.
+	Keyword string
+
+	// IP is the entry of [DetectOptions.BlockIPs] that matched, if any.
+	IP net.IP
+
+	// Section is the response section holding the matched indicator:
+	// [SectionAnswer], [SectionAuthority], or [SectionAdditional].
+	// Block IPs always match in [SectionAnswer].
+	Section string
+
+	// Signals lists every block indicator found in the response, in the
+	// order the rules are tried. It is only set with
+	// [DetectOptions.AllMatches].
+	Signals []BlockSignal
+}
+
+// BlockSignal is one block indicator found in a response, as reported in
+// [BlockDetails.Signals] and [Result.Signals]. Several signals for one
+// response reveal layered filtering, such as a Nawala CNAME redirect in the
+// Answer section together with a Komdigi EDE in the Additional section.
+type BlockSignal struct {
+	// Reason is the kind of block the indicator points to. Unlike
+	// [BlockDetails.Reason], which classifies the response as a whole, it
+	// is derived from the indicator's own section: a keyword in a CNAME
+	// answer is a [BlockReasonRedirect], one in an EDE option carries the
+	// EDE's reason.
+	Reason BlockReason
+
+	// Keyword is the entry of [DetectOptions.Keywords] that matched, if any.
+	Keyword string
+
+	// IP is the matched block IP or sinkhole address, if any.
+	IP net.IP
+
+	// Section is the response section holding the indicator.
+	Section string
+}
+
+// DetectBlock applies the checker's block detection rules to an existing
+// DNS response, without sending any query. It lets responses obtained
+// elsewhere (a forwarder, a cache, or a packet capture) be classified
+// exactly as a [Checker] would classify them.
+//
+// The response is blocked when any keyword matches (see
+// [DetectOptions.MatchScope]), any answer resolves to one of
+// [DetectOptions.BlockIPs], or, with [DetectOptions.SinkholeDetection], the
+// answers all point to private or reserved addresses. The rules are tried
+// in that order. When blocked, the details carry the matched indicator and
+// the [BlockReason], derived from the response's filtering EDE code
+// ([RFC 8914]) or CNAME redirect. With [DetectOptions.AllMatches], every
+// indicator is also listed in [BlockDetails.Signals].
+//
+//	blocked, details := nawala.DetectBlock(msg, nawala.DetectOptions{
+//	    Keywords: []string{"internetpositif", "trustpositif"},
+//	})
+//	if blocked {
+//	    fmt.Println("blocked:", details.Reason, "via", details.Keyword)
+//	}
+//
+// A nil msg is never blocked.
+//
+// [RFC 8914]: https://datatracker.ietf.org/doc/html/rfc8914
+func DetectBlock(msg *dns.Msg, opts DetectOptions) (bool, BlockDetails) {
+	if msg == nil {
+		return false, BlockDetails{}
+	}
+
+	blocked, details := detectFirst(msg, opts)
+	if blocked && opts.AllMatches {
+		details.Signals = blockSignals(msg, opts)
+	}
+	return blocked, details
+}
+
+// detectFirst implements [DetectBlock], stopping at the first indicator.
+func detectFirst(msg *dns.Msg, opts DetectOptions) (bool, BlockDetails) {
+	for _, kw := range opts.Keywords {
+		if section := matchKeywordSection(msg, kw, opts.MatchScope, opts.MatchMode, opts.JoinSegments); section != "" {
+			return true, BlockDetails{Reason: classifyBlock(msg), Keyword: kw, Section: section}
+		}
+	}
+
+	if ip := matchBlockIP(msg, opts.BlockIPs); ip != nil {
+		return true, BlockDetails{Reason: classifyBlock(msg), IP: ip, Section: SectionAnswer}
+	}
+
+	if opts.SinkholeDetection {
+		if ip := sinkholeIP(msg); ip != nil {
+			return true, BlockDetails{Reason: BlockReasonSinkhole, IP: ip, Section: SectionAnswer}
+		}
+	}
+
+	return false, BlockDetails{}
+}
+
+// blockSignals collects every block indicator in msg for
+// [DetectOptions.AllMatches]: each keyword in each section it appears in,
+// each matched block IP, a sinkhole answer, and each filtering EDE code not
+// already reported through a keyword.
+func blockSignals(msg *dns.Msg, opts DetectOptions) []BlockSignal {
+	var signals []BlockSignal
+	for _, kw := range opts.Keywords {
+		for _, section := range keywordSections(msg, kw, opts.MatchScope, opts.MatchMode, opts.JoinSegments, true) {
+			signals = append(signals, BlockSignal{Reason: sectionReason(msg, section), Keyword: kw, Section: section})
+		}
+	}
+
+	for _, blockIP := range opts.BlockIPs {
+		if slices.ContainsFunc(resolvedIPs(msg), blockIP.Equal) {
+			signals = append(signals, BlockSignal{Reason: classifyBlock(msg), IP: blockIP, Section: SectionAnswer})
+		}
+	}
+
+	if opts.SinkholeDetection {
+		if ip := sinkholeIP(msg); ip != nil {
+			signals = append(signals, BlockSignal{Reason: BlockReasonSinkhole, IP: ip, Section: SectionAnswer})
+		}
+	}
+
+	for _, ede := range extendedErrors(msg) {
+		reason, ok := blockReasonFromEDE(ede.InfoCode)
+		if !ok {
+			continue
+		}
+		signal := BlockSignal{Reason: reason, Section: SectionAdditional}
+		if !slices.ContainsFunc(signals, func(s BlockSignal) bool {
+			return s.Section == signal.Section && s.Reason == signal.Reason
+		}) {
+			signals = append(signals, signal)
+		}
+	}
+	return signals
+}
+
+// sectionReason classifies an indicator found in section of msg: a CNAME
+// answer is a redirect and the Additional section carries the reason of the
+// first filtering EDE, falling back to [classifyBlock] for the whole message.
+func sectionReason(msg *dns.Msg, section string) BlockReason {
+	switch section {
+	case SectionAnswer:
+		for _, rr := range msg.Answer {
+			if _, ok := rr.(*dns.CNAME); ok {
+				return BlockReasonRedirect
+			}
+		}
+	case SectionAdditional:
+		for _, ede := range extendedErrors(msg) {
+			if reason, ok := blockReasonFromEDE(ede.InfoCode); ok {
+				return reason
+			}
+		}
+	}
+	return classifyBlock(msg)
+}
+
+// matchBlockIP returns the first entry of blockIPs that an A or AAAA record
+// in the Answer section of msg resolves to, or nil if there is none.
+func matchBlockIP(msg *dns.Msg, blockIPs []net.IP) net.IP {
+	if len(blockIPs) == 0 {
+		return nil
+	}
+
+	for _, ip := range resolvedIPs(msg) {
+		for _, blockIP := range blockIPs {
+			if ip.Equal(blockIP) {
+				return blockIP
+			}
+		}
+	}
+	return nil
+}
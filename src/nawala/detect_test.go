@@ -0,0 +1,162 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/H0llyW00dzZ/nawala-checker/src/nawala"
+)
+
+func newDetectMsg(t *testing.T, records ...string) *dns.Msg {
+	t.Helper()
+	m := new(dns.Msg)
+	m.SetQuestion("example.com.", dns.TypeA)
+	for _, s := range records {
+		rr, err := dns.NewRR(s)
+		if err != nil {
+			t.Fatalf("dns.NewRR(%q): %v", s, err)
+		}
+		m.Answer = append(m.Answer, rr)
+	}
+	return m
+}
+
+func TestDetectBlock(t *testing.T) {
+	redirect := newDetectMsg(t, "example.com. 60 IN CNAME internetpositif.id.")
+	sinkhole := newDetectMsg(t, "example.com. 60 IN A 36.86.63.185")
+	clean := newDetectMsg(t, "example.com. 60 IN A 93.184.216.34")
+	bogon := newDetectMsg(t, "example.com. 60 IN A 127.0.0.1", "example.com. 60 IN A 10.0.0.1")
+	mixed := newDetectMsg(t, "example.com. 60 IN A 10.0.0.1", "example.com. 60 IN A 93.184.216.34")
+	local := newDetectMsg(t, "printer.local. 60 IN A 192.168.1.10")
+	local.Question[0].Name = "printer.local."
+	ede := newDetectMsg(t, "example.com. 60 IN A 103.155.26.28")
+	ede.SetEdns0(1232, false)
+	opt := ede.IsEdns0()
+	opt.Option = append(opt.Option, &dns.EDNS0_EDE{
+		InfoCode:  dns.ExtendedErrorCodeBlocked,
+		ExtraText: "trustpositif.komdigi.go.id",
+	})
+
+	tests := []struct {
+		name        string
+		msg         *dns.Msg
+		opts        nawala.DetectOptions
+		wantBlocked bool
+		want        nawala.BlockDetails
+	}{
+		{
+			name:        "keyword redirect",
+			msg:         redirect,
+			opts:        nawala.DetectOptions{Keywords: []string{"trustpositif", "InternetPositif"}},
+			wantBlocked: true,
+			want:        nawala.BlockDetails{Reason: nawala.BlockReasonRedirect, Keyword: "InternetPositif", Section: nawala.SectionAnswer},
+		},
+		{
+			name:        "block IP",
+			msg:         sinkhole,
+			opts:        nawala.DetectOptions{Keywords: []string{"internetpositif"}, BlockIPs: []net.IP{net.ParseIP("36.86.63.185")}},
+			wantBlocked: true,
+			want:        nawala.BlockDetails{Reason: nawala.BlockReasonUnknown, IP: net.ParseIP("36.86.63.185"), Section: nawala.SectionAnswer},
+		},
+		{
+			name:        "EDE in additional section",
+			msg:         ede,
+			opts:        nawala.DetectOptions{Keywords: []string{"trustpositif"}},
+			wantBlocked: true,
+			want:        nawala.BlockDetails{Reason: nawala.BlockReasonBlocked, Keyword: "trustpositif", Section: nawala.SectionAdditional},
+		},
+		{
+			name: "clean",
+			msg:  clean,
+			opts: nawala.DetectOptions{Keywords: []string{"internetpositif"}, BlockIPs: []net.IP{net.ParseIP("36.86.63.185")}},
+		},
+		{
+			name: "data scope ignores owner name",
+			msg:  clean,
+			opts: nawala.DetectOptions{Keywords: []string{"example"}, MatchScope: nawala.MatchScopeData},
+		},
+		{
+			name:        "sinkhole",
+			msg:         bogon,
+			opts:        nawala.DetectOptions{Keywords: []string{"internetpositif"}, SinkholeDetection: true},
+			wantBlocked: true,
+			want:        nawala.BlockDetails{Reason: nawala.BlockReasonSinkhole, IP: net.ParseIP("127.0.0.1"), Section: nawala.SectionAnswer},
+		},
+		{
+			name: "sinkhole detection disabled",
+			msg:  bogon,
+			opts: nawala.DetectOptions{Keywords: []string{"internetpositif"}},
+		},
+		{
+			name: "sinkhole with a public address",
+			msg:  mixed,
+			opts: nawala.DetectOptions{Keywords: []string{"internetpositif"}, SinkholeDetection: true},
+		},
+		{
+			name: "sinkhole ignores special-use TLD",
+			msg:  local,
+			opts: nawala.DetectOptions{Keywords: []string{"internetpositif"}, SinkholeDetection: true},
+		},
+		{
+			name: "nil message",
+			opts: nawala.DetectOptions{Keywords: []string{"internetpositif"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocked, details := nawala.DetectBlock(tt.msg, tt.opts)
+			assert.Equal(t, tt.wantBlocked, blocked)
+			assert.Equal(t, tt.want, details)
+		})
+	}
+}
+
+func TestDetectBlockAllMatches(t *testing.T) {
+	// Layered filtering: a Nawala CNAME redirect plus a Komdigi EDE.
+	layered := newDetectMsg(t, "example.com. 60 IN CNAME internetpositif.id.", "internetpositif.id. 60 IN A 36.86.63.185")
+	layered.SetEdns0(1232, false)
+	opt := layered.IsEdns0()
+	opt.Option = append(opt.Option,
+		&dns.EDNS0_EDE{InfoCode: dns.ExtendedErrorCodeBlocked, ExtraText: "trustpositif.komdigi.go.id"},
+		&dns.EDNS0_EDE{InfoCode: dns.ExtendedErrorCodeCensored},
+	)
+
+	opts := nawala.DetectOptions{
+		Keywords: []string{"internetpositif", "trustpositif"},
+		BlockIPs: []net.IP{net.ParseIP("36.86.63.185")},
+	}
+
+	// Without AllMatches the first indicator wins and no signals are listed.
+	blocked, first := nawala.DetectBlock(layered, opts)
+	require.True(t, blocked)
+	assert.Nil(t, first.Signals)
+
+	opts.AllMatches = true
+	blocked, details := nawala.DetectBlock(layered, opts)
+	require.True(t, blocked)
+	assert.Equal(t, first.Reason, details.Reason, "AllMatches must not change the verdict details")
+	assert.Equal(t, first.Keyword, details.Keyword)
+	assert.Equal(t, first.Section, details.Section)
+	assert.Equal(t, []nawala.BlockSignal{
+		{Reason: nawala.BlockReasonRedirect, Keyword: "internetpositif", Section: nawala.SectionAnswer},
+		{Reason: nawala.BlockReasonBlocked, Keyword: "trustpositif", Section: nawala.SectionAdditional},
+		{Reason: nawala.BlockReasonBlocked, IP: net.ParseIP("36.86.63.185"), Section: nawala.SectionAnswer},
+		{Reason: nawala.BlockReasonCensored, Section: nawala.SectionAdditional},
+	}, details.Signals)
+
+	// A clean response reports no signals even with AllMatches.
+	clean := newDetectMsg(t, "example.com. 60 IN A 93.184.216.34")
+	blocked, details = nawala.DetectBlock(clean, opts)
+	assert.False(t, blocked)
+	assert.Nil(t, details.Signals)
+}
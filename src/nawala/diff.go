@@ -0,0 +1,67 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+// Diff compares two runs of [Checker.Check] over the same domains and
+// reports which domains changed blocking state, matching results by
+// [Result.Domain]. It is the building block for "alert me when X gets
+// blocked" workflows:
+//
+//	blocked, unblocked := nawala.Diff(previous, current)
+//	for _, d := range blocked {
+//	    fmt.Println("newly blocked:", d)
+//	}
+//
+// Only domains with a successful result in both runs are compared: a result
+// carrying an error says nothing about the blocking state, so a transient
+// failure never shows up as a block or an unblock. [Result.Degraded]
+// results are skipped for the same reason. Domains present in only
+// one run are likewise ignored. If a domain appears more than once in a run
+// it counts as blocked when any of its successful results is blocked.
+//
+// Both returned slices follow the order of first appearance in newResults.
+func Diff(oldResults, newResults []Result) (newlyBlocked, newlyUnblocked []string) {
+	before := blockedStates(oldResults)
+	after := blockedStates(newResults)
+
+	seen := make(map[string]struct{}, len(after))
+	for _, r := range newResults {
+		if _, dup := seen[r.Domain]; dup {
+			continue
+		}
+
+		now, ok := after[r.Domain]
+		if !ok {
+			continue
+		}
+		was, ok := before[r.Domain]
+		if !ok {
+			continue
+		}
+		seen[r.Domain] = struct{}{}
+
+		switch {
+		case now && !was:
+			newlyBlocked = append(newlyBlocked, r.Domain)
+		case was && !now:
+			newlyUnblocked = append(newlyUnblocked, r.Domain)
+		}
+	}
+	return newlyBlocked, newlyUnblocked
+}
+
+// blockedStates maps each domain with at least one successful, non-degraded
+// result to whether any of its successful results is blocked.
+func blockedStates(results []Result) map[string]bool {
+	states := make(map[string]bool, len(results))
+	for _, r := range results {
+		if r.Error != nil || r.Degraded {
+			continue
+		}
+		states[r.Domain] = states[r.Domain] || r.Blocked
+	}
+	return states
+}
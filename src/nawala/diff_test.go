@@ -0,0 +1,60 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/H0llyW00dzZ/nawala-checker/src/nawala"
+)
+
+func TestDiff(t *testing.T) {
+	previous := []nawala.Result{
+		{Domain: "stays-blocked.com", Blocked: true},
+		{Domain: "unblocked.com", Blocked: true},
+		{Domain: "blocked.com"},
+		{Domain: "stays-clean.com"},
+		{Domain: "was-error.com", Error: nawala.ErrAllDNSFailed},
+		{Domain: "now-error.com", Blocked: true},
+		{Domain: "removed.com", Blocked: true},
+		{Domain: "now-degraded.com", Blocked: true},
+	}
+	current := []nawala.Result{
+		{Domain: "blocked.com", Blocked: true},
+		{Domain: "stays-clean.com"},
+		{Domain: "unblocked.com"},
+		{Domain: "stays-blocked.com", Blocked: true},
+		{Domain: "was-error.com", Blocked: true},
+		{Domain: "now-error.com", Error: nawala.ErrAllDNSFailed},
+		{Domain: "added.com", Blocked: true},
+		{Domain: "now-degraded.com", Degraded: true},
+	}
+
+	blocked, unblocked := nawala.Diff(previous, current)
+	assert.Equal(t, []string{"blocked.com"}, blocked)
+	assert.Equal(t, []string{"unblocked.com"}, unblocked)
+}
+
+func TestDiffDuplicates(t *testing.T) {
+	previous := []nawala.Result{{Domain: "a.com"}}
+	current := []nawala.Result{
+		{Domain: "a.com"},
+		{Domain: "a.com", Blocked: true},
+		{Domain: "a.com", Error: nawala.ErrAllDNSFailed},
+	}
+
+	blocked, unblocked := nawala.Diff(previous, current)
+	assert.Equal(t, []string{"a.com"}, blocked, "reported once, blocked by any result")
+	assert.Empty(t, unblocked)
+}
+
+func TestDiffEmpty(t *testing.T) {
+	blocked, unblocked := nawala.Diff(nil, nil)
+	assert.Nil(t, blocked)
+	assert.Nil(t, unblocked)
+}
@@ -10,36 +10,48 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"slices"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/miekg/dns"
 )
 
 // parseQueryType converts a string query type (e.g., "ANY", "TXT", "A")
-// to the corresponding dns library constant.
+// to the corresponding dns library constant. Unknown types default to A.
 func parseQueryType(qtype string) uint16 {
+	t, _ := lookupQueryType(qtype)
+	return t
+}
+
+// lookupQueryType converts a string query type to the corresponding dns
+// library constant, reporting false (and dns.TypeA) for unknown types.
+func lookupQueryType(qtype string) (uint16, bool) {
 	switch strings.ToUpper(strings.TrimSpace(qtype)) {
 	case "A":
-		return dns.TypeA
+		return dns.TypeA, true
 	case "AAAA":
-		return dns.TypeAAAA
+		return dns.TypeAAAA, true
 	case "CNAME":
-		return dns.TypeCNAME
+		return dns.TypeCNAME, true
 	case "MX":
-		return dns.TypeMX
+		return dns.TypeMX, true
 	case "NS":
-		return dns.TypeNS
+		return dns.TypeNS, true
 	case "TXT":
-		return dns.TypeTXT
+		return dns.TypeTXT, true
 	case "SOA":
-		return dns.TypeSOA
+		return dns.TypeSOA, true
 	case "SRV":
-		return dns.TypeSRV
+		return dns.TypeSRV, true
+	case "PTR":
+		return dns.TypePTR, true
 	case "ANY":
-		return dns.TypeANY
+		return dns.TypeANY, true
 	default:
-		return dns.TypeA
+		return dns.TypeA, false
 	}
 }
 
@@ -51,6 +63,61 @@ type dnsQuery struct {
 	server    string
 	qtype     uint16
 	edns0Size uint16
+
+	// noRecursion clears the RD (Recursion Desired) bit on the query.
+	// The zero value keeps the historical behavior of requesting recursion.
+	noRecursion bool
+
+	// maxResponseSize rejects responses whose wire length exceeds it.
+	// Zero means unlimited.
+	maxResponseSize int
+
+	// ednsOptions are applied in order to the query's OPT record after it
+	// has been built. See [WithEDNS0Options].
+	ednsOptions []func(*dns.OPT)
+
+	// stats, when non-nil, counts the truncated answers retried over TCP.
+	stats *checkerStats
+}
+
+// withDefaultPort returns server with the default port of client's
+// transport appended when it has none: 853 for DNS over TLS, 53 otherwise.
+func withDefaultPort(server string, client *dns.Client) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	// If it's an IPv6 address already enclosed in brackets but without a port, strip brackets first
+	// so JoinHostPort can correctly re-add them along with the port.
+	server = strings.TrimPrefix(server, "[")
+	server = strings.TrimSuffix(server, "]")
+	defaultPort := "53"
+	if client != nil && strings.HasSuffix(client.Net, "-tls") {
+		defaultPort = "853"
+	}
+	return net.JoinHostPort(server, defaultPort)
+}
+
+// exchange sends msg to addr over a new connection from client, like
+// [dns.Client.ExchangeContext] but aborted as soon as ctx is cancelled; see
+// [exchangeConn].
+func exchange(ctx context.Context, client *dns.Client, msg *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+	conn, err := client.DialContext(ctx, addr)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer conn.Close()
+	return exchangeConn(ctx, client, msg, conn)
+}
+
+// exchangeConn is [dns.Client.ExchangeWithConnContext] made cancellable.
+// The client only derives I/O deadlines from ctx's deadline, so a query
+// whose ctx is cancelled would otherwise keep waiting for the server until
+// its timeout; here cancellation expires conn's deadline instead, failing
+// the pending read or write at once.
+func exchangeConn(ctx context.Context, client *dns.Client, msg *dns.Msg, conn *dns.Conn) (*dns.Msg, time.Duration, error) {
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+	return client.ExchangeWithConnContext(ctx, msg, conn)
 }
 
 // queryDNS sends a DNS query for the given domain to the specified server.
@@ -62,25 +129,17 @@ type dnsQuery struct {
 // [RFC 6891]: https://datatracker.ietf.org/doc/html/rfc6891
 // [RFC 8914]: https://datatracker.ietf.org/doc/html/rfc8914
 func queryDNS(ctx context.Context, q dnsQuery) (*dns.Msg, error) {
-	msg := new(dns.Msg)
-	msg.SetQuestion(dns.Fqdn(q.domain), q.qtype)
-	msg.RecursionDesired = true
-	msg.SetEdns0(q.edns0Size, false)
-
-	// Ensure server has port.
-	server := q.server
-	if _, _, err := net.SplitHostPort(server); err != nil {
-		// If it's an IPv6 address already enclosed in brackets but without a port, strip brackets first
-		// so JoinHostPort can correctly re-add them along with the port.
-		server = strings.TrimPrefix(server, "[")
-		server = strings.TrimSuffix(server, "]")
-		defaultPort := "53"
-		if q.client != nil && q.client.Net == "tcp-tls" {
-			defaultPort = "853"
+	msg := acquireQueryMsg(q.domain, q.qtype, !q.noRecursion, q.edns0Size)
+	defer releaseQueryMsg(msg)
+	if len(q.ednsOptions) > 0 {
+		opt := msg.IsEdns0()
+		for _, fn := range q.ednsOptions {
+			fn(opt)
 		}
-		server = net.JoinHostPort(server, defaultPort)
 	}
 
+	server := withDefaultPort(q.server, q.client)
+
 	var (
 		resp *dns.Msg
 		err  error
@@ -88,12 +147,12 @@ func queryDNS(ctx context.Context, q dnsQuery) (*dns.Msg, error) {
 	if q.pool != nil {
 		resp, _, err = q.pool.exchange(ctx, msg)
 	} else {
-		resp, _, err = q.client.ExchangeContext(ctx, msg, server)
+		resp, _, err = exchange(ctx, q.client, msg, server)
 	}
 	if err != nil {
-		// 1. Did the context specifically exceed its deadline (timeout)?
-		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-			return nil, fmt.Errorf("%w: %v", ErrDNSTimeout, ctx.Err())
+		// 1. Did the context end (deadline or cancellation)?
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, contextError(ctxErr)
 		}
 
 		// 2. Did the underlying dns.Client hit a network timeout?
@@ -102,10 +161,43 @@ func queryDNS(ctx context.Context, q dnsQuery) (*dns.Msg, error) {
 			return nil, fmt.Errorf("%w: %v", ErrDNSTimeout, err)
 		}
 
-		// 3. For everything else (including context.Canceled), return the raw error
+		// 3. Did the transport reject a reply for another query?
+		if errors.Is(err, dns.ErrId) {
+			return nil, fmt.Errorf("%w: %v", ErrIDMismatch, err)
+		}
+
+		// 4. For everything else, return the raw error
 		return nil, err
 	}
 
+	// A truncated UDP answer is incomplete: retry over TCP (RFC 7766), and
+	// keep the truncated answer if the server cannot be reached that way.
+	if resp != nil && resp.Truncated && q.pool == nil && isUDP(q.client.Net) {
+		if q.stats != nil {
+			q.stats.truncationRetries.Add(1)
+		}
+		tcp := *q.client
+		tcp.Net = "tcp" + strings.TrimPrefix(q.client.Net, "udp")
+		if full, _, err := exchange(ctx, &tcp, msg, server); err == nil {
+			resp = full
+		}
+	}
+
+	// A foreign ID never gets this far: the transport drops it over UDP and
+	// reports dns.ErrId over TCP, handled above. The question is ours to
+	// check.
+	if resp != nil && !questionEchoed(msg, resp) {
+		got, sent := resp.Question[0], msg.Question[0]
+		return nil, fmt.Errorf("%w: got %s %s, sent %s %s", ErrQuestionMismatch,
+			got.Name, dns.TypeToString[got.Qtype], sent.Name, dns.TypeToString[sent.Qtype])
+	}
+
+	if resp != nil && q.maxResponseSize > 0 {
+		if n := resp.Len(); n > q.maxResponseSize {
+			return nil, fmt.Errorf("%w: %d bytes exceeds limit of %d", ErrResponseTooLarge, n, q.maxResponseSize)
+		}
+	}
+
 	if resp != nil {
 		// Robust error handling for DNS responses
 		switch resp.Rcode {
@@ -120,34 +212,336 @@ func queryDNS(ctx context.Context, q dnsQuery) (*dns.Msg, error) {
 	return resp, nil
 }
 
+// isUDP reports whether network, a [dns.Client] Net value, is UDP.
+func isUDP(network string) bool {
+	return network == "" || network == "udp" || network == "udp4" || network == "udp6"
+}
+
+// questionEchoed reports whether resp repeats the question of query, as a
+// genuine answer does (RFC 5452): same name (ignoring case), type and class.
+// A response without a question section, as some servers send with an
+// error rcode, is accepted.
+func questionEchoed(query, resp *dns.Msg) bool {
+	if len(resp.Question) == 0 {
+		return true
+	}
+	if len(resp.Question) != 1 || len(query.Question) != 1 {
+		return false
+	}
+	q, r := query.Question[0], resp.Question[0]
+	return strings.EqualFold(q.Name, r.Name) && q.Qtype == r.Qtype && q.Qclass == r.Qclass
+}
+
+// queryMsgPool recycles outgoing query messages across [queryDNS] calls.
+//
+// Only queries are pooled: a query never outlives the exchange that sends
+// it, whereas responses escape to the caller, the cache, and the query hook.
+var queryMsgPool = sync.Pool{
+	New: func() any { return new(dns.Msg) },
+}
+
+// acquireQueryMsg returns a pooled query message for domain and qtype with a
+// fresh ID, the RD bit set to rd, and an EDNS0 OPT record advertising
+// edns0Size. It is equivalent to [dns.Msg.SetQuestion] followed by
+// [dns.Msg.SetEdns0], but reuses the message, its question slice, and its
+// OPT record.
+func acquireQueryMsg(domain string, qtype uint16, rd bool, edns0Size uint16) *dns.Msg {
+	msg := queryMsgPool.Get().(*dns.Msg)
+
+	var opt *dns.OPT
+	if len(msg.Extra) > 0 {
+		opt, _ = msg.Extra[0].(*dns.OPT)
+	}
+	if opt == nil {
+		opt = new(dns.OPT)
+	}
+	*opt = dns.OPT{Hdr: dns.RR_Header{Name: ".", Rrtype: dns.TypeOPT}}
+	opt.SetUDPSize(edns0Size)
+
+	question, extra := msg.Question[:0], msg.Extra[:0]
+	*msg = dns.Msg{}
+
+	msg.Id = dns.Id()
+	msg.RecursionDesired = rd
+	msg.Question = append(question, dns.Question{
+		Name:   dns.Fqdn(domain),
+		Qtype:  qtype,
+		Qclass: dns.ClassINET,
+	})
+	msg.Extra = append(extra, opt)
+	return msg
+}
+
+// releaseQueryMsg returns msg to the pool. The caller must not use msg
+// afterwards.
+func releaseQueryMsg(msg *dns.Msg) {
+	queryMsgPool.Put(msg)
+}
+
+// isANYRefusal reports whether the outcome of an ANY query indicates the
+// server declined to answer it: either the query was rejected outright
+// (e.g. REFUSED or NOTIMP) or the server returned the minimal synthesized
+// HINFO "RFC8482" answer described in RFC 8482.
+func isANYRefusal(resp *dns.Msg, err error) bool {
+	if err != nil {
+		return errors.Is(err, ErrQueryRejected)
+	}
+	if resp == nil {
+		return false
+	}
+	for _, rr := range resp.Answer {
+		if hinfo, ok := rr.(*dns.HINFO); ok && strings.EqualFold(hinfo.Cpu, "RFC8482") {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeResponses returns a copy of a with the Answer, Ns, and Extra
+// records of b appended, so a single keyword scan covers both responses.
+// The OPT pseudo-record of b is skipped, since a message may carry only one.
+func mergeResponses(a, b *dns.Msg) *dns.Msg {
+	merged := a.Copy()
+	merged.Answer = append(merged.Answer, b.Answer...)
+	merged.Ns = append(merged.Ns, b.Ns...)
+	for _, rr := range b.Extra {
+		if _, ok := rr.(*dns.OPT); !ok {
+			merged.Extra = append(merged.Extra, rr)
+		}
+	}
+	merged.Authoritative = a.Authoritative && b.Authoritative
+	return merged
+}
+
 // containsKeyword scans all resource records in a DNS response message
 // for the presence of a keyword (case-insensitive). This mirrors the
 // parseDNSResponse function from the JavaScript implementation.
 //
-// It checks the Answer, Ns (authority), and Extra (additional) sections.
+// It checks the Answer, Ns (authority), and Extra (additional) sections
+// using the broad [MatchScopeRecord] scope.
 func containsKeyword(msg *dns.Msg, keyword string) bool {
+	return matchKeyword(msg, keyword, MatchScopeRecord)
+}
+
+// matchKeyword scans the Answer, Ns (authority), and Extra (additional)
+// sections of msg for keyword (case-insensitive) within the given scope.
+func matchKeyword(msg *dns.Msg, keyword, scope string) bool {
+	return matchKeywordSection(msg, keyword, scope, "", false) != ""
+}
+
+// matchKeywordSection is like [matchKeyword] but returns the name of the
+// first section containing keyword ([SectionAnswer], [SectionAuthority], or
+// [SectionAdditional]), or "" when there is no match.
+//
+// With [MatchScopeRecord] (or an empty scope) each record's full string
+// representation is searched. With [MatchScopeData] only the record data
+// returned by [rdataStrings] is searched, so keywords cannot hit the owner
+// name, TTL, class, or type in the record header.
+//
+// With [MatchModeLabel] the keyword must appear as whole DNS labels (see
+// [containsLabels]); otherwise (the default, [MatchModeSubstring]) any
+// substring matches.
+//
+// When joined is true, each record's multi-segment data (see
+// [joinedSegments]) is additionally searched as one concatenated string, so a
+// keyword split across segment boundaries still matches.
+func matchKeywordSection(msg *dns.Msg, keyword, scope, mode string, joined bool) string {
+	if sections := keywordSections(msg, keyword, scope, mode, joined, false); len(sections) > 0 {
+		return sections[0]
+	}
+	return ""
+}
+
+// keywordSections implements [matchKeywordSection]. It returns the names of
+// the sections containing keyword, in message order: only the first one, or
+// every one when all is true.
+func keywordSections(msg *dns.Msg, keyword, scope, mode string, joined, all bool) []string {
 	if msg == nil {
-		return false
+		return nil
 	}
 
 	keyword = strings.ToLower(keyword)
+	dataOnly := strings.EqualFold(scope, MatchScopeData)
+
+	match := func(s string) bool {
+		return containsLower(s, keyword)
+	}
+	if strings.EqualFold(mode, MatchModeLabel) {
+		labels := strings.Split(strings.Trim(keyword, "."), ".")
+		match = func(s string) bool {
+			return containsLabels(strings.ToLower(s), labels)
+		}
+	}
+
+	matchRR := func(rr dns.RR) bool {
+		if joined {
+			if data, ok := joinedSegments(rr); ok && match(data) {
+				return true
+			}
+		}
+
+		if dataOnly {
+			return slices.ContainsFunc(rdataStrings(rr), match)
+		}
+
+		// Convert the entire record to its string representation
+		// and check for the keyword. This is a broad match that
+		// covers all record types (TXT data, CNAME targets, etc.).
+		return match(rr.String())
+	}
 
 	// Check all sections: Answer, Authority (Ns), Additional (Extra).
-	sections := [][]dns.RR{msg.Answer, msg.Ns, msg.Extra}
+	sections := []struct {
+		name string
+		rrs  []dns.RR
+	}{
+		{SectionAnswer, msg.Answer},
+		{SectionAuthority, msg.Ns},
+		{SectionAdditional, msg.Extra},
+	}
+	var found []string
 	for _, section := range sections {
-		for _, rr := range section {
-			// Convert the entire record to its string representation
-			// and check for the keyword. This is a broad match that
-			// covers all record types (TXT data, CNAME targets, etc.).
-			if strings.Contains(strings.ToLower(rr.String()), keyword) {
-				return true
+		if slices.ContainsFunc(section.rrs, matchRR) {
+			found = append(found, section.name)
+			if !all {
+				break
 			}
 		}
 	}
+	return found
+}
+
+// rdataStrings returns the data portion of rr, without the owner name, TTL,
+// class, and type of the record header. Well-known types are extracted
+// field by field (CNAME target, A address, TXT strings, OPT options, ...);
+// any other type falls back to its presentation format minus the header.
+func rdataStrings(rr dns.RR) []string {
+	switch v := rr.(type) {
+	case *dns.TXT:
+		return v.Txt
+	case *dns.CNAME:
+		return []string{v.Target}
+	case *dns.DNAME:
+		return []string{v.Target}
+	case *dns.A:
+		return []string{v.A.String()}
+	case *dns.AAAA:
+		return []string{v.AAAA.String()}
+	case *dns.NS:
+		return []string{v.Ns}
+	case *dns.PTR:
+		return []string{v.Ptr}
+	case *dns.MX:
+		return []string{v.Mx}
+	case *dns.SRV:
+		return []string{v.Target}
+	case *dns.SOA:
+		return []string{v.Ns, v.Mbox}
+	case *dns.OPT:
+		data := make([]string, 0, len(v.Option))
+		for _, o := range v.Option {
+			data = append(data, o.String())
+		}
+		return data
+	default:
+		return []string{strings.TrimSpace(strings.TrimPrefix(rr.String(), rr.Header().String()))}
+	}
+}
+
+// minAnswerTTL returns the smallest TTL among the records in the Answer
+// section of msg, or 0 when there are none.
+func minAnswerTTL(msg *dns.Msg) uint32 {
+	if msg == nil || len(msg.Answer) == 0 {
+		return 0
+	}
+
+	ttl := msg.Answer[0].Header().Ttl
+	for _, rr := range msg.Answer[1:] {
+		ttl = min(ttl, rr.Header().Ttl)
+	}
+	return ttl
+}
+
+// containsLower reports whether the lower-case form of s contains keyword,
+// which must already be lower case. It is strings.Contains(strings.ToLower(s),
+// keyword) without the copy for ASCII text, such as the presentation format
+// of most records, since every response is matched against the keyword.
+func containsLower(s, keyword string) bool {
+	for i := range len(s) {
+		if s[i] >= utf8.RuneSelf {
+			return strings.Contains(strings.ToLower(s), keyword)
+		}
+	}
+	for i := 0; i+len(keyword) <= len(s); i++ {
+		if asciiHasLowerPrefix(s[i:], keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// asciiHasLowerPrefix reports whether the ASCII string s, lower-cased,
+// begins with prefix.
+func asciiHasLowerPrefix(s, prefix string) bool {
+	for j := range len(prefix) {
+		b := s[j]
+		if 'A' <= b && b <= 'Z' {
+			b += 'a' - 'A'
+		}
+		if b != prefix[j] {
+			return false
+		}
+	}
+	return true
+}
 
+// containsLabels reports whether text holds a name whose labels include
+// want as a contiguous run, e.g. want ["internetpositif"] or
+// ["internetpositif", "id"] in "lb.internetpositif.id." but not in
+// "internetpositifity.com". Names are the runs of label characters (letters,
+// digits, hyphens, and underscores) and dots in text, so names embedded in
+// URLs or EDE texts are found too. Both are expected in lower case.
+func containsLabels(text string, want []string) bool {
+	names := strings.FieldsFunc(text, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.')
+	})
+	for _, name := range names {
+		labels := strings.Split(strings.Trim(name, "."), ".")
+		for i := 0; i+len(want) <= len(labels); i++ {
+			if slices.Equal(labels[i:i+len(want)], want) {
+				return true
+			}
+		}
+	}
 	return false
 }
 
+// joinedSegments concatenates the segments of records whose data is split
+// into several pieces: the character-strings of a TXT record, and the
+// options of an OPT record, using the EXTRA-TEXT of Extended DNS Errors
+// rather than their presentation form. It reports false for records with a
+// single segment, which [matchKeywordSection] already covers.
+func joinedSegments(rr dns.RR) (string, bool) {
+	var segments []string
+	switch v := rr.(type) {
+	case *dns.TXT:
+		segments = v.Txt
+	case *dns.OPT:
+		segments = make([]string, 0, len(v.Option))
+		for _, o := range v.Option {
+			if ede, ok := o.(*dns.EDNS0_EDE); ok {
+				segments = append(segments, ede.ExtraText)
+				continue
+			}
+			segments = append(segments, o.String())
+		}
+	}
+	if len(segments) < 2 {
+		return "", false
+	}
+	return strings.Join(segments, ""), true
+}
+
 // queryFunc is the function used by checkDNSHealth to perform DNS queries.
 // It defaults to [queryDNS] and exists solely as a test seam so that edge
 // cases unreachable through the real [queryDNS] (such as a nil response
@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -110,6 +111,219 @@ func TestContainsKeyword(t *testing.T) {
 	})
 }
 
+func TestMatchKeywordTXTDataScope(t *testing.T) {
+	msg := new(dns.Msg)
+	msg.Answer = []dns.RR{
+		&dns.TXT{
+			Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 60},
+			Txt: []string{"v=spf1 -all", "status=clean"},
+		},
+	}
+
+	// The record header contains "com" (owner name) and "IN"/"TXT";
+	// the broad record scope matches those, the data scope does not.
+	assert.True(t, matchKeyword(msg, "com", MatchScopeRecord))
+	assert.True(t, matchKeyword(msg, "com", ""))
+	assert.False(t, matchKeyword(msg, "com", MatchScopeData))
+	assert.False(t, matchKeyword(msg, "txt", MatchScopeData))
+
+	// Keywords inside the TXT data still match in data scope.
+	assert.True(t, matchKeyword(msg, "STATUS=CLEAN", MatchScopeData))
+	assert.True(t, matchKeyword(msg, "spf1", "DATA"))
+
+	assert.False(t, matchKeyword(nil, "internetpositif", MatchScopeData))
+}
+
+func TestMatchKeywordStrictDataScope(t *testing.T) {
+	msg := new(dns.Msg)
+	msg.Answer = []dns.RR{
+		&dns.CNAME{
+			Hdr:    dns.RR_Header{Name: "positif.example.com.", Rrtype: dns.TypeCNAME, Class: dns.ClassINET},
+			Target: "cdn.example.net.",
+		},
+		&dns.A{
+			Hdr: dns.RR_Header{Name: "cdn.example.net.", Rrtype: dns.TypeA, Class: dns.ClassINET},
+			A:   net.ParseIP("192.0.2.1"),
+		},
+	}
+	msg.Ns = []dns.RR{
+		&dns.NS{
+			Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeNS, Class: dns.ClassINET},
+			Ns:  "a.iana-servers.net.",
+		},
+	}
+
+	// Keyword equal to the owner name only matches in record scope.
+	assert.True(t, matchKeyword(msg, "positif.example.com", MatchScopeRecord))
+	assert.False(t, matchKeyword(msg, "positif.example.com", MatchScopeData))
+
+	// "ns" matches the NS header in record scope but not the data.
+	assert.True(t, matchKeyword(msg, "\tns\t", MatchScopeRecord))
+	assert.False(t, matchKeyword(msg, "\tns\t", MatchScopeData))
+
+	// Data matches: CNAME target, A address, NS target.
+	assert.True(t, matchKeyword(msg, "cdn.example.net", MatchScopeData))
+	assert.True(t, matchKeyword(msg, "192.0.2.1", MatchScopeData))
+	assert.True(t, matchKeyword(msg, "iana-servers", MatchScopeData))
+
+	// EDE text in the OPT record is data.
+	msg.SetEdns0(1232, false)
+	opt := msg.IsEdns0()
+	opt.Option = append(opt.Option, &dns.EDNS0_EDE{InfoCode: dns.ExtendedErrorCodeBlocked, ExtraText: "trustpositif.komdigi.go.id"})
+	assert.True(t, matchKeyword(msg, "trustpositif", MatchScopeData))
+}
+
+func TestMatchKeywordJoinedSegments(t *testing.T) {
+	msg := new(dns.Msg)
+	msg.Answer = []dns.RR{
+		&dns.TXT{
+			Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 60},
+			Txt: []string{"redirect=internet", "positif.id"},
+		},
+	}
+
+	// Split across TXT character-strings: only the joined match finds it.
+	for _, scope := range []string{MatchScopeRecord, MatchScopeData} {
+		assert.Empty(t, matchKeywordSection(msg, "internetpositif", scope, "", false), scope)
+		assert.Equal(t, SectionAnswer, matchKeywordSection(msg, "internetpositif", scope, "", true), scope)
+	}
+
+	// EDE text split across two OPT options.
+	ede := new(dns.Msg)
+	ede.SetEdns0(1232, false)
+	opt := ede.IsEdns0()
+	opt.Option = append(opt.Option,
+		&dns.EDNS0_EDE{InfoCode: dns.ExtendedErrorCodeBlocked, ExtraText: "trustpos"},
+		&dns.EDNS0_EDE{InfoCode: dns.ExtendedErrorCodeBlocked, ExtraText: "itif.komdigi.go.id"},
+	)
+	assert.False(t, matchKeyword(ede, "trustpositif", MatchScopeRecord))
+	assert.Equal(t, SectionAdditional, matchKeywordSection(ede, "TrustPositif", MatchScopeRecord, "", true))
+	assert.Equal(t, SectionAdditional, matchKeywordSection(ede, "trustpositif", MatchScopeData, "", true))
+
+	// Joining never loses a per-segment match.
+	assert.Equal(t, SectionAdditional, matchKeywordSection(ede, "komdigi", MatchScopeData, "", true))
+
+	// Single-segment records are not joined.
+	_, ok := joinedSegments(&dns.TXT{Txt: []string{"only"}})
+	assert.False(t, ok)
+	_, ok = joinedSegments(&dns.A{A: net.ParseIP("192.0.2.1")})
+	assert.False(t, ok)
+}
+
+func TestMatchKeywordWholeLabel(t *testing.T) {
+	cname := func(target string) *dns.Msg {
+		msg := new(dns.Msg)
+		msg.Answer = []dns.RR{&dns.CNAME{
+			Hdr:    dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 60},
+			Target: target,
+		}}
+		return msg
+	}
+
+	blocked := cname("internetpositif.id.")
+	assert.Equal(t, SectionAnswer, matchKeywordSection(blocked, "internetpositif", MatchScopeData, MatchModeLabel, false))
+	assert.Equal(t, SectionAnswer, matchKeywordSection(blocked, "InternetPositif.ID", MatchScopeData, "LABEL", false))
+	assert.Empty(t, matchKeywordSection(blocked, "positif", MatchScopeData, MatchModeLabel, false))
+	assert.Equal(t, SectionAnswer, matchKeywordSection(blocked, "positif", MatchScopeData, MatchModeSubstring, false))
+
+	unrelated := cname("positifity.com.")
+	assert.Empty(t, matchKeywordSection(unrelated, "positif", MatchScopeRecord, MatchModeLabel, false))
+	assert.Equal(t, SectionAnswer, matchKeywordSection(unrelated, "positif", MatchScopeRecord, "", false))
+
+	// Names inside EDE texts and URLs are split into labels as well.
+	ede := new(dns.Msg)
+	ede.SetEdns0(1232, false)
+	opt := ede.IsEdns0()
+	opt.Option = append(opt.Option, &dns.EDNS0_EDE{
+		InfoCode:  dns.ExtendedErrorCodeBlocked,
+		ExtraText: "blockListUrl=https://trustpositif.komdigi.go.id/assets/db/domains_isp",
+	})
+	assert.Equal(t, SectionAdditional, matchKeywordSection(ede, "trustpositif", MatchScopeData, MatchModeLabel, false))
+	assert.Equal(t, SectionAdditional, matchKeywordSection(ede, "komdigi.go.id", MatchScopeData, MatchModeLabel, false))
+	assert.Empty(t, matchKeywordSection(ede, "trust", MatchScopeData, MatchModeLabel, false))
+}
+
+func TestMinAnswerTTL(t *testing.T) {
+	hdr := func(ttl uint32) dns.RR_Header {
+		return dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl}
+	}
+
+	msg := new(dns.Msg)
+	assert.Equal(t, uint32(0), minAnswerTTL(msg))
+	assert.Equal(t, uint32(0), minAnswerTTL(nil))
+
+	msg.Answer = []dns.RR{
+		&dns.A{Hdr: hdr(300), A: net.ParseIP("192.0.2.1")},
+		&dns.A{Hdr: hdr(60), A: net.ParseIP("192.0.2.2")},
+		&dns.A{Hdr: hdr(3600), A: net.ParseIP("192.0.2.3")},
+	}
+	assert.Equal(t, uint32(60), minAnswerTTL(msg))
+}
+
+func TestContainsLabels(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+		ok   bool
+	}{
+		{"lb.internetpositif.id.", []string{"internetpositif"}, true},
+		{"lb.internetpositif.id.", []string{"internetpositif", "id"}, true},
+		{"lb.internetpositif.id.", []string{"lb", "id"}, false},
+		{"internetpositifity.com", []string{"internetpositif"}, false},
+		{"example.com. 60 IN CNAME internetpositif.id.", []string{"internetpositif"}, true},
+		{"", []string{"internetpositif"}, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.ok, containsLabels(tt.text, tt.want), "%q in %q", tt.want, tt.text)
+	}
+}
+
+func TestContainsLower(t *testing.T) {
+	tests := []struct {
+		s, keyword string
+	}{
+		{"example.com. 60 IN CNAME InternetPositif.ID.", "internetpositif"},
+		{"example.com. 60 IN A 93.184.216.34", "internetpositif"},
+		{"INTERNETPOSITIF", "internetpositif"},
+		{"internetpositi", "internetpositif"},
+		{"", "internetpositif"},
+		{"anything", ""},
+		{"", ""},
+		{"Blokir ÉTAT internetpositif", "internetpositif"},
+		{"Blokir ÉTAT", "état"},
+		{"Blokir État", "etat"},
+	}
+	for _, tt := range tests {
+		want := strings.Contains(strings.ToLower(tt.s), tt.keyword)
+		assert.Equal(t, want, containsLower(tt.s, tt.keyword), "%q in %q", tt.keyword, tt.s)
+	}
+}
+
+func TestRdataStrings(t *testing.T) {
+	hdr := func(t uint16) dns.RR_Header {
+		return dns.RR_Header{Name: "owner.example.", Rrtype: t, Class: dns.ClassINET, Ttl: 60}
+	}
+
+	tests := []struct {
+		rr   dns.RR
+		want []string
+	}{
+		{&dns.TXT{Hdr: hdr(dns.TypeTXT), Txt: []string{"a", "b"}}, []string{"a", "b"}},
+		{&dns.CNAME{Hdr: hdr(dns.TypeCNAME), Target: "t."}, []string{"t."}},
+		{&dns.DNAME{Hdr: hdr(dns.TypeDNAME), Target: "d."}, []string{"d."}},
+		{&dns.AAAA{Hdr: hdr(dns.TypeAAAA), AAAA: net.ParseIP("2001:db8::1")}, []string{"2001:db8::1"}},
+		{&dns.PTR{Hdr: hdr(dns.TypePTR), Ptr: "p."}, []string{"p."}},
+		{&dns.MX{Hdr: hdr(dns.TypeMX), Mx: "mx."}, []string{"mx."}},
+		{&dns.SRV{Hdr: hdr(dns.TypeSRV), Target: "srv."}, []string{"srv."}},
+		{&dns.SOA{Hdr: hdr(dns.TypeSOA), Ns: "ns.", Mbox: "mbox."}, []string{"ns.", "mbox."}},
+		{&dns.HINFO{Hdr: hdr(dns.TypeHINFO), Cpu: "RFC8482", Os: ""}, []string{`"RFC8482" ""`}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, rdataStrings(tt.rr), "%T", tt.rr)
+	}
+}
+
 // startTestDNSServer starts a local DNS server that responds with configurable answers.
 // It returns the server address (ip:port) and a cleanup function.
 func startTestDNSServer(t *testing.T, handler dns.HandlerFunc) (string, func()) {
@@ -428,6 +642,46 @@ func TestQueryDNS_NotImplemented(t *testing.T) {
 	assert.ErrorIs(t, err, ErrQueryRejected)
 }
 
+func TestQueryDNS_MaxResponseSize(t *testing.T) {
+	// Respond with many TXT strings so the message is comfortably over 512 bytes.
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		for range 10 {
+			m.Answer = append(m.Answer, &dns.TXT{
+				Hdr: dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 60},
+				Txt: []string{strings.Repeat("x", 100)},
+			})
+		}
+		_ = w.WriteMsg(m)
+	})
+
+	addr, cleanup := startTestDNSServer(t, handler)
+	defer cleanup()
+
+	client := &dns.Client{Timeout: 2 * time.Second, Net: "udp"}
+	q := dnsQuery{
+		client:    client,
+		domain:    "example.com",
+		server:    addr,
+		qtype:     dns.TypeTXT,
+		edns0Size: 4096,
+	}
+
+	// Unlimited by default.
+	resp, err := queryDNS(context.Background(), q)
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+
+	q.maxResponseSize = 512
+	_, err = queryDNS(context.Background(), q)
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+
+	q.maxResponseSize = 64 << 10
+	_, err = queryDNS(context.Background(), q)
+	assert.NoError(t, err)
+}
+
 func TestQueryDNS_IPv6BracketedAddress(t *testing.T) {
 	// Covers the bracket-stripping path in queryDNS for IPv6 addresses.
 	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
@@ -499,3 +753,80 @@ func TestCheckDNSHealth_NilResponse(t *testing.T) {
 	assert.Error(t, status.Error)
 	assert.Contains(t, status.Error.Error(), "nil response from server")
 }
+
+func TestAcquireQueryMsg(t *testing.T) {
+	want := new(dns.Msg)
+	want.SetQuestion("example.com.", dns.TypeTXT)
+	want.RecursionDesired = false
+	want.SetEdns0(1232, false)
+
+	for range 3 { // exercise reuse of pooled messages
+		got := acquireQueryMsg("example.com", dns.TypeTXT, false, 1232)
+		got.Id = want.Id
+		assert.Equal(t, want.String(), got.String())
+		require.Len(t, got.Question, 1)
+		require.Len(t, got.Extra, 1)
+		releaseQueryMsg(got)
+	}
+}
+
+// BenchmarkQueryMsg compares building a query message from scratch, as
+// queryDNS used to, with acquiring it from the pool.
+func BenchmarkQueryMsg(b *testing.B) {
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			msg := new(dns.Msg)
+			msg.SetQuestion("example.com.", dns.TypeA)
+			msg.SetEdns0(defaultEDNS0Size, false)
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			msg := acquireQueryMsg("example.com.", dns.TypeA, true, defaultEDNS0Size)
+			releaseQueryMsg(msg)
+		}
+	})
+}
+
+// BenchmarkContainsKeyword measures keyword matching against a Nawala-style
+// CNAME redirect and a clean response with several records.
+func BenchmarkContainsKeyword(b *testing.B) {
+	blocked := new(dns.Msg)
+	blocked.SetQuestion("example.com.", dns.TypeA)
+	blocked.Answer = []dns.RR{
+		&dns.CNAME{
+			Hdr:    dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 300},
+			Target: "internetpositif.id.",
+		},
+	}
+
+	clean := new(dns.Msg)
+	clean.SetQuestion("example.com.", dns.TypeA)
+	for i := range 4 {
+		clean.Answer = append(clean.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 300},
+			A:   net.IPv4(93, 184, 216, byte(34+i)),
+		})
+	}
+
+	for _, bc := range []struct {
+		name string
+		msg  *dns.Msg
+		want bool
+	}{
+		{"blocked", blocked, true},
+		{"clean", clean, false},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if containsKeyword(bc.msg, "internetpositif") != bc.want {
+					b.Fatal("unexpected match result")
+				}
+			}
+		})
+	}
+}
@@ -24,6 +24,16 @@
 // DNS server in use; the default Nawala/Komdigi servers only return block
 // indicators when queried from an Indonesian source IP.
 //
+// On multi-homed hosts, [DNSServer.Dialer] routes each server over its own
+// path, for example one through a VPN interface and another directly:
+//
+//	nawala.DNSServer{
+//	    Address:   "180.131.144.144",
+//	    Keyword:   "internetpositif",
+//	    QueryType: "A",
+//	    Dialer:    &net.Dialer{LocalAddr: &net.UDPAddr{IP: net.ParseIP("10.8.0.2")}},
+//	}
+//
 // # General-Purpose Design
 //
 // Despite rumors that the original Nawala project may cease operations,
@@ -137,6 +147,9 @@
 //	    // Or simply switch protocol without replacing the client.
 //	    nawala.WithProtocol("tcp-tls"),
 //
+//	    // Or switch to DoT and set the TLS server name in one step.
+//	    nawala.WithDoT("dns.example.com", false),
+//
 //	    // Set custom EDNS0 size (default is 1232 to prevent fragmentation).
 //	    nawala.WithEDNS0Size(4096),
 //
@@ -152,31 +165,98 @@
 // Available options:
 //
 //   - [WithTimeout]           — Timeout per DNS query (default: 5s)
+//   - [WithPerDomainTimeout]  — Total time budget per domain across retries and failover (default: unset)
 //   - [WithMaxRetries]        — Max retry attempts per query, total = n+1 (default: 2)
+//   - [WithParallelProbes]    — Fire the n+1 probes concurrently; first block wins (default: false)
+//   - [WithProbeDelay]        — Pause between successful sequential probes to one server (default: 0)
+//   - [WithSystemResolverFallback] — Fall back to the system resolver, with IP-only detection, when every server fails (default: false)
+//   - [WithMaxTotalAttempts]  — Cap on DNS queries per check across all servers (default: unlimited)
+//   - [WithRaceServers]       — Query every server at once; a block wins, otherwise the fastest answer (default: false)
+//   - [WithFailoverPredicate] — Decide per error whether to try the next server (default: all but context errors)
+//   - [WithCircuitBreaker]    — Skip servers whose recent error rate exceeds a threshold for a cooldown (default: disabled)
+//   - [WithServerCooldown]    — Skip a server for a cooldown after n consecutive failures (default: disabled)
+//   - [WithFailOpen]          — Report "not blocked" with Result.Degraded instead of ErrAllDNSFailed
+//     when every server fails; see the option's security note (default: false)
+//   - [WithStrictMatch]       — Match keywords against record data only, not the full record (default: false)
+//   - [WithMatchMode]         — Match keywords as substrings or whole DNS labels (default: substring)
+//   - [WithTruncatedKeywordSafety] — Also match keywords across TXT strings and EDE options (default: false)
+//   - [WithMaxCNAMEDepth]     — Re-query unresolved CNAME targets up to n hops to find the keyword (default: 0, off)
+//   - [WithRequireAnswer]     — Treat NOERROR responses without answers (NODATA) as failures (default: false)
+//   - [WithAllMatches]        — List every block indicator in [Result.Signals], not just the first (default: false)
+//   - [WithSinkholeDetection] — Flag public domains resolving only to private/reserved IPs as blocked
+//     with [BlockReasonSinkhole] (default: false)
+//   - [WithQueryHook]         — Callback after every DNS query; receives the caller's context
+//   - [WithServerChangeHook]  — Callback with the old and new server lists after every runtime change
+//     so request IDs stored in it can be logged for correlation
+//   - [WithHTTPConfirmation]  — Confirm blocked verdicts by fetching the block page over HTTP and HTTPS
 //   - [WithCacheTTL]          — TTL for the built-in in-memory cache (default: 5m)
+//   - [WithCacheShards]       — Lock shards of the built-in cache (default: 16)
+//   - [WithClock]             — Time source for cache expiry and retry backoff, for tests (default: real time)
+//   - [WithStaleWhileRevalidate] — Serve expired cache entries as Result.Stale until a hard TTL
+//     while refreshing them in the background (default: disabled)
+//   - [WithCacheMinTTL]       — Cache entries by response TTL, raised to at least d (default: unset)
+//   - [WithCacheMaxTTL]       — Cache entries by response TTL, lowered to at most d (default: unset)
 //   - [WithCache]             — Custom Cache implementation; pass nil to disable
+//   - [WithCacheCompression]  — Gzip values in serializing cache backends; no-op for
+//     the built-in cache (default: backend's own)
 //   - [WithDigests]           — Digest-based cache keys via a custom hash function;
 //     key format: "nawala_checker:<digest>" (e.g. hex SHA-256); pass nil to disable
 //   - [WithConcurrency]       — Max concurrent DNS checks, semaphore size (default: 100)
+//   - [WithMaxDomains]        — Reject Check calls with more than n domains (default: unlimited)
 //   - [WithEDNS0Size]         — EDNS0 UDP buffer size, prevents fragmentation (default: 1232)
+//   - [WithEDNS0Options]      — Mutate the OPT record of every query (DO bit, ECS, cookies, padding)
+//   - [WithDNSCookie]         — Send an RFC 7873 client cookie and verify server cookies (default: false)
+//   - [WithNormalizer]        — Custom domain normalizer run before validation (default: lowercase + trim)
+//   - [WithDomainLimits]      — Max name and label length for validation (default: 255, 63)
+//   - [WithAllowSingleLabel]  — Accept single-label hostnames like "localhost" (default: false)
+//   - [WithAllowUnderscoreTLD] — Accept underscores in the TLD for internal zones (default: false)
+//   - [WithRecursionDesired]  — RD bit on outgoing queries; false for authoritative servers (default: true)
+//   - [WithMaxResponseSize]   — Reject DNS responses larger than n bytes (default: unlimited)
+//   - [WithResponseValidator] — Custom block verdict per response, run before keyword matching
+//   - [WithEnricher]          — Attach external data (RDAP, GeoIP, ...) to each verdict in Result.Metadata
+//   - [WithANYFallback]       — Retry ANY queries refused per RFC 8482 as A+AAAA (default: true)
+//   - [WithRemoteServerConfig] — Periodically refresh servers from a remote JSON document
+//   - [WithRemoteConfigErrorHandler] — Callback for failed [WithRemoteServerConfig] refreshes
 //   - [WithProtocol]          — DNS transport: "udp" (default), "tcp", or "tcp-tls" (DoT)
+//   - [WithNetworkPreference] — Pin the IP family: [NetworkAuto] (default), [PreferIPv4], or [PreferIPv6]
 //   - [WithTLSServerName]     — SNI server name for tcp-tls; required when the server address is
 //     an IP and the cert is issued for a hostname (works with trusted CA certs; set
 //     tls_skip_verify: false for full verification)
 //   - [WithTLSSkipVerify]     — Disable TLS cert verification for tcp-tls (only for self-signed
 //     certs where no valid server name can be provided; never use in production)
+//   - [WithDoT]               — DoT in one step: tcp-tls with the given server name, port 853
+//   - [WithDoTPin]            — Pin the DoT server certificate by its SHA-256 fingerprint
 //   - [WithDNSClient]         — Custom client for full transport control (TCP, TLS, dialer)
+//   - [Checker.SetDNSClient]  — Hot-reload: Swap the DNS client at runtime (e.g. rotate to DoT)
 //   - [WithServer]            — (Deprecated: use [Checker.SetServers] for hot-reloading) Add or replace a single DNS server
-//   - [WithServers]           — Replace all DNS servers (default: Nawala servers)
+//   - [WithServers]           — Replace all DNS servers (default: [DefaultServers])
+//   - [WithDefaultKeyword]    — Fallback keyword for servers without one; per-server keyword wins
 //   - [Checker.SetServers]    — Hot-reload: Add or replace servers at runtime safely
+//   - [Checker.SetServersValidated] — Hot-reload: Like SetServers, but rejects malformed
+//     addresses and unknown query types with [ErrInvalidServer] before mutating state
+//   - [ParseServers]          — Read a server list from JSON or YAML, validating every entry
+//   - [WriteServers]          — Write a server list as JSON, readable by ParseServers
+//   - [NewFromEnv]            — Build a Checker from NAWALA_* environment variables (servers as
+//     comma-separated address|keyword|type entries or JSON), rejecting invalid values with [ErrInvalidEnv]
+//   - [Checker.RefreshServers] — Hot-reload: Fetch and apply the [WithRemoteServerConfig] document now
+//   - [Checker.ReplaceServers] — Hot-reload: Atomically swap the entire server set at runtime
+//   - [Checker.ResetServers]  — Hot-reload: Restore the default Nawala servers at runtime
 //   - [Checker.HasServer]     — Hot-reload: Check if a server is configured at runtime safely
+//   - [Checker.ServerReliability] — Success rate of a server's recent queries, and the sample count
+//   - [Checker.ServerInfos]   — Configured servers with when each was added, whether it is a default, and whether it is enabled
 //   - [Checker.DeleteServers] — Hot-reload: Remove servers at runtime safely
+//   - [Checker.DeleteServersFunc] — Hot-reload: Remove every server matching a predicate
 //   - [Checker.Concurrency]   — Returns the configured concurrency limit (semaphore size);
 //     useful for sizing output channel buffers to match in-flight capacity
 //   - [WithKeepAlive]         — Persistent TCP/TLS conn pool (idle conns per server);
 //     no-op for UDP; requires [RFC 7766] (tcp) or [RFC 7858] (tcp-tls) server support —
 //     use with DoT providers or modern custom resolvers, NOT the default Nawala
 //     ISP servers (UDP-optimised, close TCP after each query); call [Checker.Close] when done
+//   - [WithConnectionPool]    — Like WithKeepAlive, plus eviction of conns idle longer than a timeout
+//
+// Since [DNSServer.Tags] was added, [DNSServer] is no longer comparable.
+// Code that compared servers with == or used them as map keys must compare
+// the relevant fields instead, for example keying a map by Address.
 //
 // # API
 //
@@ -188,6 +268,44 @@
 //	// Check a single domain.
 //	result, err := c.CheckOne(ctx, "example.com")
 //
+//	// One-off check against a single server, without a long-lived Checker.
+//	result, err = nawala.Check(ctx, "180.131.144.144", "internetpositif", "example.com")
+//
+//	// Only the verdict, with per-domain errors folded into err.
+//	blocked, err := c.IsBlocked(ctx, "example.com")
+//
+//	// Check against one configured server only, without failover.
+//	result, err = c.CheckOneServer(ctx, "example.com", "180.131.144.144")
+//
+//	// Override the timeout, servers, or probe count for one call only.
+//	result, err = c.CheckOne(ctx, "example.com", nawala.CallProbes(1), nawala.CallTimeout(time.Second))
+//
+//	// Check only against servers tagged "komdigi" (see DNSServer.Tags).
+//	results, err := c.CheckWithTags(ctx, []string{"komdigi"}, "example.com")
+//
+//	// Check every domain against every server, without failover,
+//	// to compare how the resolvers diverge (map keyed by server address).
+//	byServer := c.CheckByServer(ctx, "example.com", "another.com")
+//
+//	// Check subdomains of a zone in one batch ("@" is the apex); the names
+//	// can also come from a list or zone transfer dump via ReadSubdomains.
+//	results, err := c.CheckSubdomains(ctx, "example.com", []string{"www", "mail", "@"})
+//
+//	// Check A and AAAA together; Blocked if either family is blocked.
+//	result, err := c.CheckDualStack(ctx, "example.com")
+//
+//	// Check an IP for reverse-DNS (PTR) blocking.
+//	result, err := c.CheckIP(ctx, net.ParseIP("192.0.2.1"))
+//
+//	// Explain how a verdict was reached, server by server.
+//	e, err := c.Explain(ctx, "example.com")
+//	fmt.Println(e.Decision)
+//
+//	// Watch a domain and receive a Result whenever its status changes.
+//	for r := range c.WatchDomain(ctx, "example.com", time.Minute) {
+//	    fmt.Println(r.Domain, r.Blocked, r.Error)
+//	}
+//
 //	// Stream-check domains through a channel pipeline.
 //	// Domains are read from In and results are sent to Out as they complete.
 //	// Memory usage stays constant regardless of input size.
@@ -202,20 +320,51 @@
 //	// Read the configured concurrency (semaphore size).
 //	n := c.Concurrency()
 //
+//	// Read back other settings, e.g. for a /config endpoint.
+//	fmt.Println(c.Timeout(), c.PerDomainTimeout(), c.MaxRetries(), c.CacheTTL())
+//
+//	// Read lifetime failover and retry counters for metrics and alerting.
+//	s := c.Stats()
+//	fmt.Println(s.FailoverCount, s.RetryCount)
+//
 //	// Check DNS server health and latency.
 //	statuses, err := c.DNSStatus(ctx)
 //
+//	// Check a single server, configured or not.
+//	status := c.Ping(ctx, nawala.DNSServer{Address: "203.0.113.1"})
+//
 //	// Clear the result cache.
 //	c.FlushCache()
 //
+//	// Clear it with a deadline, for network cache backends.
+//	err = c.FlushContext(ctx)
+//
+//	// Inspect the number of cached entries (false if unsupported/disabled).
+//	n, ok := c.CacheLen()
+//
 //	// Get configured servers.
 //	servers := c.Servers()
 //
-//	// Hot-reload: Add or replace servers at runtime (concurrency-safe).
-//	c.SetServers(nawala.DNSServer{
+//	// Audit when each server was added, whether it is a built-in default,
+//	// and whether it is enabled (not skipped after failures).
+//	for _, info := range c.ServerInfos() {
+//	    fmt.Println(info.Address, info.AddedAt.Format(time.RFC3339), info.Default, info.Enabled)
+//	}
+//
+//	// Hot-reload: Add or replace servers at runtime (concurrency-safe),
+//	// after making sure the new server answers.
+//	srv := nawala.DNSServer{
 //	    Address:   "203.0.113.1",
 //	    Keyword:   "blocked",
 //	    QueryType: "A",
+//	}
+//	if c.Ping(ctx, srv).Online {
+//	    c.SetServers(srv)
+//	}
+//
+//	// Hot-reload: Atomically replace the whole server set (e.g. on config reload).
+//	c.ReplaceServers([]nawala.DNSServer{
+//	    {Address: "203.0.113.2", Keyword: "blocked", QueryType: "A"},
 //	})
 //
 //	// Check if a server is currently configured.
@@ -224,13 +373,36 @@
 //	}
 //
 //	// Hot-reload: Remove servers at runtime by address (concurrency-safe).
-//	// The address must match exactly the value used in [DNSServer.Address]
-//	// (the same format: plain IP, IP:port, hostname, or hostname:port).
+//	// Addresses are normalized before matching, so "203.0.113.1:53" and
+//	// " 203.0.113.1" name the same server as "203.0.113.1".
 //	c.DeleteServers("203.0.113.1")
 //
 //	// Release idle keep-alive connections (call when checker is no longer needed).
 //	defer c.Close()
 //
+//	// Or, in a service, refuse new checks and let in-flight ones finish first.
+//	err = c.Shutdown(ctx)
+//
+// Sort results for display (the slice returned by Check is positional):
+//
+//	nawala.SortResults(results, nawala.SortByBlocked) // blocked first, errors last
+//	nawala.SortResults(results, nawala.SortByDomain)
+//	nawala.SortResults(results, nawala.SortByLatency)
+//
+// Summarize a run, counting blocked results by how they were blocked:
+//
+//	s := nawala.Summarize(results)
+//	fmt.Println(s.ByStatus[nawala.StatusBlocked], s.ByReason[nawala.BlockReasonRedirect])
+//
+// Compare two runs to find blocking changes (error results are ignored):
+//
+//	newlyBlocked, newlyUnblocked := nawala.Diff(previous, results)
+//
+// Expand wildcard block-list entries into the apex and a probe subdomain:
+//
+//	domains := nawala.ExpandWildcard("*.gambling.example")
+//	// ["gambling.example", "nawala-wildcard-probe.gambling.example"]
+//
 // Domain validation:
 //
 //	ok := nawala.IsValidDomain("example.com") // true
@@ -244,12 +416,57 @@
 //	    ErrNoDNSServers  // No DNS servers configured
 //	    ErrAllDNSFailed  // All DNS servers failed to respond
 //	    ErrInvalidDomain // Domain name failed validation
-//	    ErrDNSTimeout    // DNS query exceeded the configured timeout
+//	    ErrDNSTimeout    // DNS query exceeded the configured timeout or the context deadline
+//	    ErrCanceled      // The caller's context was cancelled
 //	    ErrInternalPanic // An internal panic was recovered during execution
 //	    ErrNXDOMAIN      // Domain does not exist (NXDOMAIN)
 //	    ErrQueryRejected // Query explicitly rejected by server (Format Error, Refused, Not Implemented)
+//	    ErrServerFailure // DNS server answered SERVFAIL (retried and failed over)
+//	    ErrResponseTooLarge // DNS response exceeded the WithMaxResponseSize limit
+//	    ErrEDEDomainMismatch // EDE "domain=" field names neither the queried domain nor a parent
+//	    ErrIDMismatch // Response transaction ID did not match the query (TCP/DoT only; spoof suspect)
+//	    ErrQuestionMismatch // Response question did not match the query (spoof suspect)
+//	    ErrCertificatePinMismatch // DoT certificate did not match the WithDoTPin fingerprint
+//	    ErrTooManyDomains // Check was given more domains than the WithMaxDomains cap
+//	    ErrMalformedCacheValue // DecodeResult was given data it cannot decode
+//	    ErrInvalidServer // DNS server configuration failed validation
+//	    ErrInvalidEnv // NewFromEnv found a NAWALA_* variable it could not parse
+//	    ErrRemoteConfig // Remote server config could not be fetched or applied
+//	    ErrClosing // Check started after Shutdown was called
+//	    ErrNoAnswer // NOERROR response without records of the queried type (WithRequireAnswer)
+//	    ErrAttemptsExhausted // server skipped because the WithMaxTotalAttempts budget was spent
+//	    ErrServerNotFound // CheckOneServer was given an address that is not configured
 //	)
 //
+// When every server fails, [Result.Error] is a [*CheckError] that still
+// matches [ErrAllDNSFailed] via [errors.Is], while [errors.As] exposes the
+// domain, the last server tried, and the underlying cause:
+//
+//	var ce *nawala.CheckError
+//	if errors.As(r.Error, &ce) {
+//	    log.Printf("domain=%s server=%s: %v", ce.Domain, ce.Server, ce.Err)
+//	}
+//
+// If the caller's context is cancelled mid-failover, the check stops trying
+// further servers and the [CheckError] matches [context.Canceled] (or
+// [context.DeadlineExceeded]) instead of [ErrAllDNSFailed]. A valid answer
+// already received from a server is still returned as a best-effort result.
+//
+// Context errors are reported the same way by every method — [Checker.Check],
+// the per-domain [Result.Error], [Checker.DNSStatus], and so on: a
+// cancellation wraps [context.Canceled] in [ErrCanceled], and an expired
+// deadline wraps [context.DeadlineExceeded] in [ErrDNSTimeout]. Both the
+// sentinel and the context error match with [errors.Is].
+//
+// With [WithFailOpen], an all-servers-failed check reports the domain as not
+// blocked with [Result.Degraded] set instead of returning [ErrAllDNSFailed].
+//
+// [Result.Status] folds the verdict and error into one [CheckStatus]
+// ([StatusClean], [StatusBlocked], [StatusInvalid], [StatusTimeout], or
+// [StatusError]) for APIs that report a category per domain, and
+// [Result.SameVerdict] compares two results by that category and block
+// verdict alone, ignoring latency and the answering server.
+//
 // # Custom Cache
 //
 // Implement the Cache interface to plug in a custom backend such as
@@ -263,6 +480,12 @@
 //
 // Pass a nil value to WithCache to disable caching entirely.
 //
+// Backends that store bytes can serialize results with [EncodeResult] and
+// [DecodeResult]. Implementing SetCompression(enabled bool) as well lets
+// [WithCacheCompression] switch on gzip for the encoded values, and
+// FlushContext(ctx context.Context) error lets [Checker.FlushContext] cancel
+// a slow flush.
+//
 // # Cache Key Format
 //
 // All cache keys produced by this SDK are namespaced with the prefix
@@ -327,14 +550,69 @@
 //	reddit.com.    30    IN    A    103.155.26.29
 //
 // The checker detects this by scanning the Extra section (which contains
-// the OPT record) for the keyword "trustpositif" or "komdigi". To use
-// this detection, configure a server with the appropriate keyword:
+// the OPT record) for the keyword "trustpositif" or "komdigi". The
+// blockListUrl field is reported in [Result.BlockListURL], and a response
+// whose domain field names neither the queried domain nor one of its
+// parents is rejected with [ErrEDEDomainMismatch]. To use this detection,
+// configure a server with the appropriate keyword:
 //
 //	nawala.WithServers([]nawala.DNSServer{
 //	    {Address: "103.155.26.28", Keyword: "trustpositif", QueryType: "A"},
 //	    {Address: "103.155.26.29", Keyword: "komdigi",      QueryType: "A"},
 //	})
 //
+// Because the broad record match also sees the owner name and record header,
+// short or generic keywords such as "com" or "ns" can match every record.
+// Set [DNSServer.MatchScope] to [MatchScopeData] (or use [WithStrictMatch]
+// for all servers) to match record data only, e.g. the TXT strings of a
+// filter that publishes its block status in a TXT record:
+//
+//	nawala.WithServers([]nawala.DNSServer{
+//	    {Address: "203.0.113.1", Keyword: "blocked", QueryType: "TXT", MatchScope: nawala.MatchScopeData},
+//	})
+//
+// Keywords match as substrings, so "positif" also matches unrelated names
+// such as "positifity.com". Set [DNSServer.MatchMode] to [MatchModeLabel]
+// (or use [WithMatchMode] for all servers) to match whole DNS labels only:
+//
+//	nawala.WithServers([]nawala.DNSServer{
+//	    {Address: "180.131.144.144", Keyword: "internetpositif", QueryType: "A", MatchMode: nawala.MatchModeLabel},
+//	})
+//
+// # Block Reasons
+//
+// Every blocked [Result] carries a [BlockReason] describing how it was
+// blocked. Filtering-related EDE INFO-CODEs ([RFC 8914]) map to
+// [BlockReasonBlocked] (15), [BlockReasonCensored] (16),
+// [BlockReasonFiltered] (17), and [BlockReasonProhibited] (18). Nawala
+// CNAME redirects without an EDE option are reported as
+// [BlockReasonRedirect], and, with [WithSinkholeDetection], answers that
+// point only to private or reserved addresses as [BlockReasonSinkhole]:
+//
+//	switch r.BlockReason {
+//	case nawala.BlockReasonRedirect:
+//	    fmt.Println(r.Domain, "redirected to a landing page")
+//	case nawala.BlockReasonBlocked:
+//	    fmt.Println(r.Domain, "blocked via EDE 15")
+//	}
+//
+// # Standalone Detection
+//
+// The detection rules are also available without a [Checker] through
+// [DetectBlock], for responses obtained elsewhere (a forwarder, a cache, or
+// a packet capture):
+//
+//	blocked, details := nawala.DetectBlock(msg, nawala.DetectOptions{
+//	    Keywords: []string{"internetpositif"},
+//	    BlockIPs: []net.IP{net.ParseIP("36.86.63.185")},
+//	})
+//
+// Saved responses, in wire format or as printed by dig, can be loaded with
+// [ReadResponse], which makes a library of captured censorship responses
+// usable as test fixtures:
+//
+//	msg, err := nawala.ReadResponse(f) // e.g. the output of dig saved to a file
+//
 // # Default DNS Servers
 //
 // The checker comes pre-configured with known Nawala DNS servers:
@@ -375,4 +653,5 @@
 // [idiomatic Go]: https://go.dev/doc/effective_go
 // [RFC 7766]: https://www.rfc-editor.org/rfc/rfc7766.html
 // [RFC 7858]: https://www.rfc-editor.org/rfc/rfc7858.html
+// [RFC 8914]: https://datatracker.ietf.org/doc/html/rfc8914
 package nawala
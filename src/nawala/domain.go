@@ -5,11 +5,15 @@
 
 package nawala
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 // IsValidDomain reports whether domain is a syntactically valid domain name.
 //
-// A valid domain must have at least two labels separated by dots,
+// A valid domain must have at least two labels separated by dots
+// (see [WithAllowSingleLabel] to relax this for a [Checker]),
 // each label must be 1-63 characters long, contain only ASCII
 // letters, digits, hyphens, or underscores, and must not start or
 // end with a hyphen.
@@ -22,31 +26,82 @@ import "strings"
 // contain only letters, while Punycode TLDs (starting with "xn--") allow
 // digits and hyphens (conforming to standard hostname rules).
 //
+// Reverse-DNS names are guaranteed to validate: the IPv4 form under
+// "in-addr.arpa" (all-numeric labels) and the IPv6 nibble form under
+// "ip6.arpa" (32 single-hex-digit labels, 73 characters at most), as
+// produced by [github.com/miekg/dns.ReverseAddr] and used by
+// [Checker.CheckIP].
+//
 // [RFC 1035]: https://datatracker.ietf.org/doc/html/rfc1035
 func IsValidDomain(domain string) bool {
+	return defaultValidator.isValidDomain(domain)
+}
+
+// RFC 1035 size limits applied by [IsValidDomain].
+const (
+	maxDomainLength = 255
+	maxLabelLength  = 63
+)
+
+// validatorConfig holds the size limits and TLD rules used when validating
+// domain names. The zero value is not usable; start from [defaultValidator].
+type validatorConfig struct {
+	maxTotal      int  // maximum length of the whole name, without trailing dot
+	maxLabel      int  // maximum length of a single label
+	singleLabel   bool // accept one-label names such as "localhost"
+	underscoreTLD bool // accept underscores in the TLD, e.g. "host._internal"
+}
+
+// defaultValidator applies the RFC 1035 limits.
+var defaultValidator = validatorConfig{
+	maxTotal: maxDomainLength,
+	maxLabel: maxLabelLength,
+}
+
+// validate reports why domain is not valid under v, as an error wrapping
+// [ErrInvalidDomain], or nil when it is valid. Empty and whitespace-only
+// input gets its own message rather than an empty domain in the error.
+func (v validatorConfig) validate(domain string) error {
+	if strings.TrimSpace(domain) == "" {
+		return fmt.Errorf("%w: empty domain name", ErrInvalidDomain)
+	}
+	if !v.isValidDomain(domain) {
+		return fmt.Errorf("%w: %s", ErrInvalidDomain, domain)
+	}
+	return nil
+}
+
+// isValidDomain implements [IsValidDomain] using the limits in v.
+func (v validatorConfig) isValidDomain(domain string) bool {
 	// Remove optional trailing dot for FQDN validation
 	domain = strings.TrimSuffix(domain, ".")
 
-	if domain == "" || len(domain) > 255 {
+	if domain == "" || len(domain) > v.maxTotal {
 		return false
 	}
 
-	labels := strings.Split(domain, ".")
-	if len(labels) < 2 {
-		return false
+	if !strings.Contains(domain, ".") {
+		// A lone label is a hostname, not a TLD, so the TLD rules
+		// do not apply (e.g. "host01" is accepted).
+		return v.singleLabel && v.isValidLabel(domain)
 	}
 
-	for i, label := range labels {
-		if !isValidLabel(label) {
-			return false
-		}
-
-		if i == len(labels)-1 && !isValidTLD(label) {
+	// Labels are walked in place rather than split into a slice, since
+	// every check validates its domain.
+	var tld string
+	for label := range strings.SplitSeq(domain, ".") {
+		if !v.isValidLabel(label) {
 			return false
 		}
+		tld = label
 	}
 
-	return true
+	return v.isValidTLD(tld)
+}
+
+// isValidLabel checks if a label is valid under the RFC 1035 limits.
+func isValidLabel(label string) bool {
+	return defaultValidator.isValidLabel(label)
 }
 
 // isValidLabel checks if a label is valid.
@@ -56,9 +111,9 @@ func IsValidDomain(domain string) bool {
 // (e.g., Google AMP cache domains, cloud-provider service endpoints).
 //
 // [RFC 1035]: https://datatracker.ietf.org/doc/html/rfc1035
-func isValidLabel(label string) bool {
-	// Labels must be 1-63 characters (RFC 1035)
-	if len(label) == 0 || len(label) > 63 {
+func (v validatorConfig) isValidLabel(label string) bool {
+	// Labels must be 1-maxLabel characters (63 per RFC 1035)
+	if len(label) == 0 || len(label) > v.maxLabel {
 		return false
 	}
 
@@ -81,9 +136,16 @@ func isValidLabel(label string) bool {
 	return true
 }
 
+// isValidTLD checks if the Top-Level Domain label is valid under the
+// strict rules applied by [IsValidDomain].
+func isValidTLD(label string) bool {
+	return defaultValidator.isValidTLD(label)
+}
+
 // isValidTLD checks if the Top-Level Domain label is valid.
 // It handles both standard alphabetic TLDs and Punycode (IDN) TLDs.
-func isValidTLD(label string) bool {
+// Underscores are rejected unless v.underscoreTLD is set.
+func (v validatorConfig) isValidTLD(label string) bool {
 	// TLD must be at least 2 characters
 	if len(label) < 2 {
 		return false
@@ -94,7 +156,7 @@ func isValidTLD(label string) bool {
 		// Punycode TLDs follow standard hostname rules (already validated by isValidLabel)
 		// but MUST NOT contain underscores, which are conditionally allowed in generic labels.
 		for _, c := range label {
-			if c == '_' {
+			if c == '_' && !v.underscoreTLD {
 				return false
 			}
 		}
@@ -103,6 +165,9 @@ func isValidTLD(label string) bool {
 
 	// Standard TLDs must be letters only
 	for _, c := range label {
+		if c == '_' && v.underscoreTLD {
+			continue
+		}
 		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
 			return false
 		}
@@ -6,8 +6,11 @@
 package nawala
 
 import (
+	"context"
+	"strings"
 	"testing"
 
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/idna"
@@ -297,3 +300,95 @@ func TestIsValidDomainWithIDNA(t *testing.T) {
 		})
 	}
 }
+
+func TestIsValidDomainReverseDNS(t *testing.T) {
+	ips := []string{
+		"127.0.0.1",
+		"0.0.0.0",
+		"255.255.255.255",
+		"192.0.2.1",
+		"::1",
+		"2001:db8::1",
+		"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff",
+	}
+
+	for _, ip := range ips {
+		t.Run(ip, func(t *testing.T) {
+			reverse, err := dns.ReverseAddr(ip)
+			require.NoError(t, err)
+
+			assert.True(t, IsValidDomain(reverse), "IsValidDomain(%q)", reverse)
+
+			// The default normalizer must keep reverse names intact.
+			normalized := normalizeDomain(reverse)
+			assert.True(t, IsValidDomain(normalized), "IsValidDomain(%q)", normalized)
+			assert.Equal(t, strings.TrimSuffix(reverse, "."), strings.TrimSuffix(normalized, "."))
+		})
+	}
+
+	// The longest possible reverse name (IPv6) is well within limits.
+	reverse, err := dns.ReverseAddr("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff")
+	require.NoError(t, err)
+	assert.Len(t, reverse, 73)
+
+	assert.True(t, IsValidDomain("in-addr.arpa"))
+	assert.True(t, IsValidDomain("ip6.arpa"))
+	assert.True(t, IsValidDomain("1.0.0.127.in-addr.arpa"))
+}
+
+func TestValidatorConfigLimits(t *testing.T) {
+	v := validatorConfig{maxTotal: 20, maxLabel: 8}
+
+	assert.True(t, v.isValidDomain("short.example.com"))
+	assert.False(t, v.isValidDomain("toolonglabel.com"), "label over maxLabel")
+	assert.False(t, v.isValidDomain("abc.defgh.ijklm.comxy"), "name over maxTotal")
+	assert.True(t, v.isValidDomain("abcdefgh.example.co."), "trailing dot is not counted")
+
+	// The package-level helper keeps the RFC 1035 defaults.
+	assert.True(t, IsValidDomain(strings.Repeat("a", 63)+".com"))
+	assert.False(t, IsValidDomain(strings.Repeat("a", 64)+".com"))
+	assert.Equal(t, defaultValidator, validatorConfig{maxTotal: 255, maxLabel: 63})
+}
+
+func TestValidatorConfigSingleLabel(t *testing.T) {
+	v := defaultValidator
+	assert.False(t, v.isValidDomain("localhost"))
+
+	v.singleLabel = true
+	assert.True(t, v.isValidDomain("localhost"))
+	assert.True(t, v.isValidDomain("localhost."))
+	assert.True(t, v.isValidDomain("host01"), "TLD rules do not apply to a lone label")
+	assert.True(t, v.isValidDomain("example.com"))
+	assert.False(t, v.isValidDomain("-bad"))
+	assert.False(t, v.isValidDomain(strings.Repeat("a", 64)))
+	assert.False(t, v.isValidDomain(""))
+	assert.False(t, v.isValidDomain("."))
+
+	// The package-level helper keeps the two-label requirement.
+	assert.False(t, IsValidDomain("localhost"))
+}
+
+func TestValidatorConfigUnderscoreTLD(t *testing.T) {
+	v := defaultValidator
+	assert.False(t, v.isValidDomain("mail._dmarc"))
+
+	v.underscoreTLD = true
+	assert.True(t, v.isValidDomain("mail._dmarc"))
+	assert.True(t, v.isValidDomain("host.split_zone."))
+	assert.True(t, v.isValidDomain("example.xn--p1ai_"), "Punycode TLD may carry underscores too")
+	assert.True(t, v.isValidDomain("example.com"))
+	assert.False(t, v.isValidDomain("host._"), "TLD must still be at least 2 characters")
+	assert.False(t, v.isValidDomain("host._1"), "TLD must still not contain digits")
+
+	// The package-level helper stays strict.
+	assert.False(t, IsValidDomain("mail._dmarc"))
+}
+
+func TestWithAllowUnderscoreTLD(t *testing.T) {
+	c := New(WithAllowUnderscoreTLD(true), WithServers([]DNSServer{{Address: "127.0.0.1:1"}}))
+	assert.True(t, c.domainRules.underscoreTLD)
+
+	strict := New(WithServers([]DNSServer{{Address: "127.0.0.1:1"}}))
+	r := strict.checkSingle(context.Background(), "mail._dmarc", checkOptions{})
+	assert.ErrorIs(t, r.Error, ErrInvalidDomain)
+}
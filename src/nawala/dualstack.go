@@ -0,0 +1,92 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// CheckDualStack answers the dual-stack question for domain: is it blocked
+// over IPv4, IPv6, or both? It runs two checks concurrently, one querying A
+// and one querying AAAA records, each with the usual failover across the
+// configured servers regardless of their [DNSServer.QueryType], and merges
+// them into one [Result]:
+//
+//   - Blocked is true when either family is blocked; the other fields
+//     describe the blocked answer, preferring A when both are.
+//   - ResolvedIPs holds the addresses of both answers, IPv4 first.
+//   - Latency is the slower of the two checks.
+//
+// A blocked verdict from one family stands even if the other family's check
+// failed. Otherwise a failure in either family is reported in
+// [Result.Error], since the domain's status over that family is unknown.
+//
+// With no servers configured, [ErrNoDNSServers] is returned.
+func (c *Checker) CheckDualStack(ctx context.Context, domain string) (Result, error) {
+	if err := c.lifecycle.begin(); err != nil {
+		return Result{}, err
+	}
+	defer c.lifecycle.end()
+
+	if !c.hasServers(checkOptions{}) {
+		return Result{}, ErrNoDNSServers
+	}
+
+	var v4, v6 Result
+	var wg sync.WaitGroup
+	for _, q := range []struct {
+		qtype string
+		dst   *Result
+	}{{"A", &v4}, {"AAAA", &v6}} {
+		wg.Go(func() {
+			defer func() {
+				if r := recover(); r != nil {
+					*q.dst = Result{Domain: domain, Error: fmt.Errorf("%w: %v", ErrInternalPanic, r)}
+				}
+			}()
+			*q.dst = c.checkSingle(ctx, domain, checkOptions{queryType: q.qtype})
+		})
+	}
+	wg.Wait()
+
+	return mergeDualStack(v4, v6), nil
+}
+
+// mergeDualStack combines the A result v4 and the AAAA result v6 of
+// [Checker.CheckDualStack].
+func mergeDualStack(v4, v6 Result) Result {
+	v4Blocked := v4.Error == nil && v4.Blocked
+	v6Blocked := v6.Error == nil && v6.Blocked
+
+	var merged Result
+	switch {
+	case v4Blocked:
+		merged = v4
+	case v6Blocked:
+		merged = v6
+	case v4.Error != nil:
+		return v4
+	case v6.Error != nil:
+		return v6
+	default:
+		merged = v4
+	}
+
+	var ips []net.IP
+	for _, r := range []Result{v4, v6} {
+		if r.Error == nil {
+			ips = append(ips, r.ResolvedIPs...)
+		}
+	}
+	merged.ResolvedIPs = ips
+	if v4.Error == nil && v6.Error == nil {
+		merged.Latency = max(v4.Latency, v6.Latency)
+	}
+	return merged
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startDualStackDNSServer starts a local DNS server that answers A queries
+// with a normal address and AAAA queries with a block CNAME when
+// blockAAAA is set, a normal address otherwise.
+func startDualStackDNSServer(t *testing.T, blockAAAA bool) (string, func()) {
+	t.Helper()
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		hdr := dns.RR_Header{Name: r.Question[0].Name, Class: dns.ClassINET, Ttl: 60}
+		switch {
+		case r.Question[0].Qtype == dns.TypeA:
+			hdr.Rrtype = dns.TypeA
+			m.Answer = append(m.Answer, &dns.A{Hdr: hdr, A: net.ParseIP("93.184.216.34")})
+		case blockAAAA:
+			hdr.Rrtype = dns.TypeCNAME
+			m.Answer = append(m.Answer, &dns.CNAME{Hdr: hdr, Target: "internetpositif.id."})
+		default:
+			hdr.Rrtype = dns.TypeAAAA
+			m.Answer = append(m.Answer, &dns.AAAA{Hdr: hdr, AAAA: net.ParseIP("2606:2800:220:1::1")})
+		}
+		_ = w.WriteMsg(m)
+	})
+
+	return startTestDNSServer(t, handler)
+}
+
+func TestCheckDualStack(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("clean on both families", func(t *testing.T) {
+		addr, cleanup := startDualStackDNSServer(t, false)
+		defer cleanup()
+
+		c := New(WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}))
+		result, err := c.CheckDualStack(ctx, "example.com")
+		require.NoError(t, err)
+		require.NoError(t, result.Error)
+		assert.False(t, result.Blocked)
+		require.Len(t, result.ResolvedIPs, 2)
+		assert.NotNil(t, result.ResolvedIPs[0].To4(), "IPv4 address comes first")
+		assert.Nil(t, result.ResolvedIPs[1].To4())
+	})
+
+	t.Run("blocked over IPv6 only", func(t *testing.T) {
+		addr, cleanup := startDualStackDNSServer(t, true)
+		defer cleanup()
+
+		c := New(WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}))
+		result, err := c.CheckDualStack(ctx, "example.com")
+		require.NoError(t, err)
+		require.NoError(t, result.Error)
+		assert.True(t, result.Blocked)
+		require.Len(t, result.ResolvedIPs, 1)
+		assert.Equal(t, "93.184.216.34", result.ResolvedIPs[0].String())
+	})
+
+	t.Run("no servers", func(t *testing.T) {
+		c := New(WithServers(nil))
+		_, err := c.CheckDualStack(ctx, "example.com")
+		assert.ErrorIs(t, err, ErrNoDNSServers)
+	})
+
+	t.Run("invalid domain", func(t *testing.T) {
+		c := New()
+		result, err := c.CheckDualStack(ctx, "invalid")
+		require.NoError(t, err)
+		assert.ErrorIs(t, result.Error, ErrInvalidDomain)
+	})
+}
+
+func TestMergeDualStack(t *testing.T) {
+	failed := Result{Domain: "example.com", Error: ErrAllDNSFailed}
+	v4 := Result{Domain: "example.com", Server: "a", Latency: time.Millisecond, ResolvedIPs: []net.IP{net.ParseIP("192.0.2.1")}}
+	v6 := Result{Domain: "example.com", Server: "b", Latency: 3 * time.Millisecond, ResolvedIPs: []net.IP{net.ParseIP("2001:db8::1")}}
+	blocked := Result{Domain: "example.com", Server: "c", Blocked: true, BlockReason: BlockReasonBlocked}
+
+	merged := mergeDualStack(v4, v6)
+	assert.Equal(t, "a", merged.Server)
+	assert.Equal(t, 3*time.Millisecond, merged.Latency)
+	assert.Len(t, merged.ResolvedIPs, 2)
+
+	// A block in one family stands even when the other failed.
+	merged = mergeDualStack(failed, blocked)
+	assert.NoError(t, merged.Error)
+	assert.True(t, merged.Blocked)
+	assert.Equal(t, BlockReasonBlocked, merged.BlockReason)
+
+	// Otherwise a failure makes the verdict unknown.
+	merged = mergeDualStack(v4, failed)
+	assert.True(t, errors.Is(merged.Error, ErrAllDNSFailed))
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEnricher(t *testing.T) {
+	blocking, cleanupBlocking := startBlockingDNSServer(t)
+	defer cleanupBlocking()
+	normal, cleanupNormal := startNormalDNSServer(t)
+	defer cleanupNormal()
+
+	ctx := context.Background()
+	server := func(addr string) Option {
+		return WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}})
+	}
+
+	t.Run("metadata cached", func(t *testing.T) {
+		var calls atomic.Int32
+		c := New(server(blocking), WithEnricher(func(ctx context.Context, r *Result) error {
+			calls.Add(1)
+			if r.Blocked {
+				r.Metadata["registrar"] = "Example Registrar"
+			}
+			return nil
+		}))
+
+		r, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		require.NoError(t, r.Error)
+		assert.True(t, r.Blocked)
+		assert.Equal(t, map[string]any{"registrar": "Example Registrar"}, r.Metadata)
+
+		// A cache hit carries the metadata without enriching again.
+		r, err = c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "Example Registrar", r.Metadata["registrar"])
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("error skips cache", func(t *testing.T) {
+		var calls atomic.Int32
+		c := New(server(normal), WithEnricher(func(ctx context.Context, r *Result) error {
+			calls.Add(1)
+			r.Metadata["partial"] = true
+			return errors.New("rdap: rate limited")
+		}))
+
+		for range 2 {
+			r, err := c.CheckOne(ctx, "example.com")
+			require.NoError(t, err)
+			require.NoError(t, r.Error, "enricher errors never replace the verdict")
+			assert.False(t, r.Blocked)
+			assert.Equal(t, true, r.Metadata["partial"])
+		}
+		assert.Equal(t, int32(2), calls.Load(), "an incomplete enrichment is not cached")
+	})
+
+	t.Run("order", func(t *testing.T) {
+		var order []string
+		c := New(
+			server(normal),
+			WithEnricher(func(ctx context.Context, r *Result) error {
+				order = append(order, "first")
+				r.Metadata["geo"] = "ID"
+				return errors.New("first failed")
+			}),
+			WithEnricher(nil),
+			WithEnricher(func(ctx context.Context, r *Result) error {
+				order = append(order, "second")
+				assert.Equal(t, "ID", r.Metadata["geo"], "enrichers share the result")
+				return nil
+			}),
+			WithCache(nil),
+		)
+
+		_, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first", "second"}, order, "an error does not stop later enrichers")
+	})
+
+	t.Run("failures not enriched", func(t *testing.T) {
+		var calls atomic.Int32
+		c := New(
+			server("127.0.0.1:1"), // nothing listens here
+			WithTimeout(200*time.Millisecond),
+			WithMaxRetries(0),
+			WithEnricher(func(ctx context.Context, r *Result) error {
+				calls.Add(1)
+				return nil
+			}),
+		)
+
+		r, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		assert.ErrorIs(t, r.Error, ErrAllDNSFailed)
+		assert.Nil(t, r.Metadata)
+		assert.Zero(t, calls.Load())
+	})
+
+	t.Run("race", func(t *testing.T) {
+		var calls atomic.Int32
+		c := New(
+			WithServers([]DNSServer{
+				{Address: normal, Keyword: "internetpositif", QueryType: "A"},
+				{Address: blocking, Keyword: "internetpositif", QueryType: "A"},
+			}),
+			WithRaceServers(true),
+			WithEnricher(func(ctx context.Context, r *Result) error {
+				calls.Add(1)
+				r.Metadata["server"] = r.Server
+				return nil
+			}),
+		)
+
+		r, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		require.NoError(t, r.Error)
+		assert.True(t, r.Blocked)
+		assert.Equal(t, blocking, r.Metadata["server"])
+		assert.NotZero(t, calls.Load())
+	})
+
+	t.Run("without enricher", func(t *testing.T) {
+		r, err := New(server(normal)).CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		assert.Nil(t, r.Metadata)
+	})
+}
+
+func TestEncodeResultMetadata(t *testing.T) {
+	want := Result{
+		Domain:   "example.com",
+		Blocked:  true,
+		Metadata: map[string]any{"registrar": "Example Registrar", "age_days": 42},
+	}
+	for _, compress := range []bool{false, true} {
+		data, err := EncodeResult(want, compress)
+		require.NoError(t, err)
+		got, err := DecodeResult(data)
+		require.NoError(t, err)
+		assert.Equal(t, want.Metadata, got.Metadata)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by [NewFromEnv].
+const (
+	EnvServers          = "NAWALA_SERVERS"
+	EnvKeyword          = "NAWALA_KEYWORD"
+	EnvProtocol         = "NAWALA_PROTOCOL"
+	EnvTimeout          = "NAWALA_TIMEOUT"
+	EnvPerDomainTimeout = "NAWALA_PER_DOMAIN_TIMEOUT"
+	EnvMaxRetries       = "NAWALA_MAX_RETRIES"
+	EnvConcurrency      = "NAWALA_CONCURRENCY"
+	EnvCacheTTL         = "NAWALA_CACHE_TTL"
+)
+
+// NewFromEnv creates a [Checker] configured from NAWALA_* environment
+// variables, for twelve-factor deployments. Unset or empty variables keep
+// the defaults of [New]:
+//
+//   - NAWALA_SERVERS — comma-separated "address|keyword|type" entries, where
+//     keyword and type are optional ("180.131.144.144|internetpositif|A,
+//     8.8.8.8"), or a JSON array as read by [ParseServers]
+//   - NAWALA_KEYWORD — [WithDefaultKeyword]
+//   - NAWALA_PROTOCOL — [WithProtocol]: "udp", "tcp", or "tcp-tls"
+//   - NAWALA_TIMEOUT — [WithTimeout], as a Go duration such as "3s"
+//   - NAWALA_PER_DOMAIN_TIMEOUT — [WithPerDomainTimeout], as a Go duration
+//   - NAWALA_MAX_RETRIES — [WithMaxRetries], a non-negative integer
+//   - NAWALA_CONCURRENCY — [WithConcurrency], a positive integer
+//   - NAWALA_CACHE_TTL — [WithCacheTTL], as a Go duration
+//
+// Servers are validated like [Checker.SetServersValidated] does. If any
+// variable is invalid, no checker is created and the returned error wraps
+// [ErrInvalidEnv], naming every offending variable; invalid server
+// entries also match [ErrInvalidServer].
+//
+// opts are applied after the environment, so they take precedence:
+//
+//	c, err := nawala.NewFromEnv(nawala.WithFailOpen(true))
+func NewFromEnv(opts ...Option) (*Checker, error) {
+	var (
+		envOpts []Option
+		errs    []error
+	)
+	add := func(name string, opt Option, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			return
+		}
+		envOpts = append(envOpts, opt)
+	}
+
+	if v, ok := lookupEnv(EnvServers); ok {
+		servers, err := parseEnvServers(v)
+		add(EnvServers, WithServers(servers), err)
+	}
+	if v, ok := lookupEnv(EnvKeyword); ok {
+		add(EnvKeyword, WithDefaultKeyword(v), nil)
+	}
+	if v, ok := lookupEnv(EnvProtocol); ok {
+		var err error
+		switch v {
+		case "udp", "tcp", "tcp-tls":
+		default:
+			err = fmt.Errorf("unknown protocol %q", v)
+		}
+		add(EnvProtocol, WithProtocol(v), err)
+	}
+	if v, ok := lookupEnv(EnvTimeout); ok {
+		d, err := parseEnvDuration(v)
+		add(EnvTimeout, WithTimeout(d), err)
+	}
+	if v, ok := lookupEnv(EnvPerDomainTimeout); ok {
+		d, err := parseEnvDuration(v)
+		add(EnvPerDomainTimeout, WithPerDomainTimeout(d), err)
+	}
+	if v, ok := lookupEnv(EnvMaxRetries); ok {
+		n, err := parseEnvInt(v, 0)
+		add(EnvMaxRetries, WithMaxRetries(n), err)
+	}
+	if v, ok := lookupEnv(EnvConcurrency); ok {
+		n, err := parseEnvInt(v, 1)
+		add(EnvConcurrency, WithConcurrency(n), err)
+	}
+	if v, ok := lookupEnv(EnvCacheTTL); ok {
+		d, err := parseEnvDuration(v)
+		add(EnvCacheTTL, WithCacheTTL(d), err)
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEnv, errors.Join(errs...))
+	}
+	return New(append(envOpts, opts...)...), nil
+}
+
+// lookupEnv returns the trimmed value of the environment variable name,
+// reporting false when it is unset or blank.
+func lookupEnv(name string) (string, bool) {
+	v := strings.TrimSpace(os.Getenv(name))
+	return v, v != ""
+}
+
+// parseEnvServers parses the value of NAWALA_SERVERS: a JSON array, or
+// comma-separated "address|keyword|type" entries with keyword and type
+// optional. Empty entries are skipped, but the list as a whole must not be
+// empty.
+func parseEnvServers(v string) ([]DNSServer, error) {
+	var servers []DNSServer
+	if strings.HasPrefix(v, "[") {
+		parsed, err := ParseServers(strings.NewReader(v))
+		if err != nil {
+			return nil, err
+		}
+		servers = parsed
+	} else {
+		for entry := range strings.SplitSeq(v, ",") {
+			if strings.TrimSpace(entry) == "" {
+				continue
+			}
+			fields := strings.Split(entry, "|")
+			if len(fields) > 3 {
+				return nil, fmt.Errorf("%w: %q: want address|keyword|type", ErrInvalidServer, entry)
+			}
+			for i := range fields {
+				fields[i] = strings.TrimSpace(fields[i])
+			}
+			fields = append(fields, "", "")[:3]
+			servers = append(servers, DNSServer{Address: fields[0], Keyword: fields[1], QueryType: fields[2]})
+		}
+		if err := validateServers(servers); err != nil {
+			return nil, err
+		}
+	}
+	if len(servers) == 0 {
+		return nil, errors.New("no servers")
+	}
+	return servers, nil
+}
+
+// parseEnvDuration parses a positive Go duration such as "3s".
+func parseEnvDuration(v string) (time.Duration, error) {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration %q must be positive", v)
+	}
+	return d, nil
+}
+
+// parseEnvInt parses a base-10 integer of at least minimum.
+func parseEnvInt(v string, minimum int) (int, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, err
+	}
+	if n < minimum {
+		return 0, fmt.Errorf("%d is below the minimum of %d", n, minimum)
+	}
+	return n, nil
+}
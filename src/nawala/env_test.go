@@ -0,0 +1,114 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromEnvDefaults(t *testing.T) {
+	for _, name := range []string{
+		EnvServers, EnvKeyword, EnvProtocol, EnvTimeout, EnvPerDomainTimeout,
+		EnvMaxRetries, EnvConcurrency, EnvCacheTTL,
+	} {
+		t.Setenv(name, "")
+	}
+
+	c, err := NewFromEnv()
+	require.NoError(t, err)
+	d := New()
+	assert.Equal(t, d.Servers(), c.Servers())
+	assert.Equal(t, d.Timeout(), c.Timeout())
+	assert.Equal(t, d.MaxRetries(), c.MaxRetries())
+	assert.Equal(t, d.Concurrency(), c.Concurrency())
+	assert.Equal(t, d.CacheTTL(), c.CacheTTL())
+}
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv(EnvServers, " 180.131.144.144|internetpositif|A , 8.8.8.8:53|| ,[2001:db8::1]:5353|trustpositif,")
+	t.Setenv(EnvKeyword, "internetpositif")
+	t.Setenv(EnvProtocol, "tcp")
+	t.Setenv(EnvTimeout, "3s")
+	t.Setenv(EnvPerDomainTimeout, "10s")
+	t.Setenv(EnvMaxRetries, "0")
+	t.Setenv(EnvConcurrency, "8")
+	t.Setenv(EnvCacheTTL, "1m")
+
+	c, err := NewFromEnv(WithConcurrency(16))
+	require.NoError(t, err)
+
+	assert.Equal(t, []DNSServer{
+		{Address: "180.131.144.144", Keyword: "internetpositif", QueryType: "A"},
+		{Address: "8.8.8.8:53"},
+		{Address: "[2001:db8::1]:5353", Keyword: "trustpositif"},
+	}, c.Servers())
+	assert.Equal(t, "internetpositif", c.defaultKeyword)
+	assert.Equal(t, "tcp", c.dnsProtocol)
+	assert.Equal(t, 3*time.Second, c.Timeout())
+	assert.Equal(t, 10*time.Second, c.PerDomainTimeout())
+	assert.Equal(t, 0, c.MaxRetries())
+	assert.Equal(t, 16, c.Concurrency(), "explicit options override the environment")
+	assert.Equal(t, time.Minute, c.CacheTTL())
+}
+
+func TestNewFromEnvJSONServers(t *testing.T) {
+	t.Setenv(EnvServers, `[{"address": "180.131.144.144", "keyword": "internetpositif", "query_type": "A", "tags": ["nawala"]}]`)
+
+	c, err := NewFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, []DNSServer{
+		{Address: "180.131.144.144", Keyword: "internetpositif", QueryType: "A", Tags: []string{"nawala"}},
+	}, c.Servers())
+}
+
+func TestNewFromEnvInvalid(t *testing.T) {
+	tests := []struct {
+		name, value string
+		serverErr   bool
+	}{
+		{EnvServers, "not a host!|internetpositif|A", true},
+		{EnvServers, "8.8.8.8|kw|NOPE", true},
+		{EnvServers, "8.8.8.8|kw|A|extra", true},
+		{EnvServers, " , ", false},
+		{EnvServers, `[{"address": ""}]`, true},
+		{EnvServers, `[{"address": `, false},
+		{EnvProtocol, "https", false},
+		{EnvTimeout, "5", false},
+		{EnvTimeout, "-1s", false},
+		{EnvPerDomainTimeout, "soon", false},
+		{EnvMaxRetries, "-1", false},
+		{EnvConcurrency, "0", false},
+		{EnvConcurrency, "many", false},
+		{EnvCacheTTL, "0s", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"="+tt.value, func(t *testing.T) {
+			t.Setenv(tt.name, tt.value)
+
+			c, err := NewFromEnv()
+			assert.Nil(t, c)
+			require.ErrorIs(t, err, ErrInvalidEnv)
+			assert.ErrorContains(t, err, tt.name)
+			if tt.serverErr {
+				assert.ErrorIs(t, err, ErrInvalidServer)
+			}
+		})
+	}
+
+	t.Run("every offender", func(t *testing.T) {
+		t.Setenv(EnvTimeout, "fast")
+		t.Setenv(EnvConcurrency, "-4")
+
+		_, err := NewFromEnv()
+		require.ErrorIs(t, err, ErrInvalidEnv)
+		assert.ErrorContains(t, err, EnvTimeout)
+		assert.ErrorContains(t, err, EnvConcurrency)
+	})
+}
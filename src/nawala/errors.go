@@ -6,7 +6,9 @@
 package nawala
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net"
 	"strings"
@@ -29,6 +31,11 @@ var (
 	// ErrDNSTimeout is returned when a DNS query exceeds the configured timeout.
 	ErrDNSTimeout = errors.New("nawala: DNS query timed out")
 
+	// ErrCanceled is returned when the caller's context is cancelled before
+	// a check or query completes. The [context.Canceled] cause stays in the
+	// chain, so either can be matched with [errors.Is].
+	ErrCanceled = errors.New("nawala: check canceled")
+
 	// ErrInternalPanic is returned when an internal panic is recovered during execution.
 	ErrInternalPanic = errors.New("nawala: internal panic recovered")
 
@@ -38,8 +45,121 @@ var (
 	// ErrQueryRejected is returned when a DNS server explicitly rejects a query
 	// (e.g., Format Error, Refused, Not Implemented).
 	ErrQueryRejected = errors.New("nawala: query rejected by server")
+
+	// ErrServerFailure is returned when a DNS server answers SERVFAIL. Unlike
+	// [ErrQueryRejected], it is treated as a transient failure: the query is
+	// retried and then failed over to the next server.
+	ErrServerFailure = errors.New("nawala: DNS server failure (SERVFAIL)")
+
+	// ErrResponseTooLarge is returned when a DNS response exceeds the size
+	// limit configured via [WithMaxResponseSize].
+	ErrResponseTooLarge = errors.New("nawala: DNS response too large")
+
+	// ErrEDEDomainMismatch is returned when the "domain=" field of a
+	// response's Extended DNS Error text names neither the queried domain
+	// nor one of its parents, which indicates a stale or cross-contaminated
+	// response. Like [ErrServerFailure], the query is retried and then
+	// failed over.
+	ErrEDEDomainMismatch = errors.New("nawala: EDE domain does not match the query")
+
+	// ErrIDMismatch is returned when a response's transaction ID differs
+	// from the query's, which suggests a spoofed or misrouted reply. Like
+	// [ErrServerFailure], the query is retried and then failed over; each
+	// occurrence is counted in [Stats.IDMismatchCount].
+	//
+	// Only stream transports (TCP and DNS-over-TLS) report it. Over UDP the
+	// transport silently discards replies with a foreign ID while waiting
+	// (they may answer an earlier, timed-out query), so a spoofer guessing
+	// wrong surfaces as a timeout at most and is never counted.
+	ErrIDMismatch = errors.New("nawala: DNS response ID does not match the query")
+
+	// ErrQuestionMismatch is returned when a response's question section
+	// names a different domain, type, or class than the query, which, like
+	// [ErrIDMismatch], suggests a spoofed or misrouted reply (RFC 5452). It
+	// is retried and then failed over; each occurrence is counted in
+	// [SecurityStats.QuestionMismatchCount].
+	ErrQuestionMismatch = errors.New("nawala: DNS response question does not match the query")
+
+	// ErrCertificatePinMismatch is returned when a DoT server's certificate
+	// does not match the fingerprint pinned with [WithDoTPin], which
+	// suggests the encrypted channel is being intercepted.
+	ErrCertificatePinMismatch = errors.New("nawala: DoT certificate does not match the pinned fingerprint")
+
+	// ErrTooManyDomains is returned by [Checker.Check] when the number of
+	// domains exceeds the cap configured with [WithMaxDomains].
+	ErrTooManyDomains = errors.New("nawala: too many domains")
+
+	// ErrMalformedCacheValue is returned by [DecodeResult] when the data
+	// was not produced by [EncodeResult] or is corrupted.
+	ErrMalformedCacheValue = errors.New("nawala: malformed cache value")
+
+	// ErrInvalidServer is returned when a [DNSServer] configuration fails
+	// validation (e.g. a malformed address or an unknown query type).
+	ErrInvalidServer = errors.New("nawala: invalid DNS server configuration")
+
+	// ErrInvalidEnv is returned by [NewFromEnv] when a NAWALA_* environment
+	// variable cannot be parsed. The error names each offending variable.
+	ErrInvalidEnv = errors.New("nawala: invalid environment configuration")
+
+	// ErrRemoteConfig is returned when the remote server config configured
+	// with [WithRemoteServerConfig] cannot be fetched or applied.
+	ErrRemoteConfig = errors.New("nawala: remote server config")
+
+	// ErrClosing is returned by checks started after [Checker.Shutdown]
+	// was called.
+	ErrClosing = errors.New("nawala: checker is shutting down")
+
+	// ErrNoAnswer is returned, with [WithRequireAnswer], when a DNS server
+	// answers NOERROR without any record of the queried type (NODATA).
+	// Like [ErrServerFailure], the query is retried and then failed over.
+	ErrNoAnswer = errors.New("nawala: no answer records (NODATA)")
+
+	// ErrAttemptsExhausted is joined into the error of a failed check, for
+	// each server that was not queried because the [WithMaxTotalAttempts]
+	// budget was already spent.
+	ErrAttemptsExhausted = errors.New("nawala: total attempt budget exhausted")
+
+	// ErrServerNotFound is returned by [Checker.CheckOneServer] when the
+	// requested address is not among the configured servers.
+	ErrServerNotFound = errors.New("nawala: DNS server not configured")
 )
 
+// CheckError carries the context of a failed domain check: which domain was
+// being checked, which DNS server was last tried, and the underlying cause.
+//
+// Err wraps one of the sentinel errors (such as [ErrAllDNSFailed]) together
+// with the cause, so both forms of inspection work:
+//
+//	if errors.Is(r.Error, nawala.ErrAllDNSFailed) { ... }
+//
+//	var ce *nawala.CheckError
+//	if errors.As(r.Error, &ce) {
+//	    log.Printf("domain=%s server=%s: %v", ce.Domain, ce.Server, ce.Err)
+//	}
+type CheckError struct {
+	// Domain is the (normalized) domain name that was being checked.
+	Domain string
+
+	// Server is the address of the last DNS server that was tried.
+	Server string
+
+	// Err is the wrapped error, typically a sentinel wrapping the
+	// underlying causes. For [ErrAllDNSFailed] the causes are the
+	// per-server errors combined with [errors.Join], each prefixed with
+	// the server address, so it can be printed or inspected with
+	// [errors.Is] (e.g. against [ErrDNSTimeout]).
+	Err error
+}
+
+// Error implements the error interface.
+func (e *CheckError) Error() string {
+	return fmt.Sprintf("%v (domain: %s, server: %s)", e.Err, e.Domain, e.Server)
+}
+
+// Unwrap returns the wrapped error so that [errors.Is] and [errors.As]
+// can match the sentinel and the underlying cause.
+func (e *CheckError) Unwrap() error { return e.Err }
+
 // isConnError reports whether err indicates a broken or stale connection that
 // warrants a transparent redial. It returns false for application-level errors
 // (e.g. context cancellation, deadlines) so those are surfaced to the caller.
@@ -77,3 +197,19 @@ func isConnError(err error) bool {
 	}
 	return false
 }
+
+// contextError wraps a context error in the matching sentinel, so that
+// cancellation surfaces the same way from every entry point:
+// [context.Canceled] as [ErrCanceled] and [context.DeadlineExceeded] as
+// [ErrDNSTimeout]. Both the sentinel and the context error match with
+// [errors.Is]. Other errors, including nil, are returned unchanged.
+func contextError(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return fmt.Errorf("%w: %w", ErrCanceled, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%w: %w", ErrDNSTimeout, err)
+	default:
+		return err
+	}
+}
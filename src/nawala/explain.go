@@ -0,0 +1,217 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Explanation describes how [Checker.Explain] reached its verdict for a
+// domain, server by server.
+type Explanation struct {
+	// Domain is the normalized domain name that was checked.
+	Domain string
+
+	// Blocked is the final verdict. It is only meaningful when Server is
+	// set; otherwise no server produced a usable answer.
+	Blocked bool
+
+	// Server is the address of the server whose answer decided the verdict,
+	// or empty when every server failed.
+	Server string
+
+	// Decision is a human-readable summary of the final verdict and why it
+	// was reached.
+	Decision string
+
+	// Error is the error a check reports in [Result.Error] for the deciding
+	// answer, such as [ErrNXDOMAIN] or [ErrQueryRejected]; nil when the
+	// answer was usable or every server failed.
+	Error error
+
+	// Steps lists every server tried, in failover order.
+	Steps []ExplainStep
+}
+
+// ExplainStep describes the query sent to a single server during
+// [Checker.Explain] and what the checker concluded from the response.
+type ExplainStep struct {
+	// Server is the address of the server that was queried.
+	Server string
+
+	// Query is the question sent, e.g. "example.com. IN A".
+	Query string
+
+	// Rcode is the response code name (e.g. "NOERROR", "SERVFAIL"). It is
+	// empty when no usable response was received; see Error.
+	Rcode string
+
+	// Keyword is the block indicator searched for in the response, and
+	// MatchScope how much of each record it was matched against.
+	Keyword    string
+	MatchScope string
+
+	// MatchedSection is the response section in which Keyword was found
+	// (see [Result.MatchedSection]); empty when it was not found.
+	MatchedSection string
+
+	// EDECodes are the Extended DNS Error ([RFC 8914]) INFO-CODEs carried by
+	// the response.
+	//
+	// [RFC 8914]: https://datatracker.ietf.org/doc/html/rfc8914
+	EDECodes []uint16
+
+	// ResolvedIPs holds the A and AAAA addresses in the Answer section.
+	ResolvedIPs []net.IP
+
+	// CNAMEHops lists the names queried, in order, to follow a CNAME chain
+	// the response left unresolved (see [WithMaxCNAMEDepth]). When the
+	// last one was judged blocked, MatchedSection and ResolvedIPs describe
+	// its answer.
+	CNAMEHops []string
+
+	// Blocked reports whether this server's answer indicates a block.
+	Blocked bool
+
+	// Latency is the round-trip time of the query.
+	Latency time.Duration
+
+	// Error is the reason the query failed, if it did.
+	Error error
+
+	// Reason is a human-readable account of what the checker concluded
+	// from this step.
+	Reason string
+}
+
+// Explain checks domain like [Checker.CheckOne] but reports how the verdict
+// was reached instead of just the verdict: for every server tried, the query
+// sent, the response code, the keyword match and the section it was found
+// in, the EDE codes, and the resolved addresses, followed by the decision
+// and its reasoning. It is meant for debugging and for answering disputes
+// about a verdict without parsing raw DNS messages.
+//
+//	e, err := c.Explain(ctx, "example.com")
+//	if err != nil {
+//	    return err
+//	}
+//	for _, s := range e.Steps {
+//	    fmt.Printf("%s: %s\n", s.Server, s.Reason)
+//	}
+//	fmt.Println(e.Decision)
+//
+// Explain follows the same failover order as a check, and the same
+// [WithMaxCNAMEDepth] chain, but sends a single probe per server, bypasses
+// and does not populate the cache, and skips [WithHTTPConfirmation]. Its
+// verdict can therefore differ from a check's when blocking is intermittent.
+//
+// An invalid domain yields [ErrInvalidDomain] and a checker without servers
+// [ErrNoDNSServers]. If ctx is done before a verdict is reached, the partial
+// explanation is returned together with the context error.
+func (c *Checker) Explain(ctx context.Context, domain string) (Explanation, error) {
+	if err := c.lifecycle.begin(); err != nil {
+		return Explanation{}, err
+	}
+	defer c.lifecycle.end()
+
+	domain = c.normalizer(domain)
+	if err := c.domainRules.validate(domain); err != nil {
+		return Explanation{}, err
+	}
+
+	servers := c.snapshotServers(checkOptions{})
+	if len(servers) == 0 {
+		return Explanation{}, ErrNoDNSServers
+	}
+
+	e := Explanation{Domain: domain}
+	for _, srv := range servers {
+		step, definitive := c.explainServer(ctx, domain, srv)
+		e.Steps = append(e.Steps, step)
+
+		if definitive {
+			e.Blocked = step.Blocked
+			e.Server = srv.Address
+			e.Decision = step.Reason
+			e.Error = step.Error
+			return e, nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			e.Decision = "aborted: " + err.Error()
+			return e, contextError(err)
+		}
+	}
+
+	e.Decision = fmt.Sprintf("no verdict: all %d servers failed", len(servers))
+	return e, nil
+}
+
+// explainServer sends one probe for domain to srv and describes the outcome.
+// It reports true when the outcome is definitive, i.e. no failover to the
+// next server would happen during a check.
+func (c *Checker) explainServer(ctx context.Context, domain string, srv DNSServer) (ExplainStep, bool) {
+	qtype := parseQueryType(srv.QueryType)
+	step := ExplainStep{
+		Server:     srv.Address,
+		Query:      fmt.Sprintf("%s IN %s", dns.Fqdn(domain), dns.TypeToString[qtype]),
+		Keyword:    srv.Keyword,
+		MatchScope: c.matchScope(srv),
+	}
+
+	resp, rtt, err := c.probe(ctx, domain, srv, qtype)
+	step.Latency = rtt
+	if resp != nil {
+		step.Rcode = dns.RcodeToString[resp.Rcode]
+	}
+	if err != nil {
+		step.Error = err
+		if errors.Is(err, ErrNXDOMAIN) || errors.Is(err, ErrQueryRejected) {
+			step.Reason = fmt.Sprintf("not blocked: definitive answer, no failover (%v)", err)
+			return step, true
+		}
+		step.Reason = fmt.Sprintf("query failed, trying next server (%v)", err)
+		return step, false
+	}
+
+	for _, ede := range extendedErrors(resp) {
+		step.EDECodes = append(step.EDECodes, ede.InfoCode)
+	}
+
+	result, stop, hops := c.evaluateChain(ctx, domain, srv, qtype, resp, rtt)
+	step.CNAMEHops = hops
+	step.Blocked = result.Blocked
+	step.MatchedSection = result.MatchedSection
+	step.ResolvedIPs = result.ResolvedIPs
+
+	if !stop && !result.Blocked {
+		if err := c.noAnswer(domain, resp, qtype); err != nil {
+			step.Error = err
+			step.Reason = fmt.Sprintf("no answer, trying next server (%v)", err)
+			return step, false
+		}
+	}
+
+	switch {
+	case stop:
+		step.Reason = fmt.Sprintf("blocked=%v: decided by the response validator", result.Blocked)
+	case result.Blocked && len(hops) > 0:
+		step.Reason = fmt.Sprintf("blocked: keyword %q found in the %s section of the answer for %s, after %d CNAME hop(s) (reason: %s)",
+			srv.Keyword, result.MatchedSection, hops[len(hops)-1], len(hops), result.BlockReason)
+	case result.Blocked:
+		step.Reason = fmt.Sprintf("blocked: keyword %q found in the %s section (reason: %s)",
+			srv.Keyword, result.MatchedSection, result.BlockReason)
+	default:
+		step.Reason = fmt.Sprintf("not blocked: keyword %q not found in any section", srv.Keyword)
+	}
+	return step, true
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("komdigi EDE", func(t *testing.T) {
+		handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			_ = w.WriteMsg(newKomdigiReply(r, dns.ExtendedErrorCodeBlocked))
+		})
+		addr, cleanup := startTestDNSServer(t, handler)
+		defer cleanup()
+
+		c := New(WithServers([]DNSServer{{Address: addr, Keyword: "trustpositif", QueryType: "A"}}))
+		e, err := c.Explain(ctx, "Reddit.com")
+		require.NoError(t, err)
+
+		assert.Equal(t, "reddit.com", e.Domain)
+		assert.True(t, e.Blocked)
+		assert.Equal(t, addr, e.Server)
+		require.Len(t, e.Steps, 1)
+
+		step := e.Steps[0]
+		assert.Equal(t, "reddit.com. IN A", step.Query)
+		assert.Equal(t, "NOERROR", step.Rcode)
+		assert.Equal(t, "trustpositif", step.Keyword)
+		assert.Equal(t, MatchScopeRecord, step.MatchScope)
+		assert.Equal(t, SectionAdditional, step.MatchedSection)
+		assert.Equal(t, []uint16{dns.ExtendedErrorCodeBlocked}, step.EDECodes)
+		assert.True(t, step.Blocked)
+		assert.NoError(t, step.Error)
+		assert.Contains(t, e.Decision, "additional section")
+		assert.Equal(t, step.Reason, e.Decision)
+	})
+
+	t.Run("failover to clean server", func(t *testing.T) {
+		failing := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetRcode(r, dns.RcodeServerFailure)
+			_ = w.WriteMsg(m)
+		})
+		bad, cleanupBad := startTestDNSServer(t, failing)
+		defer cleanupBad()
+		good, cleanupGood := startNormalDNSServer(t)
+		defer cleanupGood()
+
+		c := New(WithServers([]DNSServer{
+			{Address: bad, Keyword: "internetpositif", QueryType: "A"},
+			{Address: good, Keyword: "internetpositif", QueryType: "A"},
+		}))
+		e, err := c.Explain(ctx, "example.com")
+		require.NoError(t, err)
+
+		assert.False(t, e.Blocked)
+		assert.Equal(t, good, e.Server)
+		require.Len(t, e.Steps, 2)
+
+		assert.Equal(t, "SERVFAIL", e.Steps[0].Rcode)
+		assert.ErrorIs(t, e.Steps[0].Error, ErrServerFailure)
+		assert.Contains(t, e.Steps[0].Reason, "trying next server")
+
+		assert.Equal(t, "NOERROR", e.Steps[1].Rcode)
+		assert.Empty(t, e.Steps[1].MatchedSection)
+		assert.Equal(t, "93.184.216.34", e.Steps[1].ResolvedIPs[0].String())
+		assert.Contains(t, e.Decision, "not blocked")
+	})
+
+	t.Run("CNAME chain matches check", func(t *testing.T) {
+		var queries atomic.Int32
+		addr := startCNAMEChainDNSServer(t, map[string]string{
+			"example.com.":      "cdn.example.net.",
+			"cdn.example.net.":  "edge.example.org.",
+			"edge.example.org.": "internetpositif.id.",
+		}, &queries)
+
+		c := New(
+			WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithMaxRetries(0),
+			WithCache(nil),
+			WithMaxCNAMEDepth(3),
+		)
+		r, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		require.True(t, r.Blocked)
+
+		e, err := c.Explain(ctx, "example.com")
+		require.NoError(t, err)
+		assert.Equal(t, r.Blocked, e.Blocked)
+		assert.Equal(t, r.Server, e.Server)
+		require.Len(t, e.Steps, 1)
+
+		step := e.Steps[0]
+		assert.Equal(t, []string{"cdn.example.net.", "edge.example.org."}, step.CNAMEHops)
+		assert.Equal(t, SectionAnswer, step.MatchedSection)
+		assert.Contains(t, e.Decision, "2 CNAME hop(s)")
+	})
+
+	t.Run("all servers failed", func(t *testing.T) {
+		handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetRcode(r, dns.RcodeServerFailure)
+			_ = w.WriteMsg(m)
+		})
+		addr, cleanup := startTestDNSServer(t, handler)
+		defer cleanup()
+
+		c := New(WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}))
+		e, err := c.Explain(ctx, "example.com")
+		require.NoError(t, err)
+		assert.Empty(t, e.Server)
+		assert.Contains(t, e.Decision, "all 1 servers failed")
+	})
+
+	t.Run("NXDOMAIN matches check", func(t *testing.T) {
+		handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetRcode(r, dns.RcodeNameError)
+			_ = w.WriteMsg(m)
+		})
+		addr, cleanup := startTestDNSServer(t, handler)
+		defer cleanup()
+		clean, cleanupClean := startNormalDNSServer(t)
+		defer cleanupClean()
+
+		c := New(
+			WithServers([]DNSServer{
+				{Address: addr, Keyword: "internetpositif", QueryType: "A"},
+				{Address: clean, Keyword: "internetpositif", QueryType: "A"},
+			}),
+			WithCache(nil),
+		)
+		r, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		require.ErrorIs(t, r.Error, ErrNXDOMAIN)
+
+		e, err := c.Explain(ctx, "example.com")
+		require.NoError(t, err)
+		assert.False(t, e.Blocked)
+		assert.Equal(t, r.Server, e.Server)
+		assert.ErrorIs(t, e.Error, ErrNXDOMAIN)
+		assert.Equal(t, r.Error.Error(), e.Error.Error())
+		require.Len(t, e.Steps, 1, "no failover after NXDOMAIN")
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		c := New()
+		_, err := c.Explain(ctx, "invalid")
+		assert.ErrorIs(t, err, ErrInvalidDomain)
+
+		empty := New()
+		empty.ReplaceServers(nil)
+		_, err = empty.Explain(ctx, "example.com")
+		assert.ErrorIs(t, err, ErrNoDNSServers)
+	})
+
+	t.Run("cache bypassed", func(t *testing.T) {
+		addr, cleanup := startBlockingDNSServer(t)
+		defer cleanup()
+
+		c := New(WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}))
+		e, err := c.Explain(ctx, "example.com")
+		require.NoError(t, err)
+		assert.True(t, e.Blocked)
+		assert.Equal(t, SectionAnswer, e.Steps[0].MatchedSection)
+
+		n, ok := c.CacheLen()
+		require.True(t, ok)
+		assert.Zero(t, n)
+	})
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// QueryEvent describes a single DNS query performed by the checker.
+// It is passed to the hook registered with [WithQueryHook].
+type QueryEvent struct {
+	// Domain is the (normalized) domain name that was queried.
+	Domain string
+
+	// Server is the address of the DNS server that was queried.
+	Server string
+
+	// QueryType is the DNS record type that was queried (e.g. dns.TypeA).
+	QueryType uint16
+
+	// Rcode is the response code of the reply, or -1 when no reply was
+	// received (for example on timeout or network error).
+	Rcode int
+
+	// Duration is the wall-clock time the query took.
+	Duration time.Duration
+
+	// Err is the error returned by the query, if any.
+	Err error
+}
+
+// QueryHook is a callback invoked after every DNS query the checker sends.
+//
+// The ctx argument is the same context passed to [Checker.Check],
+// [Checker.CheckOne], or [Checker.CheckStream], so request-scoped values
+// stored in it (such as a trace or correlation ID) can be extracted for
+// logging:
+//
+//	type requestIDKey struct{}
+//
+//	c := nawala.New(nawala.WithQueryHook(func(ctx context.Context, ev nawala.QueryEvent) {
+//	    id, _ := ctx.Value(requestIDKey{}).(string)
+//	    log.Printf("request=%s domain=%s server=%s rcode=%d took=%s err=%v",
+//	        id, ev.Domain, ev.Server, ev.Rcode, ev.Duration, ev.Err)
+//	}))
+//
+//	ctx := context.WithValue(r.Context(), requestIDKey{}, reqID)
+//	result, err := c.CheckOne(ctx, "example.com")
+//
+// Hooks are called synchronously from the goroutine performing the query and
+// may run concurrently, so they must be safe for concurrent use and should
+// return quickly.
+type QueryHook func(ctx context.Context, ev QueryEvent)
+
+// ServerChangeHook is a callback registered with [WithServerChangeHook],
+// invoked after a runtime change to the server list with the list before
+// and after the change, for audit logging:
+//
+//	c := nawala.New(nawala.WithServerChangeHook(func(old, new []nawala.DNSServer) {
+//	    log.Printf("nawala: servers changed from %d to %d entries", len(old), len(new))
+//	}))
+//
+// Both slices are copies the hook may keep or modify. The hook runs
+// synchronously on the goroutine that made the change, after the server
+// lock has been released, so it may call other [Checker] methods. Hooks for
+// concurrent changes may run concurrently and in any order.
+type ServerChangeHook func(old, new []DNSServer)
+
+// ResponseValidator is a custom block-detection rule registered with
+// [WithResponseValidator]. It is called with every successful DNS response,
+// before the built-in keyword check, and can inspect anything the keyword
+// cannot express — specific IP ranges, SOA serials, TTL patterns, and so on.
+//
+// When stop is false the verdict is ignored and the built-in keyword check
+// runs as usual. When stop is true, blocked is the final verdict for the
+// response and no further probes are sent to the server:
+//
+//	sinkhole := netip.MustParsePrefix("36.86.63.0/24")
+//	c := nawala.New(nawala.WithResponseValidator(
+//	    func(domain string, srv nawala.DNSServer, resp *dns.Msg) (blocked, stop bool) {
+//	        for _, rr := range resp.Answer {
+//	            if a, ok := rr.(*dns.A); ok {
+//	                addr, _ := netip.AddrFromSlice(a.A.To4())
+//	                if sinkhole.Contains(addr) {
+//	                    return true, true
+//	                }
+//	            }
+//	        }
+//	        return false, false // fall back to keyword matching
+//	    }))
+//
+// Validators may run concurrently and must be safe for concurrent use. resp
+// must not be modified.
+type ResponseValidator func(domain string, srv DNSServer, resp *dns.Msg) (blocked bool, stop bool)
+
+// Enricher attaches external data to a verdict, registered with
+// [WithEnricher]. It is called once for every result obtained from a DNS
+// server, after the verdict and any HTTP confirmation, and before the result
+// is cached. It may set entries in [Result.Metadata], which is never nil
+// when an enricher runs, or adjust other fields. A typical use is looking up
+// the registrar of blocked domains over RDAP:
+//
+//	c := nawala.New(nawala.WithEnricher(func(ctx context.Context, r *nawala.Result) error {
+//	    if !r.Blocked {
+//	        return nil
+//	    }
+//	    registrar, err := rdapRegistrar(ctx, r.Domain)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    r.Metadata["registrar"] = registrar
+//	    return nil
+//	}))
+//
+// The ctx argument is the caller's context, bounded by [WithPerDomainTimeout]
+// if set. A non-nil error marks the enrichment as incomplete: the verdict is
+// still returned with whatever was attached, but the result is not cached,
+// so the next check enriches it again. Results served from the cache are
+// not enriched again.
+//
+// Enrichers may run concurrently and must be safe for concurrent use.
+type Enricher func(ctx context.Context, r *Result) error
@@ -0,0 +1,175 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type requestIDKey struct{}
+
+func TestWithQueryHook(t *testing.T) {
+	addr, cleanup := startNormalDNSServer(t)
+	defer cleanup()
+
+	var (
+		mu     sync.Mutex
+		events []QueryEvent
+		ids    []string
+	)
+
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(2),
+		WithQueryHook(func(ctx context.Context, ev QueryEvent) {
+			id, _ := ctx.Value(requestIDKey{}).(string)
+			mu.Lock()
+			events = append(events, ev)
+			ids = append(ids, id)
+			mu.Unlock()
+		}),
+	)
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-42")
+	result, err := c.CheckOne(ctx, "example.com")
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, events, 3, "expected one event per probe")
+	for i, ev := range events {
+		assert.Equal(t, "req-42", ids[i], "hook must receive the caller's context")
+		assert.Equal(t, "example.com", ev.Domain)
+		assert.Equal(t, addr, ev.Server)
+		assert.Equal(t, dns.TypeA, ev.QueryType)
+		assert.Equal(t, dns.RcodeSuccess, ev.Rcode)
+		assert.NoError(t, ev.Err)
+	}
+}
+
+func TestWithQueryHookError(t *testing.T) {
+	var got []QueryEvent
+
+	c := New(
+		WithServers([]DNSServer{{Address: "127.0.0.1:19998", Keyword: "test", QueryType: "A"}}),
+		WithTimeout(200*time.Millisecond),
+		WithMaxRetries(0),
+		WithQueryHook(func(_ context.Context, ev QueryEvent) { got = append(got, ev) }),
+	)
+
+	_, err := c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, -1, got[0].Rcode)
+	assert.Error(t, got[0].Err)
+}
+
+func TestWithQueryHookNil(t *testing.T) {
+	c := New(WithQueryHook(nil))
+	assert.Nil(t, c.queryHook)
+}
+
+func TestWithResponseValidator(t *testing.T) {
+	normalAddr, cleanupNormal := startNormalDNSServer(t)
+	defer cleanupNormal()
+	blockAddr, cleanupBlock := startBlockingDNSServer(t)
+	defer cleanupBlock()
+
+	// isDocIP flags the A record served by startNormalDNSServer.
+	isDocIP := func(domain string, srv DNSServer, resp *dns.Msg) (bool, bool) {
+		for _, ip := range resolvedIPs(resp) {
+			if ip.String() == "93.184.216.34" {
+				return true, true
+			}
+		}
+		return false, false
+	}
+
+	for _, parallel := range []bool{false, true} {
+		opts := []Option{WithMaxRetries(2), WithCache(nil), WithParallelProbes(parallel)}
+
+		// The validator's final verdict blocks what the keyword would not.
+		c := New(append(opts,
+			WithServers([]DNSServer{{Address: normalAddr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithResponseValidator(isDocIP),
+		)...)
+		result, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		require.NoError(t, result.Error)
+		assert.True(t, result.Blocked)
+
+		// stop=false falls back to keyword matching.
+		c = New(append(opts,
+			WithServers([]DNSServer{{Address: blockAddr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithResponseValidator(isDocIP),
+		)...)
+		result, err = c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.True(t, result.Blocked, "keyword match still applies")
+
+		// A final clean verdict overrides the keyword and stops probing.
+		var calls sync.Map
+		c = New(append(opts,
+			WithServers([]DNSServer{{Address: blockAddr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithResponseValidator(func(domain string, srv DNSServer, resp *dns.Msg) (bool, bool) {
+				calls.Store(domain+"|"+srv.Address, true)
+				return false, true
+			}),
+		)...)
+		result, err = c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		require.NoError(t, result.Error)
+		assert.False(t, result.Blocked)
+		assert.Equal(t, 1, result.Attempts, "no further probes after stop")
+		_, called := calls.Load("example.com|" + blockAddr)
+		assert.True(t, called, "validator receives the domain and server")
+	}
+}
+
+func TestWithServerChangeHook(t *testing.T) {
+	type change struct{ old, new []DNSServer }
+	var changes []change
+
+	a := DNSServer{Address: "203.0.113.1", Keyword: "blocked", QueryType: "A"}
+	b := DNSServer{Address: "203.0.113.2", Keyword: "blocked", QueryType: "A"}
+
+	var c *Checker
+	c = New(
+		WithServers([]DNSServer{a}),
+		WithServerChangeHook(func(old, new []DNSServer) {
+			// The lock is released, so the hook may call back into the checker.
+			assert.Equal(t, new, c.Servers())
+			changes = append(changes, change{old, new})
+		}),
+	)
+	assert.Empty(t, changes, "construction does not invoke the hook")
+
+	c.SetServers(b)
+	c.DeleteServers("198.51.100.1") // unknown address: no change
+	c.DeleteServers(a.Address)
+	c.ReplaceServers([]DNSServer{a, b})
+	c.DeleteServersFunc(func(s DNSServer) bool { return s.Address == b.Address })
+	c.ResetServers()
+
+	require.Len(t, changes, 5)
+	assert.Equal(t, change{[]DNSServer{a}, []DNSServer{a, b}}, changes[0])
+	assert.Equal(t, change{[]DNSServer{a, b}, []DNSServer{b}}, changes[1])
+	assert.Equal(t, change{[]DNSServer{b}, []DNSServer{a, b}}, changes[2])
+	assert.Equal(t, change{[]DNSServer{a, b}, []DNSServer{a}}, changes[3])
+	assert.Equal(t, change{[]DNSServer{a}, defaultServers}, changes[4])
+
+	// The hook receives copies, not the live list.
+	changes[4].new[0].Keyword = "mutated"
+	assert.Equal(t, defaultServers[0].Keyword, c.Servers()[0].Keyword)
+}
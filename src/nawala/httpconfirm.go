@@ -0,0 +1,143 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// maxBlockPageBody caps how much of a landing page is read when looking for
+// block-page markers. Indonesian block pages are small static documents, so
+// there is no need to buffer more than this.
+const maxBlockPageBody = 64 << 10
+
+// blockPageMarkers are the case-insensitive substrings that identify a known
+// Indonesian block page (Nawala landing pages and the Komdigi TrustPositif
+// portal) in the HTTP response body, headers, or final URL.
+var blockPageMarkers = []string{
+	"internetpositif",
+	"internetsehatku",
+	"trustpositif",
+	"komdigi",
+}
+
+// resolvedIPs extracts every A and AAAA address from the Answer section of msg.
+func resolvedIPs(msg *dns.Msg) []net.IP {
+	if msg == nil {
+		return nil
+	}
+
+	var ips []net.IP
+	for _, rr := range msg.Answer {
+		switch v := rr.(type) {
+		case *dns.A:
+			ips = append(ips, v.A)
+		case *dns.AAAA:
+			ips = append(ips, v.AAAA)
+		}
+	}
+	return ips
+}
+
+// confirmBlockPage performs an HTTP GET against each resolved IP of a blocked
+// domain, first over HTTP on port 80 and then over HTTPS on port 443, and
+// reports whether any of them serves a known block page.
+//
+// The request is sent to the IP directly with the Host header set to domain,
+// and for HTTPS with domain as the TLS server name, mirroring what a browser
+// would do after following the DNS answer. The keyword configured for the
+// server is checked in addition to the built-in [blockPageMarkers]. Every
+// request is bound to ctx.
+func confirmBlockPage(ctx context.Context, client *http.Client, domain, keyword string, ips []net.IP) bool {
+	tlsClient, closeIdle := withServerName(client, domain)
+	defer closeIdle()
+
+	for _, ip := range ips {
+		if ctx.Err() != nil {
+			return false
+		}
+		if fetchBlockPage(ctx, client, "http", "80", domain, keyword, ip) ||
+			fetchBlockPage(ctx, tlsClient, "https", "443", domain, keyword, ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// withServerName returns a copy of client whose transport sends domain as the
+// TLS server name, since an HTTPS request to an IP would otherwise use the IP,
+// and a function that releases the copy's idle connections. A client with a
+// transport other than [*http.Transport] is returned as is.
+func withServerName(client *http.Client, domain string) (*http.Client, func()) {
+	base, ok := client.Transport.(*http.Transport)
+	if client.Transport == nil {
+		base, ok = http.DefaultTransport.(*http.Transport)
+	}
+	if !ok {
+		return client, func() {}
+	}
+
+	tr := base.Clone()
+	if tr.TLSClientConfig == nil {
+		tr.TLSClientConfig = &tls.Config{}
+	}
+	tr.TLSClientConfig.ServerName = domain
+
+	tlsClient := *client
+	tlsClient.Transport = tr
+	return &tlsClient, tr.CloseIdleConnections
+}
+
+// fetchBlockPage sends a single block-page probe to ip on port over scheme.
+func fetchBlockPage(ctx context.Context, client *http.Client, scheme, port, domain, keyword string, ip net.IP) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, scheme+"://"+net.JoinHostPort(ip.String(), port)+"/", nil)
+	if err != nil {
+		return false
+	}
+	req.Host = domain
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBlockPageBody))
+
+	var sb strings.Builder
+	sb.WriteString(resp.Request.URL.String())
+	for name, values := range resp.Header {
+		sb.WriteString(name)
+		for _, v := range values {
+			sb.WriteString(v)
+		}
+	}
+	sb.Write(body)
+
+	return hasBlockPageMarker(sb.String(), keyword)
+}
+
+// hasBlockPageMarker reports whether s contains keyword or any of the
+// built-in [blockPageMarkers] (case-insensitive).
+func hasBlockPageMarker(s, keyword string) bool {
+	s = strings.ToLower(s)
+	if keyword != "" && strings.Contains(s, strings.ToLower(keyword)) {
+		return true
+	}
+	for _, m := range blockPageMarkers {
+		if strings.Contains(s, m) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startLandingDNSServer starts a local DNS server that answers with a CNAME
+// to "internetpositif.id." followed by the landing page A record 127.0.0.1.
+func startLandingDNSServer(t *testing.T) (string, func()) {
+	t.Helper()
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		m.Answer = append(m.Answer,
+			&dns.CNAME{
+				Hdr:    dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 60},
+				Target: "internetpositif.id.",
+			},
+			&dns.A{
+				Hdr: dns.RR_Header{Name: "internetpositif.id.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   net.ParseIP("127.0.0.1"),
+			},
+		)
+		_ = w.WriteMsg(m)
+	})
+
+	return startTestDNSServer(t, handler)
+}
+
+// newPinnedHTTPClient returns an HTTP client that dials target regardless of
+// the requested address, so block-page probes against resolved IPs land on a
+// local [httptest.Server].
+func newPinnedHTTPClient(target string) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, target)
+			},
+		},
+	}
+}
+
+func TestWithHTTPConfirmation(t *testing.T) {
+	dnsAddr, cleanup := startLandingDNSServer(t)
+	defer cleanup()
+
+	t.Run("block page confirmed", func(t *testing.T) {
+		var gotHost string
+		hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotHost = r.Host
+			fmt.Fprint(w, `<html><a href="https://internetpositif.id">Internet Positif</a></html>`)
+		}))
+		defer hs.Close()
+
+		c := New(
+			WithServers([]DNSServer{{Address: dnsAddr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithHTTPConfirmation(newPinnedHTTPClient(hs.Listener.Addr().String())),
+			WithCache(nil),
+		)
+
+		result, err := c.CheckOne(context.Background(), "blocked.example.com")
+		require.NoError(t, err)
+		require.NoError(t, result.Error)
+		assert.True(t, result.Blocked)
+		assert.True(t, result.HTTPConfirmed)
+		assert.Equal(t, "blocked.example.com", gotHost)
+		require.Len(t, result.ResolvedIPs, 1)
+		assert.Equal(t, "127.0.0.1", result.ResolvedIPs[0].String())
+	})
+
+	t.Run("block page confirmed over HTTPS", func(t *testing.T) {
+		var gotHost, gotServerName string
+		hs := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotHost, gotServerName = r.Host, r.TLS.ServerName
+			fmt.Fprint(w, `<html><a href="https://internetpositif.id">Internet Positif</a></html>`)
+		}))
+		defer hs.Close()
+
+		// Only port 443 reaches the TLS server; port 80 is refused.
+		tr := hs.Client().Transport.(*http.Transport).Clone()
+		tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if _, port, _ := net.SplitHostPort(addr); port != "443" {
+				return nil, fmt.Errorf("refused: %s", addr)
+			}
+			var d net.Dialer
+			return d.DialContext(ctx, network, hs.Listener.Addr().String())
+		}
+
+		c := New(
+			WithServers([]DNSServer{{Address: dnsAddr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithHTTPConfirmation(&http.Client{Transport: tr}),
+			WithCache(nil),
+		)
+
+		// The test certificate is valid for example.com, so verification
+		// only succeeds when the domain is sent as the server name.
+		result, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.True(t, result.Blocked)
+		assert.True(t, result.HTTPConfirmed)
+		assert.Equal(t, "example.com", gotHost)
+		assert.Equal(t, "example.com", gotServerName)
+	})
+
+	t.Run("regular page not confirmed", func(t *testing.T) {
+		hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "<html><title>Welcome</title></html>")
+		}))
+		defer hs.Close()
+
+		c := New(
+			WithServers([]DNSServer{{Address: dnsAddr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithHTTPConfirmation(newPinnedHTTPClient(hs.Listener.Addr().String())),
+			WithCache(nil),
+		)
+
+		result, err := c.CheckOne(context.Background(), "blocked.example.com")
+		require.NoError(t, err)
+		assert.True(t, result.Blocked)
+		assert.False(t, result.HTTPConfirmed)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		c := New(
+			WithServers([]DNSServer{{Address: dnsAddr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithHTTPConfirmation(nil),
+		)
+		assert.Nil(t, c.httpClient)
+
+		result, err := c.CheckOne(context.Background(), "blocked.example.com")
+		require.NoError(t, err)
+		assert.True(t, result.Blocked)
+		assert.False(t, result.HTTPConfirmed)
+	})
+}
+
+func TestConfirmBlockPageContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ok := confirmBlockPage(ctx, http.DefaultClient, "example.com", "", []net.IP{net.ParseIP("127.0.0.1")})
+	assert.False(t, ok)
+}
+
+func TestHasBlockPageMarker(t *testing.T) {
+	assert.True(t, hasBlockPageMarker("Location: https://TrustPositif.komdigi.go.id/", ""))
+	assert.True(t, hasBlockPageMarker("custom landing page", "LANDING"))
+	assert.False(t, hasBlockPageMarker("hello world", ""))
+}
+
+func TestResolvedIPs(t *testing.T) {
+	assert.Nil(t, resolvedIPs(nil))
+
+	msg := new(dns.Msg)
+	msg.Answer = []dns.RR{
+		&dns.A{Hdr: dns.RR_Header{Name: "a.", Rrtype: dns.TypeA}, A: net.ParseIP("192.0.2.1")},
+		&dns.AAAA{Hdr: dns.RR_Header{Name: "a.", Rrtype: dns.TypeAAAA}, AAAA: net.ParseIP("2001:db8::1")},
+		&dns.CNAME{Hdr: dns.RR_Header{Name: "a.", Rrtype: dns.TypeCNAME}, Target: "b."},
+	}
+
+	ips := resolvedIPs(msg)
+	require.Len(t, ips, 2)
+	assert.Equal(t, "192.0.2.1", ips[0].String())
+	assert.Equal(t, "2001:db8::1", ips[1].String())
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"fmt"
+
+	"github.com/miekg/dns"
+)
+
+// hasAnswer reports whether msg's Answer section holds at least one record
+// of type qtype. For ANY queries every record counts.
+func hasAnswer(msg *dns.Msg, qtype uint16) bool {
+	for _, rr := range msg.Answer {
+		if qtype == dns.TypeANY || rr.Header().Rrtype == qtype {
+			return true
+		}
+	}
+	return false
+}
+
+// noAnswer returns an [ErrNoAnswer] error when [WithRequireAnswer] is
+// enabled and resp is a NODATA response for qtype: NOERROR without a single
+// answer record of that type. A bare CNAME chain that is not resolved to
+// the queried type counts as NODATA too.
+func (c *Checker) noAnswer(domain string, resp *dns.Msg, qtype uint16) error {
+	if !c.requireAnswer || resp.Rcode != dns.RcodeSuccess || hasAnswer(resp, qtype) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s has no %s records", ErrNoAnswer, domain, dns.TypeToString[qtype])
+}
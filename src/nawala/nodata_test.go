@@ -0,0 +1,166 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasAnswer(t *testing.T) {
+	msg := func(record string) *dns.Msg {
+		rr, err := dns.NewRR(record)
+		require.NoError(t, err)
+		return &dns.Msg{Answer: []dns.RR{rr}}
+	}
+
+	a := msg("example.com. 60 IN A 192.0.2.1")
+	assert.True(t, hasAnswer(a, dns.TypeA))
+	assert.False(t, hasAnswer(a, dns.TypeAAAA))
+	assert.True(t, hasAnswer(a, dns.TypeANY))
+
+	cname := msg("example.com. 60 IN CNAME cdn.example.net.")
+	assert.False(t, hasAnswer(cname, dns.TypeA), "an unresolved CNAME is NODATA for A")
+
+	assert.False(t, hasAnswer(new(dns.Msg), dns.TypeANY))
+}
+
+// startNoDataDNSServer answers every query with NOERROR and an empty
+// Answer section, counting the queries it receives.
+func startNoDataDNSServer(t *testing.T, queries *atomic.Int32) string {
+	t.Helper()
+	addr, cleanup := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		queries.Add(1)
+		m := new(dns.Msg)
+		m.SetReply(r)
+		_ = w.WriteMsg(m)
+	}))
+	t.Cleanup(cleanup)
+	return addr
+}
+
+func TestWithRequireAnswer(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		var queries atomic.Int32
+		addr := startNoDataDNSServer(t, &queries)
+		c := New(
+			WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithMaxRetries(0),
+		)
+
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		require.NoError(t, r.Error)
+		assert.False(t, r.Blocked)
+	})
+
+	t.Run("fails over", func(t *testing.T) {
+		var queries atomic.Int32
+		noData := startNoDataDNSServer(t, &queries)
+		normal, cleanup := startNormalDNSServer(t)
+		defer cleanup()
+
+		c := New(
+			WithServers([]DNSServer{
+				{Address: noData, Keyword: "internetpositif", QueryType: "A"},
+				{Address: normal, Keyword: "internetpositif", QueryType: "A"},
+			}),
+			WithMaxRetries(0),
+			WithRequireAnswer(true),
+		)
+
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		require.NoError(t, r.Error)
+		assert.False(t, r.Blocked)
+		assert.Equal(t, normal, r.Server)
+		assert.EqualValues(t, 1, queries.Load())
+
+		// Answering NODATA still counts as a healthy server.
+		rate, samples := c.ServerReliability(noData)
+		assert.Equal(t, 1.0, rate)
+		assert.Equal(t, 1, samples)
+	})
+
+	t.Run("all servers", func(t *testing.T) {
+		var queries atomic.Int32
+		addr := startNoDataDNSServer(t, &queries)
+		clock := newFakeClock()
+		c := New(
+			WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithMaxRetries(1),
+			WithRequireAnswer(true),
+			WithClock(clock),
+		)
+
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.ErrorIs(t, r.Error, ErrAllDNSFailed)
+		assert.ErrorIs(t, r.Error, ErrNoAnswer)
+		assert.EqualValues(t, 2, queries.Load(), "NODATA is retried")
+	})
+
+	t.Run("parallel probes", func(t *testing.T) {
+		var queries atomic.Int32
+		addr := startNoDataDNSServer(t, &queries)
+		c := New(
+			WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithMaxRetries(1),
+			WithParallelProbes(true),
+			WithRequireAnswer(true),
+		)
+
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.ErrorIs(t, r.Error, ErrNoAnswer)
+	})
+
+	t.Run("blocked without answers", func(t *testing.T) {
+		addr, cleanup := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := newKomdigiReply(r, dns.ExtendedErrorCodeBlocked)
+			m.Answer = nil
+			_ = w.WriteMsg(m)
+		}))
+		defer cleanup()
+
+		c := New(
+			WithServers([]DNSServer{{Address: addr, Keyword: "trustpositif", QueryType: "A"}}),
+			WithMaxRetries(0),
+			WithRequireAnswer(true),
+		)
+
+		r, err := c.CheckOne(context.Background(), "reddit.com")
+		require.NoError(t, err)
+		require.NoError(t, r.Error)
+		assert.True(t, r.Blocked)
+	})
+
+	t.Run("explain", func(t *testing.T) {
+		var queries atomic.Int32
+		noData := startNoDataDNSServer(t, &queries)
+		normal, cleanup := startNormalDNSServer(t)
+		defer cleanup()
+
+		c := New(
+			WithServers([]DNSServer{
+				{Address: noData, Keyword: "internetpositif", QueryType: "A"},
+				{Address: normal, Keyword: "internetpositif", QueryType: "A"},
+			}),
+			WithRequireAnswer(true),
+		)
+
+		e, err := c.Explain(context.Background(), "example.com")
+		require.NoError(t, err)
+		require.Len(t, e.Steps, 2)
+		assert.ErrorIs(t, e.Steps[0].Error, ErrNoAnswer)
+		assert.Equal(t, normal, e.Server)
+	})
+}
@@ -6,6 +6,13 @@
 package nawala
 
 import (
+	"crypto/sha256"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/miekg/dns"
@@ -17,29 +24,105 @@ type Option func(*Checker)
 // WithServers replaces all configured DNS servers.
 // This overrides the default Nawala DNS servers.
 // If multiple servers with identical configurations (Address, Keyword, and QueryType) are provided, only the first occurrence is kept.
+// Its [DNSServer.Tags] are merged with those of the dropped duplicates, so the
+// same resolver can be listed once per role.
+//
+// Addresses are compared in their normalized form (see [DNSServer.Address]),
+// so "8.8.8.8:53" and "8.8.8.8" are the same server.
 func WithServers(servers []DNSServer) Option {
 	return func(c *Checker) {
-		if len(servers) == 0 {
-			c.servers = servers
-			return
-		}
+		c.servers = dedupServers(trimServers(servers))
+	}
+}
 
-		type serverKey struct {
-			Address   string
-			Keyword   string
-			QueryType string
+// dedupServers returns servers with duplicate configurations (identical
+// Address, Keyword, and QueryType) removed, keeping the first occurrence
+// with the tags of its duplicates appended to its own, in order and without
+// repeats. Addresses are compared in their normalized form.
+// An empty input is returned unchanged.
+func dedupServers(servers []DNSServer) []DNSServer {
+	if len(servers) == 0 {
+		return servers
+	}
+
+	type serverKey struct {
+		Address   string
+		Keyword   string
+		QueryType string
+	}
+	seen := make(map[serverKey]int, len(servers)) // index into deduped
+	deduped := make([]DNSServer, 0, len(servers))
+
+	for _, s := range servers {
+		key := serverKey{Address: normalizeAddress(s.Address), Keyword: s.Keyword, QueryType: s.QueryType}
+		i, ok := seen[key]
+		if !ok {
+			seen[key] = len(deduped)
+			deduped = append(deduped, s)
+			continue
 		}
-		seen := make(map[serverKey]struct{}, len(servers))
-		deduped := make([]DNSServer, 0, len(servers))
-
-		for _, s := range servers {
-			key := serverKey(s)
-			if _, ok := seen[key]; !ok {
-				seen[key] = struct{}{}
-				deduped = append(deduped, s)
+
+		kept := &deduped[i]
+		for _, tag := range s.Tags {
+			if !slices.Contains(kept.Tags, tag) {
+				// Clip so the caller's backing array is never written to.
+				kept.Tags = append(slices.Clip(kept.Tags), tag)
 			}
 		}
-		c.servers = deduped
+	}
+	return deduped
+}
+
+// ReplaceServers atomically replaces the entire server set of a running
+// [Checker]. It is the runtime equivalent of [WithServers]: servers not in
+// the new set are dropped and the new ones are installed in a single locked
+// operation, so there is never a window with no servers configured (as there
+// would be with [Checker.DeleteServers] followed by [Checker.SetServers]).
+//
+// Duplicate configurations are removed exactly as [WithServers] does. The
+// slice is copied, so the caller may reuse it afterwards. Passing an empty
+// slice clears all servers, after which checks return [ErrNoDNSServers].
+//
+// It is safe to call concurrently with [Checker.Check], [Checker.CheckOne],
+// and [Checker.DNSStatus]; in-flight queries keep their own snapshot.
+func (c *Checker) ReplaceServers(servers []DNSServer) {
+	replaced := dedupServers(trimServers(servers))
+
+	c.mutateServers(func() {
+		c.servers = replaced
+		c.trackServersLocked(replaced, false)
+	})
+}
+
+// ResetServers restores the default Nawala DNS servers on a running
+// [Checker], discarding any changes made by [Checker.SetServers],
+// [Checker.DeleteServers], or [Checker.ReplaceServers]. It is the runtime
+// counterpart of constructing a fresh [New] without [WithServers], and the
+// swap happens in a single locked operation.
+//
+// It is safe to call concurrently with [Checker.Check], [Checker.CheckOne],
+// and [Checker.DNSStatus]; in-flight queries keep their own snapshot.
+func (c *Checker) ResetServers() {
+	servers := DefaultServers()
+
+	c.mutateServers(func() {
+		c.servers = servers
+		c.trackServersLocked(servers, true)
+	})
+}
+
+// WithDefaultKeyword sets a fallback blocking keyword for servers configured
+// with an empty [DNSServer.Keyword], such as servers bulk-loaded from a feed
+// that only lists addresses. Without it such servers cannot meaningfully
+// detect blocks, since an empty keyword carries no block indicator.
+//
+// The fallback is applied at query time to the checker's working copy of the
+// server list; [Checker.Servers] still reports the stored (empty) keyword.
+// An explicit per-server keyword always wins. Since the keyword is part of
+// the cache key, cached results reflect the effective keyword.
+func WithDefaultKeyword(kw string) Option {
+	return func(c *Checker) {
+		c.defaultKeyword = kw
 	}
 }
 
@@ -48,7 +131,9 @@ func WithServers(servers []DNSServer) Option {
 // and [Checker.DNSStatus].
 //
 // For each server provided, if a server with the same address is already
-// configured it is replaced in-place; otherwise it is appended.
+// configured it is replaced in-place; otherwise it is appended. Addresses
+// are compared in their normalized form (see [DNSServer.Address]), so
+// "8.8.8.8:53" replaces "8.8.8.8".
 // The change takes effect for all DNS queries that start after this call
 // returns — in-flight queries use their own snapshot of the server list.
 //
@@ -65,39 +150,244 @@ func (c *Checker) SetServers(servers ...DNSServer) {
 	if len(servers) == 0 {
 		return
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	for _, server := range servers {
-		updated := false
-		for i, s := range c.servers {
-			if s.Address == server.Address {
-				c.servers[i] = server
-				updated = true
-				break
+	servers = trimServers(servers)
+	c.mutateServers(func() {
+		for _, server := range servers {
+			updated := false
+			for i, s := range c.servers {
+				if sameAddress(s.Address, server.Address) {
+					c.servers[i] = server
+					updated = true
+					break
+				}
+			}
+			if !updated {
+				c.servers = append(c.servers, server)
 			}
 		}
-		if !updated {
-			c.servers = append(c.servers, server)
-		}
-	}
+		c.trackServersLocked(servers, false)
+	})
 }
 
 // HasServer returns true if a DNS server with the given address is
 // currently configured. It is safe to call concurrently with other
 // runtime configuration methods.
 //
-// The address should match exactly the address provided in [DNSServer.Address].
+// The address is normalized like [DNSServer.Address] before it is compared,
+// so "8.8.8.8:53" matches a server configured as "8.8.8.8".
 func (c *Checker) HasServer(address string) bool {
+	address = normalizeAddress(address)
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	for _, s := range c.servers {
-		if s.Address == address {
+		if normalizeAddress(s.Address) == address {
 			return true
 		}
 	}
 	return false
 }
 
+// WithNormalizer replaces the built-in domain normalizer (which lowercases
+// and trims whitespace) with fn. Use it to strip a trailing dot, collapse a
+// "www." prefix, or map homoglyphs before checking.
+//
+// The normalizer runs before [IsValidDomain], so fn must return a
+// validatable ASCII domain name; anything else is reported as
+// [ErrInvalidDomain]. The normalized value is also what appears in
+// [Result.Domain] and in cache keys.
+//
+// Passing nil is a no-op and the built-in normalizer is kept.
+func WithNormalizer(fn func(string) string) Option {
+	return func(c *Checker) {
+		if fn != nil {
+			c.normalizer = fn
+		}
+	}
+}
+
+// WithDomainLimits overrides the length limits used when validating domains
+// before they are checked: maxTotal caps the whole name (without the trailing
+// dot) and maxLabel caps each label. Names exceeding either limit are
+// reported as [ErrInvalidDomain] without sending a query.
+//
+// The defaults are the [RFC 1035] limits of 255 and 63, which the
+// package-level [IsValidDomain] always applies. A non-positive value keeps
+// the corresponding default.
+//
+// Tightening the limits suits internal naming schemes with shorter names.
+// Raising them only relaxes validation: the DNS wire format cannot encode
+// longer names, so such queries fail when the message is packed.
+//
+// [RFC 1035]: https://datatracker.ietf.org/doc/html/rfc1035
+func WithDomainLimits(maxTotal, maxLabel int) Option {
+	return func(c *Checker) {
+		if maxTotal > 0 {
+			c.domainRules.maxTotal = maxTotal
+		}
+		if maxLabel > 0 {
+			c.domainRules.maxLabel = maxLabel
+		}
+	}
+}
+
+// WithAllowSingleLabel makes the checker accept single-label hostnames such
+// as "localhost" or internal short names, which is useful when checking
+// against an intranet resolver. A single label must still satisfy the label
+// rules of [IsValidDomain], but not the TLD rules, so "host01" is accepted.
+//
+// The default is false. The package-level [IsValidDomain] always requires
+// at least two labels.
+func WithAllowSingleLabel(enabled bool) Option {
+	return func(c *Checker) {
+		c.domainRules.singleLabel = enabled
+	}
+}
+
+// WithAllowUnderscoreTLD makes the checker accept underscores in the last
+// label, such as "_dmarc"-style or "host._internal" names used by some
+// internal and test zones in split-horizon setups. The TLD must otherwise
+// still consist of letters (or be a Punycode "xn--" label) and be at least
+// two characters long.
+//
+// The default is false, per [RFC 1035]. The package-level [IsValidDomain]
+// always rejects underscores in the TLD.
+//
+// [RFC 1035]: https://datatracker.ietf.org/doc/html/rfc1035
+func WithAllowUnderscoreTLD(enabled bool) Option {
+	return func(c *Checker) {
+		c.domainRules.underscoreTLD = enabled
+	}
+}
+
+// WithFailOpen controls what a check reports when every server fails.
+//
+// By default the checker fails closed: the [Result] carries
+// [ErrAllDNSFailed], so a network outage is never mistaken for a verdict.
+// With fail-open enabled the Result instead reports the domain as not
+// blocked with a nil [Result.Error] and [Result.Degraded] set, which suits
+// deployments where treating an outage as a block is the worse failure.
+// Degraded results are never cached, and a cancelled caller context is still
+// reported as an error.
+//
+// Security note: fail-open lets anyone able to disrupt DNS traffic (by
+// dropping packets or taking the resolvers down) make every domain appear
+// unblocked. Callers relying on the verdict for enforcement or reporting
+// should inspect [Result.Degraded] rather than trusting [Result.Blocked]
+// alone.
+func WithFailOpen(enabled bool) Option {
+	return func(c *Checker) {
+		c.failOpen = enabled
+	}
+}
+
+// WithSystemResolverFallback makes a check that failed on every configured
+// server fall back to the system resolver ([net.DefaultResolver]) for a
+// best-effort verdict, for hosts where no filtering resolver is reachable.
+// The system resolver is typically the ISP's, which applies the same
+// filtering by redirecting blocked domains to a block page.
+//
+// The system resolver returns addresses only, so keyword and EDE detection
+// cannot work. The addresses are judged by the IP rules instead: a domain is
+// blocked when it resolves to one of blockIPs (the block pages of the
+// filter in use), or, as with [WithSinkholeDetection], only to private or
+// reserved addresses. The result has [Result.Server] set to
+// [SystemResolverServer] and [Result.Degraded] set, and is never cached.
+//
+// The lookup shares the per-domain budget of [WithPerDomainTimeout]. If it
+// fails too, the check fails as it would without the fallback (or fails open
+// with [WithFailOpen]), with the lookup error among the causes.
+//
+// Disabled by default.
+func WithSystemResolverFallback(enabled bool, blockIPs ...net.IP) Option {
+	return func(c *Checker) {
+		c.sysResolver = nil
+		c.sysBlockIPs = nil
+		if enabled {
+			c.sysResolver = net.DefaultResolver
+			c.sysBlockIPs = slices.Clone(blockIPs)
+		}
+	}
+}
+
+// WithFailoverPredicate sets fn to decide, after a server fails, whether the
+// check moves on to the next configured server. fn receives the error from
+// the failed server (after its retries) and returns true to fail over, or
+// false to stop, in which case the check ends as if every server had failed.
+//
+// Definitive answers ([ErrNXDOMAIN], [ErrQueryRejected]) never fail over and
+// are not passed to fn. Regardless of fn, failover also stops once the
+// context is done.
+//
+// The default fails over on every error except [context.Canceled] and
+// [context.DeadlineExceeded]. Passing nil restores the default.
+//
+// Example — stop at the first server that answers SERVFAIL:
+//
+//	c := nawala.New(nawala.WithFailoverPredicate(func(err error) bool {
+//	    return !errors.Is(err, nawala.ErrServerFailure)
+//	}))
+func WithFailoverPredicate(fn func(err error) bool) Option {
+	return func(c *Checker) {
+		if fn == nil {
+			fn = defaultFailover
+		}
+		c.failover = fn
+	}
+}
+
+// WithCircuitBreaker temporarily skips servers that keep failing, so checks
+// stop spending a timeout (and its retries) on a resolver that is down.
+//
+// Once a server has at least 5 queries in its window and its error rate
+// (1 - [Checker.ServerReliability]) exceeds threshold, it is left out of
+// the failover loop for cooldown. After the cooldown it is queried again:
+// a success puts it back with a clean slate, a failure skips it for another
+// cooldown. If every server is tripped they are all queried anyway, so the
+// breaker alone never fails a check.
+//
+// For example, skip a server for 30 seconds once more than half of its
+// recent queries failed:
+//
+//	c := nawala.New(nawala.WithCircuitBreaker(0.5, 30*time.Second))
+//
+// A threshold outside (0, 1) or a non-positive cooldown disables the
+// breaker, which is the default.
+func WithCircuitBreaker(threshold float64, cooldown time.Duration) Option {
+	return func(c *Checker) {
+		if threshold <= 0 || threshold >= 1 || cooldown <= 0 {
+			c.health.threshold, c.health.cooldown = 0, 0
+			return
+		}
+		c.health.threshold = threshold
+		c.health.cooldown = cooldown
+	}
+}
+
+// WithServerCooldown skips a server for cooldown once it has failed
+// failures times in a row, so a dead server stops eating into each check's
+// timeout budget. It is a simpler alternative to [WithCircuitBreaker],
+// which looks at the error rate over a window instead.
+//
+// Failures are counted like in [Checker.ServerReliability]: once per server
+// per check, after its retries. Any success resets the count. A server that
+// fails again right after its cooldown is skipped for another cooldown. If
+// every server is being skipped they are all queried anyway, so the
+// cooldown alone never fails a check.
+//
+//	c := nawala.New(nawala.WithServerCooldown(3, time.Minute))
+//
+// Non-positive failures or cooldown disable it, which is the default.
+func WithServerCooldown(failures int, cooldown time.Duration) Option {
+	return func(c *Checker) {
+		if failures <= 0 || cooldown <= 0 {
+			c.health.maxFailures, c.health.failCooldown = 0, 0
+			return
+		}
+		c.health.maxFailures = failures
+		c.health.failCooldown = cooldown
+	}
+}
+
 // WithTimeout sets the timeout for each DNS query.
 // The default is 5 seconds.
 //
@@ -109,6 +399,23 @@ func WithTimeout(d time.Duration) Option {
 	}
 }
 
+// WithPerDomainTimeout bounds the total time spent checking a single domain,
+// across every probe, backoff, and server failover. Each domain derives its
+// own deadline from the caller's context, so one hung lookup cannot consume
+// the whole deadline of a large [Checker.Check] batch.
+//
+// [WithTimeout] still bounds each individual DNS query; the effective limit
+// for a query is whichever of the two expires first. A per-domain timeout
+// shorter than the query timeout therefore also cuts individual queries
+// short. The caller's context deadline, if earlier, always wins.
+//
+// Values ≤ 0 disable the per-domain budget (the default).
+func WithPerDomainTimeout(d time.Duration) Option {
+	return func(c *Checker) {
+		c.domainTimeout = max(d, 0)
+	}
+}
+
 // WithMaxRetries sets the maximum number of retry attempts per DNS query.
 // The default is 2 retries (3 total attempts).
 func WithMaxRetries(n int) Option {
@@ -120,6 +427,263 @@ func WithMaxRetries(n int) Option {
 	}
 }
 
+// WithMaxTotalAttempts caps the number of DNS queries a single domain check
+// makes across all servers, retries and parallel probes included. Without
+// it a check may send up to (maxRetries+1) queries to each server; with it,
+// the check stops querying after n and returns the verdict it has, or an
+// [ErrAllDNSFailed] error if no server answered. Servers skipped for lack
+// of budget are reported with [ErrAttemptsExhausted], and cached results
+// are still used.
+//
+// Follow-up queries made while answering a single probe, such as
+// [WithMaxCNAMEDepth] chain lookups or [WithANYFallback] re-queries, are
+// not counted. Values ≤ 0 disable the cap (the default).
+func WithMaxTotalAttempts(n int) Option {
+	return func(c *Checker) {
+		c.maxTotalAttempts = max(n, 0)
+	}
+}
+
+// WithRaceServers queries every configured server at once instead of one
+// after another in failover order, trading extra queries for latency: a
+// down or slow primary no longer delays the check by its timeout and
+// retries before the next server is tried.
+//
+// A block wins: the first blocked result is returned at once and the
+// queries still in flight are cancelled. A clean answer, however, is only
+// returned once every server has answered or failed, so that a block seen
+// by a slower server is never overlooked; the first clean answer to arrive
+// is reported. A race therefore takes as long as its slowest server unless
+// one of them blocks. Definitive NXDOMAIN or rejection answers are treated
+// like clean ones, and the check fails only if every server does.
+//
+// Each server's result is cached under its own key as usual, and servers
+// with a cached result are not queried. [WithFailoverPredicate] does not
+// apply, since there is no failover. With a single server this option has
+// no effect. Disabled by default.
+func WithRaceServers(enabled bool) Option {
+	return func(c *Checker) {
+		c.raceServers = enabled
+	}
+}
+
+// WithProbeDelay inserts a pause of d between consecutive probes to the same
+// server when the previous probe succeeded. By default the multi-probe loop
+// sends such probes back-to-back, which a server with per-source rate
+// limiting may drop, producing inconsistent results.
+//
+// The pause honors context cancellation, in which case the verdict from the
+// probes already answered is returned. It complements the exponential
+// backoff, which only applies after a failed probe, and has no effect with
+// [WithParallelProbes].
+//
+// Values ≤ 0 disable the delay (the default).
+func WithProbeDelay(d time.Duration) Option {
+	return func(c *Checker) {
+		c.probeDelay = max(d, 0)
+	}
+}
+
+// WithParallelProbes controls whether the multi-probe logic fires all
+// probe attempts against a server concurrently instead of sequentially.
+//
+// By default the checker probes the same server maxRetries+1 times one after
+// another to catch intermittent blocking, which serializes latency. When
+// enabled, every probe is launched as its own goroutine: the first probe that
+// detects blocking wins and the remaining probes are cancelled, otherwise the
+// checker waits for all of them to return not-blocked.
+//
+// Parallel probes do not apply exponential backoff between attempts. The
+// default (false) keeps the sequential behavior with error backoff.
+func WithParallelProbes(enabled bool) Option {
+	return func(c *Checker) {
+		c.parallelProbes = enabled
+	}
+}
+
+// WithHTTPConfirmation enables an opt-in secondary confirmation for blocked
+// domains. When a domain is flagged as blocked, the checker sends an HTTP GET
+// to port 80 of each resolved IP, then an HTTPS GET to port 443 (with the Host
+// header, and the TLS server name, set to the domain) using client and looks
+// for known Indonesian block-page markers in the final URL, headers, and body.
+// The outcome is reported in [Result.HTTPConfirmed]. Certificates are verified
+// according to the client's transport; a block page served with a certificate
+// that is not valid for the domain is only seen over HTTP.
+//
+// The confirmation only runs for blocked verdicts that carry A/AAAA records
+// (e.g. Komdigi block-page IPs, or CNAME redirects whose landing page was
+// resolved by the server) and is bounded by the context passed to
+// [Checker.Check]. Configure client.Timeout to bound each request further.
+//
+// Passing nil is a no-op and the confirmation stays disabled.
+func WithHTTPConfirmation(client *http.Client) Option {
+	return func(c *Checker) {
+		if client != nil {
+			c.httpClient = client
+		}
+	}
+}
+
+// WithQueryHook registers a [QueryHook] invoked after every DNS query sent
+// during a domain check, including each retry probe. The hook receives the
+// caller's context, so request-scoped values (e.g. a trace or correlation ID)
+// can be used to correlate queries with the inbound request.
+//
+// Passing nil is a no-op.
+func WithQueryHook(hook QueryHook) Option {
+	return func(c *Checker) {
+		if hook != nil {
+			c.queryHook = hook
+		}
+	}
+}
+
+// WithServerChangeHook registers a [ServerChangeHook] invoked whenever the
+// server list changes at runtime through [Checker.SetServers],
+// [Checker.SetServersValidated], [Checker.ReplaceServers],
+// [Checker.ResetServers], [Checker.DeleteServers],
+// [Checker.DeleteServersFunc], or a [WithRemoteServerConfig] refresh. Calls
+// that leave the list unchanged (such as deleting an unknown address) do not
+// invoke it, nor does the configuration applied by [New].
+//
+// Passing nil is a no-op.
+func WithServerChangeHook(hook ServerChangeHook) Option {
+	return func(c *Checker) {
+		if hook != nil {
+			c.serverHook = hook
+		}
+	}
+}
+
+// WithStrictMatch makes keyword matching consider only the data portion of
+// each DNS record (CNAME target, A/AAAA address, TXT strings, OPT options)
+// instead of its full string representation.
+//
+// The default broad match stringifies the whole record, including the owner
+// name, TTL, class, and type, so a short keyword like "ns" matches every NS
+// header and a keyword equal to the queried domain matches its own owner
+// name. Strict matching avoids those false positives.
+//
+// This sets the default for servers whose [DNSServer.MatchScope] is empty;
+// an explicit per-server MatchScope always wins.
+func WithStrictMatch(enabled bool) Option {
+	return func(c *Checker) {
+		c.strictMatch = enabled
+	}
+}
+
+// WithMatchMode sets how keywords are compared with DNS records:
+// [MatchModeSubstring] (the default) matches any substring, while
+// [MatchModeLabel] only matches the keyword as whole DNS labels. Label
+// matching keeps a short keyword such as "positif" from hitting unrelated
+// names like "positifity.com" while still catching "internetpositif.id"
+// with the keyword "internetpositif".
+//
+// This sets the default for servers whose [DNSServer.MatchMode] is empty;
+// an explicit per-server MatchMode always wins. Like the per-server field,
+// mode is case-insensitive. Invalid values are ignored and the current
+// default is kept.
+func WithMatchMode(mode string) Option {
+	return func(c *Checker) {
+		if isMatchMode(mode) {
+			c.defaultMode = strings.ToLower(mode)
+		}
+	}
+}
+
+// WithSinkholeDetection flags a domain as blocked, with
+// [BlockReasonSinkhole], when it resolves only to loopback (127.0.0.0/8,
+// ::1), unspecified (0.0.0.0, ::), private (RFC 1918, fc00::/7),
+// link-local, multicast, or otherwise reserved addresses. Many filters
+// block this way, sinkholing the domain instead of redirecting it, so
+// keyword matching alone misses them.
+//
+// Only public domains are judged: names under special-use TLDs such as
+// "local", "internal", or "arpa" legitimately resolve to private space and
+// are left alone. A domain with at least one public address is not flagged.
+// Keyword and block-IP matches take precedence. Disabled by default, since
+// split-horizon setups may resolve public names to private addresses.
+func WithSinkholeDetection(enabled bool) Option {
+	return func(c *Checker) {
+		c.sinkhole = enabled
+	}
+}
+
+// WithAllMatches makes blocked results list every block indicator in the
+// response in [Result.Signals] instead of stopping at the first one: the
+// keyword in each section it appears in, matched sinkhole addresses, and
+// filtering EDE codes. This gives a complete forensic picture and helps
+// tell layered filtering apart, such as a Nawala CNAME redirect combined
+// with a Komdigi EDE on the same domain.
+//
+// The verdict, [Result.BlockReason], and [Result.MatchedSection] are the
+// same either way. Disabled by default, since it scans the whole response
+// even after a match.
+func WithAllMatches(enabled bool) Option {
+	return func(c *Checker) {
+		c.allMatches = enabled
+	}
+}
+
+// WithMaxCNAMEDepth makes the checker follow CNAME chains that a response
+// leaves unresolved, so a landing-page keyword several hops deep is still
+// caught (domain → CDN → internetpositif.id). Keywords are always matched
+// against every record in a response, so chains contained in one response
+// need no option; this covers responses that stop at a CNAME whose target
+// they do not resolve.
+//
+// When a response is not blocked and its CNAME chain ends at such a
+// target, the same server is queried for the target, up to n follow-up
+// queries per probe. The first follow-up judged blocked makes the domain
+// blocked, reported with the follow-up's details and the combined latency.
+// A chain that loops back on a name already seen stops the follow-up, as
+// does a failed query. Follow-ups are reported to the [WithQueryHook] hook
+// but are not retried, and [Checker.Explain] does not perform them.
+//
+// The default of 0 disables following; negative values are treated as 0.
+func WithMaxCNAMEDepth(n int) Option {
+	return func(c *Checker) {
+		c.cnameDepth = max(n, 0)
+	}
+}
+
+// WithRequireAnswer makes a NODATA response a failure instead of a
+// verdict. A resolver answers NODATA (rcode NOERROR with no record of the
+// queried type) when the name exists but has no such record; for an A
+// check that says nothing about whether the domain is reachable, yet by
+// default it is reported as not blocked.
+//
+// When enabled, a NODATA response that is not judged blocked fails the
+// probe with [ErrNoAnswer]: it is retried, then failed over to the next
+// server, and if every server answers the same way [Result.Error] matches
+// both [ErrAllDNSFailed] and [ErrNoAnswer]. A CNAME chain that never
+// reaches a record of the queried type also counts as NODATA. Blocked
+// responses without answers, such as an EDE-only block, are unaffected,
+// and the server still counts as healthy for [Checker.ServerReliability].
+//
+// Disabled by default.
+func WithRequireAnswer(enabled bool) Option {
+	return func(c *Checker) {
+		c.requireAnswer = enabled
+	}
+}
+
+// WithTruncatedKeywordSafety makes keyword matching also scan the
+// concatenation of each record's segments: the character-strings of a TXT
+// record, and the EDE texts (and other options) of an OPT record. A keyword
+// that a server splits across two segments, such as an EDE EXTRA-TEXT spread
+// over two options, is otherwise missed because each segment is matched on
+// its own.
+//
+// The concatenated match is additional, so enabling it never loses a match.
+// It can only add false positives when the end of one segment and the start
+// of the next happen to spell the keyword. The default is false.
+func WithTruncatedKeywordSafety(enabled bool) Option {
+	return func(c *Checker) {
+		c.joinSegments = enabled
+	}
+}
+
 // WithCache sets a custom [Cache] implementation.
 // By default, the checker uses an in-memory cache with a 5-minute TTL.
 //
@@ -140,6 +704,108 @@ func WithCacheTTL(d time.Duration) Option {
 	}
 }
 
+// WithStaleWhileRevalidate splits the built-in cache's TTL into a soft and a
+// hard limit. Entries are fresh for soft, the same as [WithCacheTTL]; from
+// then until hard they are still served, with [Result.Stale] set, while a
+// background check refreshes them. Past hard they are dropped and the next
+// check queries the servers as usual. This keeps hot domains answered from
+// memory without ever blocking on an expiry.
+//
+// Only one refresh runs per cache entry at a time. Refreshes skip the cache
+// lookup, keep the values of the triggering context but not its cancellation,
+// and are waited for by [Checker.Close].
+//
+// This overrides [WithCacheTTL] and has no effect if a custom cache is set
+// via [WithCache]. A hard limit not greater than soft disables stale serving.
+func WithStaleWhileRevalidate(soft, hard time.Duration) Option {
+	return func(c *Checker) {
+		c.cacheTTL = soft
+		c.staleWindow = max(hard-soft, 0)
+	}
+}
+
+// WithCacheCompression asks the cache backend to gzip the results it stores.
+// It applies to backends set via [WithCache] that serialize results and
+// implement the optional method
+//
+//	SetCompression(enabled bool)
+//
+// typically by passing the flag to [EncodeResult] on Set and decoding with
+// [DecodeResult] on Get. Compression pays off for persistent or remote
+// caches (e.g. Redis, files) holding many or large entries.
+//
+// It is a no-op for the built-in in-memory cache, which stores results
+// unserialized, and for backends without SetCompression.
+func WithCacheCompression(enabled bool) Option {
+	return func(c *Checker) {
+		c.cacheCompress = &enabled
+	}
+}
+
+// WithClock replaces the time source used for the built-in cache's
+// expiration, for the pause between probes (retry backoff and
+// [WithProbeDelay]), for [WithCircuitBreaker] cooldowns, and for
+// [ServerInfo.AddedAt]. It exists for tests: a fake [Clock] whose Now can
+// be advanced by hand, and whose After fires immediately, exercises TTL and
+// backoff logic without sleeping. Latency measurement, connection pool idle
+// eviction, and query timeouts keep using real time.
+//
+// Passing nil is a no-op.
+func WithClock(clock Clock) Option {
+	return func(c *Checker) {
+		if clock != nil {
+			c.clock = clock
+		}
+	}
+}
+
+// WithCacheShards sets the number of independently locked shards in the
+// built-in in-memory cache. Keys are hashed to a shard, so concurrent checks
+// mostly lock different mutexes instead of serializing on one; raise it when
+// running with a high [WithConcurrency]. Values ≤ 0 are treated as 1, which
+// restores a single-lock cache.
+//
+// This has no effect if a custom cache is set via [WithCache].
+// The default is 16.
+func WithCacheShards(n int) Option {
+	return func(c *Checker) {
+		c.cacheShards = max(n, 1)
+	}
+}
+
+// WithCacheMinTTL sets a lower bound for the expiration of each cache entry.
+// A TTL below d (for example a TTL-0 answer from a misbehaving resolver) is
+// raised to d before the entry is stored.
+//
+// Setting either this or [WithCacheMaxTTL] makes cache entries honor the
+// TTL of the DNS response ([Result.TTL]) instead of the fixed
+// [WithCacheTTL], with the bounds applied by the checker when computing the
+// per-entry expiration. This only takes effect for caches that accept a
+// per-entry TTL through a SetWithTTL(key string, val Result, ttl
+// time.Duration) method, such as the built-in in-memory cache. For other
+// caches it is a no-op.
+//
+// Values ≤ 0 leave the lower bound unset (the default).
+func WithCacheMinTTL(d time.Duration) Option {
+	return func(c *Checker) {
+		c.cacheMinTTL = max(d, 0)
+	}
+}
+
+// WithCacheMaxTTL sets an upper bound for the expiration of each cache entry.
+// A TTL above d (for example an absurd 604800-second answer) is lowered to d
+// before the entry is stored.
+//
+// Like [WithCacheMinTTL], it makes entries honor the response TTL and only
+// affects caches that accept a per-entry TTL. Without a lower bound, a
+// TTL-0 answer is therefore not cached. Values ≤ 0 leave the upper bound
+// unset (the default).
+func WithCacheMaxTTL(d time.Duration) Option {
+	return func(c *Checker) {
+		c.cacheMaxTTL = max(d, 0)
+	}
+}
+
 // WithConcurrency sets the maximum number of concurrent DNS checks.
 // The default is 100.
 func WithConcurrency(n int) Option {
@@ -150,6 +816,20 @@ func WithConcurrency(n int) Option {
 	}
 }
 
+// WithMaxDomains caps the number of domains a single [Checker.Check] or
+// [Checker.CheckWithTags] call accepts. A call exceeding the cap returns
+// [ErrTooManyDomains] before allocating results or sending any query, which
+// guards services that pass untrusted list sizes through. Use
+// [Checker.CheckStream] to process arbitrarily large lists in constant
+// memory instead.
+//
+// Values ≤ 0 disable the cap (the default).
+func WithMaxDomains(n int) Option {
+	return func(c *Checker) {
+		c.maxDomains = max(n, 0)
+	}
+}
+
 // WithDNSClient sets a custom [dns.Client] for all DNS operations.
 // This allows full control over the transport configuration, including:
 //
@@ -170,11 +850,31 @@ func WithConcurrency(n int) Option {
 func WithDNSClient(client *dns.Client) Option {
 	return func(c *Checker) {
 		if client != nil {
-			c.dnsClient = client
+			c.dnsClient.Store(client)
 		}
 	}
 }
 
+// SetDNSClient replaces the [dns.Client] used for queries at runtime, e.g.
+// to rotate to a DNS-over-TLS client during a maintenance window without
+// rebuilding the checker. It is the runtime counterpart of [WithDNSClient]
+// and is safe for concurrent use with checks: the client is swapped
+// atomically, so queries read it without waiting on a lock.
+//
+// The change takes effect for all DNS queries that start after this call
+// returns — in-flight queries finish with the client they started with.
+// Connection pools created by [WithKeepAlive] or [WithConnectionPool] keep
+// the client they were built with, so servers served from a pool are not
+// affected.
+//
+// Passing nil is a no-op.
+func (c *Checker) SetDNSClient(client *dns.Client) {
+	if client == nil {
+		return
+	}
+	c.dnsClient.Store(client)
+}
+
 // WithEDNS0Size sets the EDNS0 UDP buffer size.
 // The default is 1232 bytes, which is the recommended size to prevent
 // IP fragmentation over UDP.
@@ -188,6 +888,114 @@ func WithEDNS0Size(size uint16) Option {
 	}
 }
 
+// WithDNSCookie attaches a DNS Cookie ([RFC 7873]) to every outgoing query
+// and verifies the server cookie echoed in each response, reporting the
+// outcome in [Result.CookieVerified].
+//
+// On plain UDP, cookies protect against off-path spoofing: a forged answer
+// cannot echo the random client cookie, so a response without a valid server
+// cookie from a resolver known to support cookies hints at interception.
+// A random client cookie is generated once per [Checker].
+//
+// The cookie is attached through the same hook as [WithEDNS0Options].
+//
+// [RFC 7873]: https://datatracker.ietf.org/doc/html/rfc7873
+func WithDNSCookie(enabled bool) Option {
+	return func(c *Checker) {
+		c.dnsCookie = enabled
+	}
+}
+
+// WithEDNS0Options registers fn to customize the EDNS0 OPT record of every
+// outgoing query, including [Checker.DNSStatus] health probes. fn runs after
+// the record has been built with the configured [WithEDNS0Size] and DO=false,
+// so it can set the DO bit, version, or extended Rcode, or attach options
+// such as Client Subnet, cookies, or padding in one place:
+//
+//	c := nawala.New(nawala.WithEDNS0Options(func(opt *dns.OPT) {
+//	    opt.SetDo()
+//	    opt.Option = append(opt.Option, &dns.EDNS0_PADDING{Padding: make([]byte, 16)})
+//	}))
+//
+// Multiple calls accumulate and run in order. fn may be called concurrently
+// and must not retain opt, which is recycled once the query completes.
+// A nil fn is ignored.
+func WithEDNS0Options(fn func(opt *dns.OPT)) Option {
+	return func(c *Checker) {
+		if fn != nil {
+			c.ednsOptions = append(c.ednsOptions, fn)
+		}
+	}
+}
+
+// WithRecursionDesired controls the RD (Recursion Desired) bit on outgoing
+// DNS queries. The default is true, which is what recursive resolvers such
+// as the default Nawala servers expect.
+//
+// Set it to false when querying an authoritative server directly, to avoid
+// triggering unwanted upstream recursion. This applies to both domain checks
+// and [Checker.DNSStatus] health probes.
+func WithRecursionDesired(enabled bool) Option {
+	return func(c *Checker) {
+		c.recursion = enabled
+	}
+}
+
+// WithResponseValidator registers a custom block-detection rule that runs
+// on every successful DNS response before the built-in keyword check. See
+// [ResponseValidator] for the contract and an example.
+//
+// Keyword matching stays the default: when no validator is set, or when it
+// returns stop=false, responses are judged by [DNSServer.Keyword] as usual.
+// A nil validator disables the hook.
+func WithResponseValidator(fn ResponseValidator) Option {
+	return func(c *Checker) {
+		c.validator = fn
+	}
+}
+
+// WithEnricher registers fn to attach external data, such as RDAP or GeoIP
+// lookups, to each verdict through [Result.Metadata]. See [Enricher] for
+// when it runs and how errors are handled.
+//
+// Multiple calls accumulate and the enrichers run in order, all sharing
+// the same result. A nil fn is ignored.
+func WithEnricher(fn Enricher) Option {
+	return func(c *Checker) {
+		if fn != nil {
+			c.enrichers = append(c.enrichers, fn)
+		}
+	}
+}
+
+// WithANYFallback controls what happens when a server declines an ANY
+// query, as many modern resolvers do per RFC 8482 — either by answering
+// REFUSED (or another rejection code) or with a minimal HINFO "RFC8482"
+// record. When enabled (the default), such queries are retried as A and
+// AAAA and the two responses are merged for keyword scanning.
+//
+// Disable it to surface the refusal as-is (an [ErrQueryRejected] error, or
+// the HINFO answer).
+func WithANYFallback(enabled bool) Option {
+	return func(c *Checker) {
+		c.anyFallback = enabled
+	}
+}
+
+// WithMaxResponseSize caps the size, in bytes, of DNS responses the checker
+// accepts. Responses whose wire length exceeds n are rejected with an error
+// wrapping [ErrResponseTooLarge] and treated like any other query error
+// (retried, then failed over to the next server).
+//
+// This hardens deployments that query untrusted resolvers, particularly over
+// TCP where a malicious or broken server could send an oversized response.
+// Values ≤ 0 disable the limit (the default).
+func WithMaxResponseSize(n int) Option {
+	return func(c *Checker) {
+		c.maxRespSize = max(n, 0)
+	}
+}
+
 // WithProtocol sets the DNS transport protocol used by the default DNS client.
 // The default is "udp".
 //
@@ -212,6 +1020,55 @@ func WithProtocol(net string) Option {
 	}
 }
 
+// NetworkPreference selects the IP family used to reach DNS servers; see
+// [WithNetworkPreference].
+type NetworkPreference int
+
+// Network preferences for [WithNetworkPreference].
+const (
+	// NetworkAuto lets the dialer pick the family from the server address
+	// (the default).
+	NetworkAuto NetworkPreference = iota
+
+	// PreferIPv4 dials servers over IPv4 only ("udp4", "tcp4").
+	PreferIPv4
+
+	// PreferIPv6 dials servers over IPv6 only ("udp6", "tcp6").
+	PreferIPv6
+)
+
+// WithNetworkPreference pins the IP family used by the default DNS client,
+// for dual-stack hosts where only one family routes cleanly to the servers.
+// It maps the transport chosen by [WithProtocol] to its family-specific
+// network: "udp4"/"udp6", "tcp4"/"tcp6", or "tcp4-tls"/"tcp6-tls".
+//
+// The family is enforced, not merely preferred: a server given as a hostname
+// that has no address in that family, or as an IP literal of the other
+// family, fails to dial. Unknown values are ignored and [NetworkAuto] is
+// kept. This option has no effect if a custom DNS client is set via
+// [WithDNSClient].
+func WithNetworkPreference(pref NetworkPreference) Option {
+	return func(c *Checker) {
+		switch pref {
+		case NetworkAuto, PreferIPv4, PreferIPv6:
+			c.network = pref
+		}
+	}
+}
+
+// family returns the suffix appended to a network name for p: "4", "6",
+// or "" for [NetworkAuto].
+func (p NetworkPreference) family() string {
+	switch p {
+	case PreferIPv4:
+		return "4"
+	case PreferIPv6:
+		return "6"
+	default:
+		return ""
+	}
+}
+
 // WithTLSServerName overrides the TLS server name (SNI) used when connecting
 // to a DoT (DNS-over-TLS) server.
 //
@@ -252,6 +1109,75 @@ func WithTLSSkipVerify() Option {
 	}
 }
 
+// WithDoT switches the default DNS client to DNS over TLS ([RFC 7858]) in one
+// step. It is shorthand for [WithProtocol]("tcp-tls") combined with
+// [WithTLSServerName](serverName) and, when insecure is true,
+// [WithTLSSkipVerify]:
+//
+//	c := nawala.New(
+//	    nawala.WithServers([]nawala.DNSServer{
+//	        {Address: "203.0.113.53", Keyword: "internetpositif", QueryType: "A"},
+//	    }),
+//	    nawala.WithDoT("dns.example.com", false),
+//	)
+//
+// serverName is the TLS identity the server certificate is verified against
+// (and sent as SNI); leave it empty to verify against the server address.
+// Servers given without a port are dialled on 853, the DoT port.
+//
+// insecure disables certificate verification and carries the same warning
+// as [WithTLSSkipVerify]: only use it against a self-signed test server.
+// WithDoT sets both TLS settings, replacing earlier WithTLSServerName and
+// WithTLSSkipVerify options. Like them, it has no effect when a custom
+// client is set via [WithDNSClient].
+//
+// [RFC 7858]: https://www.rfc-editor.org/rfc/rfc7858.html
+func WithDoT(serverName string, insecure bool) Option {
+	return func(c *Checker) {
+		c.dnsProtocol = "tcp-tls"
+		c.tlsServerName = serverName
+		c.tlsSkipVerify = insecure
+	}
+}
+
+// WithDoTPin pins the certificate of DoT servers: a TLS handshake succeeds
+// only if the leaf certificate presented by the server has the given SHA-256
+// fingerprint, computed over its DER encoding. This detects interception of
+// the encrypted channel itself, e.g. by a middlebox holding a certificate
+// from a CA the host trusts. Obtain the fingerprint with:
+//
+//	openssl s_client -connect 203.0.113.53:853 </dev/null 2>/dev/null |
+//	    openssl x509 -outform DER | sha256sum
+//
+// A mismatch fails the connection with an error wrapping
+// [ErrCertificatePinMismatch], which is retried and failed over like any
+// other connection error. The pin is checked in addition to the usual
+// certificate verification; combined with insecure [WithDoT] (or
+// [WithTLSSkipVerify]) it replaces it, which suits self-signed servers.
+//
+// Only applies when the transport is "tcp-tls" (see [WithDoT]). Has no
+// effect when a custom client is set via [WithDNSClient].
+func WithDoTPin(fingerprint [32]byte) Option {
+	return func(c *Checker) {
+		c.dotPin = &fingerprint
+	}
+}
+
+// verifyPin returns a [tls.Config.VerifyConnection] callback enforcing the
+// [WithDoTPin] fingerprint. VerifyConnection rather than
+// VerifyPeerCertificate is used because it also runs on resumed sessions.
+func verifyPin(pin [32]byte) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return fmt.Errorf("%w: no certificate presented", ErrCertificatePinMismatch)
+		}
+		if got := sha256.Sum256(cs.PeerCertificates[0].Raw); got != pin {
+			return fmt.Errorf("%w: got %x", ErrCertificatePinMismatch, got)
+		}
+		return nil
+	}
+}
+
 // WithDigests enables digest-based cache keys using the provided hash function.
 // When set, the raw cache key components (domain, server address, keyword, and
 // query type) are concatenated and passed to hash, and the returned string
@@ -344,9 +1270,37 @@ func WithKeepAlive(poolSize int) Option {
 	}
 }
 
+// WithConnectionPool is [WithKeepAlive] with an idle timeout: it keeps up to
+// size persistent connections per server for the "tcp" and "tcp-tls"
+// protocols, and evicts connections that have sat idle in the pool for longer
+// than idleTimeout instead of reusing them.
+//
+// This is aimed at high-volume DNS-over-TLS checks, where the TLS handshake
+// dominates per-query latency. Pick an idleTimeout slightly below the
+// server's own idle timeout so that connections are retired before the
+// server closes them; values ≤ 0 keep idle connections indefinitely, exactly
+// like [WithKeepAlive].
+//
+//	c := nawala.New(
+//	    nawala.WithProtocol("tcp-tls"),
+//	    nawala.WithConnectionPool(5, 30*time.Second),
+//	)
+//	defer c.Close()
+//
+// Eviction happens lazily when a connection is taken from the pool; no
+// background goroutine is started.
+func WithConnectionPool(size int, idleTimeout time.Duration) Option {
+	return func(c *Checker) {
+		c.keepAlive = true
+		c.poolSize = size
+		c.idleTimeout = max(idleTimeout, 0)
+	}
+}
+
 // DeleteServers removes one or more servers from the checker's active
 // configuration at runtime. It is concurrency-safe and will safely remove
-// servers identified by their Address field.
+// servers identified by their Address field. The addresses are normalized
+// like [DNSServer.Address] before they are compared.
 //
 // Passing zero servers or non-existent addresses is a no-op.
 func (c *Checker) DeleteServers(addresses ...string) {
@@ -354,19 +1308,51 @@ func (c *Checker) DeleteServers(addresses ...string) {
 		return
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	toDelete := make(map[string]struct{}, len(addresses))
 	for _, addr := range addresses {
-		toDelete[addr] = struct{}{}
+		toDelete[normalizeAddress(addr)] = struct{}{}
 	}
 
+	c.mutateServers(func() {
+		c.deleteServersLocked(func(s DNSServer) bool {
+			_, deleteMe := toDelete[normalizeAddress(s.Address)]
+			return deleteMe
+		})
+	})
+}
+
+// DeleteServersFunc removes every server for which pred returns true from
+// the checker's active configuration at runtime. It is concurrency-safe and
+// holds the same lock as [Checker.DeleteServers], so pred observes a
+// consistent view of the server list.
+//
+// Example — drop every server using a stale keyword:
+//
+//	c.DeleteServersFunc(func(s nawala.DNSServer) bool {
+//	    return s.Keyword == "legacy-keyword"
+//	})
+//
+// pred must not call other [Checker] methods that acquire the server lock.
+// Passing a nil predicate is a no-op.
+func (c *Checker) DeleteServersFunc(pred func(DNSServer) bool) {
+	if pred == nil {
+		return
+	}
+
+	c.mutateServers(func() {
+		c.deleteServersLocked(pred)
+	})
+}
+
+// deleteServersLocked rebuilds c.servers without the servers matching pred.
+// The caller must hold c.mu for writing.
+func (c *Checker) deleteServersLocked(pred func(DNSServer) bool) {
 	var newServers []DNSServer
 	for _, s := range c.servers {
-		if _, deleteMe := toDelete[s.Address]; !deleteMe {
+		if !pred(s) {
 			newServers = append(newServers, s)
 		}
 	}
 	c.servers = newServers
+	c.trackServersLocked(nil, false)
 }
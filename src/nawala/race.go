@@ -0,0 +1,116 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// checkRace is the concurrent counterpart of the failover loop in
+// [Checker.checkSingle], used with [WithRaceServers]: every server without
+// a cached result is queried at once.
+//
+// The first blocked result wins and the queries still in flight are
+// cancelled; they are waited for before returning, so that no query of the
+// check outlives it (and the [Checker.Shutdown] that counts it). Otherwise
+// it waits for every server and reports the first answer to arrive (a clean
+// result, or a definitive NXDOMAIN or rejection), so that a slower server's
+// block is never overlooked. Each server's result is cached under its own
+// key, as in the failover loop.
+func (c *Checker) checkRace(ctx, parent context.Context, domain string, servers []DNSServer, retries int, budget *attemptBudget, opts checkOptions) Result {
+	// Deferred first so it runs last, after the losers are cancelled.
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	raceCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type raceResult struct {
+		srv    DNSServer
+		key    string
+		result Result
+		err    error
+	}
+
+	// The first non-blocked answer, cached ones first.
+	var answer *Result
+
+	// Buffered so losers never block on send once the winner is found.
+	ch := make(chan raceResult, len(servers))
+	pending := 0
+	for _, srv := range servers {
+		qtype := parseQueryType(srv.QueryType)
+		key := c.cacheKey(domain, srv, qtype)
+
+		if c.cache != nil && !opts.fresh {
+			if cached, ok := c.cache.Get(key); ok {
+				if cached.Stale {
+					c.revalidate(ctx, key, domain, opts)
+				}
+				if cached.Blocked {
+					return cached
+				}
+				if answer == nil {
+					answer = &cached
+				}
+				continue
+			}
+		}
+
+		pending++
+		wg.Go(func() {
+			result, err := c.queryWithRetries(raceCtx, domain, srv, qtype, retries, budget)
+			ch <- raceResult{srv: srv, key: key, result: result, err: err}
+		})
+	}
+
+	var (
+		serverErrs []error // per-server errors, in arrival order
+		lastServer string  // address of the last server that failed
+	)
+	for range pending {
+		rr := <-ch
+		if rr.err != nil {
+			if errors.Is(rr.err, ErrNXDOMAIN) || errors.Is(rr.err, ErrQueryRejected) {
+				if answer == nil {
+					answer = &Result{Domain: domain, Server: rr.srv.Address, Error: rr.err}
+				}
+				continue
+			}
+			serverErrs = append(serverErrs, fmt.Errorf("%s: %w", rr.srv.Address, rr.err))
+			lastServer = rr.srv.Address
+			continue
+		}
+
+		if rr.result.Blocked {
+			cancel()
+			result := rr.result
+			if c.httpClient != nil {
+				result.HTTPConfirmed = confirmBlockPage(ctx, c.httpClient, domain, rr.srv.Keyword, result.ResolvedIPs)
+			}
+			if c.enrich(ctx, &result) {
+				c.storeResult(rr.key, result)
+			}
+			return result
+		}
+
+		result := rr.result
+		if c.enrich(ctx, &result) {
+			c.storeResult(rr.key, result)
+		}
+		if answer == nil {
+			answer = &result
+		}
+	}
+
+	if answer != nil {
+		return *answer
+	}
+	return c.allFailed(ctx, parent, domain, lastServer, serverErrs)
+}
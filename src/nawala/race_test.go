@@ -0,0 +1,153 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startDelayedDNSServer starts a server answering after delay, with a
+// CNAME to internetpositif.id. when blocked, or an A record otherwise.
+func startDelayedDNSServer(t *testing.T, delay time.Duration, blocked bool) string {
+	t.Helper()
+	addr, cleanup := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		time.Sleep(delay)
+		m := new(dns.Msg)
+		m.SetReply(r)
+		hdr := dns.RR_Header{Name: r.Question[0].Name, Class: dns.ClassINET, Ttl: 60}
+		if blocked {
+			hdr.Rrtype = dns.TypeCNAME
+			m.Answer = append(m.Answer, &dns.CNAME{Hdr: hdr, Target: "internetpositif.id."})
+		} else {
+			hdr.Rrtype = dns.TypeA
+			m.Answer = append(m.Answer, &dns.A{Hdr: hdr, A: []byte{93, 184, 216, 34}})
+		}
+		_ = w.WriteMsg(m)
+	}))
+	t.Cleanup(cleanup)
+	return addr
+}
+
+func newRaceChecker(addrs ...string) *Checker {
+	servers := make([]DNSServer, len(addrs))
+	for i, addr := range addrs {
+		servers[i] = DNSServer{Address: addr, Keyword: "internetpositif", QueryType: "A"}
+	}
+	return New(
+		WithServers(servers),
+		WithMaxRetries(0),
+		WithTimeout(time.Second),
+		WithRaceServers(true),
+	)
+}
+
+func TestWithRaceServers(t *testing.T) {
+	t.Run("block wins over a faster clean answer", func(t *testing.T) {
+		fast := startDelayedDNSServer(t, 0, false)
+		slow := startDelayedDNSServer(t, 100*time.Millisecond, true)
+		c := newRaceChecker(fast, slow)
+
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		require.NoError(t, r.Error)
+		assert.True(t, r.Blocked)
+		assert.Equal(t, slow, r.Server)
+	})
+
+	t.Run("fastest clean answer", func(t *testing.T) {
+		slow := startDelayedDNSServer(t, 100*time.Millisecond, false)
+		fast := startDelayedDNSServer(t, 0, false)
+		c := newRaceChecker(slow, fast)
+
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		require.NoError(t, r.Error)
+		assert.False(t, r.Blocked)
+		assert.Equal(t, fast, r.Server)
+
+		// Both servers' results are cached under their own keys.
+		n, ok := c.CacheLen()
+		require.True(t, ok)
+		assert.Equal(t, 2, n)
+	})
+
+	t.Run("block does not wait for a hung server", func(t *testing.T) {
+		hung := startDelayedDNSServer(t, 1500*time.Millisecond, false)
+		blocking := startDelayedDNSServer(t, 0, true)
+		c := newRaceChecker(hung, blocking)
+
+		start := time.Now()
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		require.NoError(t, r.Error)
+		assert.True(t, r.Blocked)
+		assert.Less(t, time.Since(start), 500*time.Millisecond, "the hung primary must not delay the verdict")
+	})
+
+	t.Run("losers finish before return", func(t *testing.T) {
+		hung := startDelayedDNSServer(t, 1500*time.Millisecond, false)
+		blocking := startDelayedDNSServer(t, 0, true)
+
+		var (
+			mu     sync.Mutex
+			served []string
+		)
+		c := New(
+			WithServers([]DNSServer{
+				{Address: hung, Keyword: "internetpositif", QueryType: "A"},
+				{Address: blocking, Keyword: "internetpositif", QueryType: "A"},
+			}),
+			WithMaxRetries(0),
+			WithTimeout(time.Second),
+			WithRaceServers(true),
+			WithQueryHook(func(_ context.Context, ev QueryEvent) {
+				mu.Lock()
+				served = append(served, ev.Server)
+				mu.Unlock()
+			}),
+		)
+
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.True(t, r.Blocked)
+
+		// The cancelled query to the hung server has already completed, so
+		// Shutdown cannot race with it.
+		mu.Lock()
+		assert.ElementsMatch(t, []string{hung, blocking}, served)
+		mu.Unlock()
+		require.NoError(t, c.Shutdown(context.Background()))
+	})
+
+	t.Run("cached block", func(t *testing.T) {
+		fast := startDelayedDNSServer(t, 0, false)
+		blocking := startDelayedDNSServer(t, 0, true)
+		c := newRaceChecker(fast, blocking)
+
+		_, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.True(t, r.Blocked)
+		assert.Equal(t, blocking, r.Server)
+	})
+
+	t.Run("all fail", func(t *testing.T) {
+		c := newRaceChecker("127.0.0.1:1", "127.0.0.1:2")
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.ErrorIs(t, r.Error, ErrAllDNSFailed)
+		assert.Contains(t, r.Error.Error(), "127.0.0.1:1")
+		assert.Contains(t, r.Error.Error(), "127.0.0.1:2")
+	})
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxRemoteConfigSize caps the size of a remote server config document.
+const maxRemoteConfigSize = 1 << 20 // 1 MiB
+
+// remoteConfig holds the settings configured by [WithRemoteServerConfig].
+type remoteConfig struct {
+	url      string
+	client   *http.Client
+	interval time.Duration
+	onError  func(error) // set by WithRemoteConfigErrorHandler; nil drops errors
+
+	cancel context.CancelFunc // stops the refresh goroutine
+	done   chan struct{}      // closed when the refresh goroutine exits
+}
+
+// WithRemoteServerConfig keeps the server list in sync with a JSON document
+// hosted at url, for fleet-managed deployments. The document must be a JSON
+// array of [DNSServer] objects, as read by [ParseServers]:
+//
+//	[
+//	  {"address": "180.131.144.144", "keyword": "internetpositif", "query_type": "A"},
+//	  {"address": "180.131.145.145", "keyword": "internetpositif", "query_type": "A"}
+//	]
+//
+// When interval is positive, a background goroutine fetches the document
+// right after [New] returns and then on every tick, applying it with
+// [Checker.ReplaceServers]. Failed fetches and invalid documents (see
+// [Checker.SetServersValidated]) leave the running configuration untouched
+// and are reported to the [WithRemoteConfigErrorHandler] callback, if any.
+// The goroutine is stopped by [Checker.Close]. When interval is ≤ 0, no
+// goroutine is started and refreshes happen only through
+// [Checker.RefreshServers].
+//
+// If client is nil, [http.DefaultClient] is used.
+func WithRemoteServerConfig(url string, client *http.Client, interval time.Duration) Option {
+	return func(c *Checker) {
+		if client == nil {
+			client = http.DefaultClient
+		}
+		var onError func(error)
+		if c.remote != nil {
+			onError = c.remote.onError
+		}
+		c.remote = &remoteConfig{url: url, client: client, interval: interval, onError: onError}
+	}
+}
+
+// WithRemoteConfigErrorHandler sets a callback that receives the error of
+// every failed background refresh started by [WithRemoteServerConfig]. The
+// error wraps [ErrRemoteConfig]. Without a handler such failures are
+// dropped; the running configuration is kept either way.
+//
+//	nawala.WithRemoteConfigErrorHandler(func(err error) {
+//	    slog.Warn("server config refresh failed", "err", err)
+//	})
+//
+// fn is called from the refresh goroutine, one call at a time. It has no
+// effect on [Checker.RefreshServers], which returns its error.
+func WithRemoteConfigErrorHandler(fn func(err error)) Option {
+	return func(c *Checker) {
+		if c.remote == nil {
+			c.remote = &remoteConfig{}
+		}
+		c.remote.onError = fn
+	}
+}
+
+// RefreshServers fetches the remote server config configured by
+// [WithRemoteServerConfig] and applies it with [Checker.ReplaceServers].
+//
+// The document is validated before anything is applied: on any error —
+// network failure, non-200 status, malformed JSON, an empty list, or an
+// invalid server — the current configuration is kept and the returned error
+// wraps [ErrRemoteConfig].
+func (c *Checker) RefreshServers(ctx context.Context) error {
+	if c.remote == nil || c.remote.url == "" {
+		return fmt.Errorf("%w: no URL configured", ErrRemoteConfig)
+	}
+
+	servers, err := fetchServers(ctx, c.remote.client, c.remote.url)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRemoteConfig, err)
+	}
+
+	c.ReplaceServers(servers)
+	return nil
+}
+
+// startRemoteRefresh launches the periodic refresh goroutine when a
+// positive interval is configured.
+func (c *Checker) startRemoteRefresh() {
+	if c.remote == nil || c.remote.interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.remote.cancel = cancel
+	c.remote.done = make(chan struct{})
+
+	go func() {
+		defer close(c.remote.done)
+
+		ticker := time.NewTicker(c.remote.interval)
+		defer ticker.Stop()
+
+		for {
+			// Bound each fetch by the interval so a hung server cannot
+			// stack up refreshes.
+			fetchCtx, fetchCancel := context.WithTimeout(ctx, c.remote.interval)
+			if err := c.RefreshServers(fetchCtx); err != nil && ctx.Err() == nil && c.remote.onError != nil {
+				c.remote.onError(err)
+			}
+			fetchCancel()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// stopRemoteRefresh stops the periodic refresh goroutine, if running, and
+// waits for it to exit. It is safe to call more than once.
+func (c *Checker) stopRemoteRefresh() {
+	if c.remote == nil || c.remote.cancel == nil {
+		return
+	}
+	c.remote.cancel()
+	<-c.remote.done
+}
+
+// fetchServers downloads and parses a non-empty server list from url.
+func fetchServers(ctx context.Context, client *http.Client, url string) ([]DNSServer, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+
+	servers, err := ParseServers(io.LimitReader(resp.Body, maxRemoteConfigSize))
+	if err != nil {
+		return nil, err
+	}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("empty server list")
+	}
+	return servers, nil
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshServers(t *testing.T) {
+	var body atomic.Value
+	body.Store(`[{"address":"1.1.1.1","keyword":"blocked","query_type":"A"}]`)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body.Load().(string)))
+	}))
+	defer ts.Close()
+
+	c := New(WithRemoteServerConfig(ts.URL, ts.Client(), 0))
+	defer c.Close()
+	ctx := context.Background()
+
+	require.NoError(t, c.RefreshServers(ctx))
+	assert.Equal(t, []DNSServer{{Address: "1.1.1.1", Keyword: "blocked", QueryType: "A"}}, c.Servers())
+
+	// Every bad document is rejected without touching the running config.
+	for _, bad := range []string{
+		`not json`,
+		`[]`,
+		`[{"address":"8.8.8.8","keyword":"x","query_type":"AAA"}]`,
+	} {
+		body.Store(bad)
+		assert.ErrorIs(t, c.RefreshServers(ctx), ErrRemoteConfig, "body %q", bad)
+		assert.True(t, c.HasServer("1.1.1.1"), "body %q", bad)
+	}
+}
+
+func TestRefreshServersErrors(t *testing.T) {
+	ctx := context.Background()
+
+	// Not configured.
+	assert.ErrorIs(t, New().RefreshServers(ctx), ErrRemoteConfig)
+
+	// Non-200 status.
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	c := New(WithRemoteServerConfig(ts.URL, nil, 0))
+	err := c.RefreshServers(ctx)
+	assert.ErrorIs(t, err, ErrRemoteConfig)
+	assert.Contains(t, err.Error(), "404")
+	assert.True(t, c.HasServer("180.131.144.144"), "default servers are kept")
+}
+
+func TestRemoteServerConfigPeriodicRefresh(t *testing.T) {
+	var hits atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write([]byte(`[{"address":"9.9.9.9","keyword":"blocked","query_type":"A"}]`))
+	}))
+	defer ts.Close()
+
+	c := New(WithRemoteServerConfig(ts.URL, ts.Client(), 20*time.Millisecond))
+
+	require.Eventually(t, func() bool { return hits.Load() >= 2 }, 2*time.Second, 5*time.Millisecond)
+	assert.True(t, c.HasServer("9.9.9.9"))
+
+	require.NoError(t, c.Close())
+	stopped := hits.Load()
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, stopped, hits.Load(), "no refreshes after Close")
+
+	// Close is idempotent.
+	require.NoError(t, c.Close())
+}
+
+func TestWithRemoteConfigErrorHandler(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	errs := make(chan error, 16)
+	// The handler may be set before or after the remote config itself.
+	for _, opts := range [][]Option{
+		{WithRemoteConfigErrorHandler(func(err error) { errs <- err }), WithRemoteServerConfig(ts.URL, ts.Client(), 20*time.Millisecond)},
+		{WithRemoteServerConfig(ts.URL, ts.Client(), 20*time.Millisecond), WithRemoteConfigErrorHandler(func(err error) { errs <- err })},
+	} {
+		c := New(opts...)
+		select {
+		case err := <-errs:
+			assert.ErrorIs(t, err, ErrRemoteConfig)
+			assert.Contains(t, err.Error(), "404")
+		case <-time.After(2 * time.Second):
+			t.Fatal("refresh failure not reported")
+		}
+		require.NoError(t, c.Close())
+		assert.True(t, c.HasServer("180.131.144.144"), "default servers are kept")
+
+		for len(errs) > 0 {
+			<-errs
+		}
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+var (
+	// responseUDPSize matches the buffer size on an OPT pseudosection line:
+	// "udp: 1232".
+	responseUDPSize = regexp.MustCompile(`udp: (\d+)`)
+
+	// responseEDE matches an EDE line as printed by dig and [dns.Msg.String]:
+	// "EDE: 15 (Blocked): (extra text)", with the name and text optional.
+	responseEDE = regexp.MustCompile(`^EDE: (\d+)(?: \([^)]*\))?(?:: (.*))?$`)
+)
+
+// ReadResponse reads a DNS response saved in a file or fixture, so that
+// captured real-world responses can be fed to [DetectBlock] without a live
+// server:
+//
+//	f, err := os.Open("testdata/komdigi_ede15.txt")
+//	...
+//	msg, err := nawala.ReadResponse(f)
+//	blocked, details := nawala.DetectBlock(msg, nawala.DetectOptions{
+//	    Keywords: []string{"trustpositif"},
+//	})
+//
+// Two formats are accepted. Wire format is the raw DNS message, as
+// extracted from a packet capture or produced by [dns.Msg.Pack]. The
+// presentation format is the textual output of dig or [dns.Msg.String]:
+//
+//	;; ->>HEADER<<- opcode: QUERY, status: NOERROR, id: 4711
+//	;; flags: qr rd ra; QUERY: 1, ANSWER: 1, AUTHORITY: 0, ADDITIONAL: 1
+//
+//	;; OPT PSEUDOSECTION:
+//	; EDNS: version: 0, flags:; udp: 1232
+//	; EDE: 15 (Blocked): (source=block-list-zone; domain=reddit.com)
+//
+//	;; QUESTION SECTION:
+//	;reddit.com.            IN      A
+//
+//	;; ANSWER SECTION:
+//	reddit.com.     30      IN      A       103.155.26.29
+//
+// From the presentation format the header status, id and flags, the
+// question, the records of each section, and the EDNS buffer size, DO bit
+// and EDE options are restored; other comment lines, such as dig's query
+// statistics, are ignored. Input starting with ';' is always read as
+// presentation format; anything else is tried as wire format first.
+func ReadResponse(r io.Reader) (*dns.Msg, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	text := bytes.TrimSpace(data)
+	if len(text) == 0 {
+		return nil, errors.New("read response: empty input")
+	}
+	if text[0] != ';' {
+		msg := new(dns.Msg)
+		if err := msg.Unpack(data); err == nil {
+			return msg, nil
+		}
+	}
+
+	msg, err := parseResponseText(text)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	return msg, nil
+}
+
+// parseResponseText parses the presentation format described in
+// [ReadResponse].
+func parseResponseText(text []byte) (*dns.Msg, error) {
+	msg := new(dns.Msg)
+	var section string
+
+	sc := bufio.NewScanner(bytes.NewReader(text))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		var err error
+
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, ";;"):
+			body := strings.TrimSpace(strings.TrimPrefix(line, ";;"))
+			if name, ok := strings.CutSuffix(body, " SECTION:"); ok {
+				section = name
+				continue
+			}
+			if body == "OPT PSEUDOSECTION:" {
+				section = "OPT"
+				continue
+			}
+			err = parseResponseHeader(msg, body)
+		case strings.HasPrefix(line, ";"):
+			body := strings.TrimSpace(strings.TrimPrefix(line, ";"))
+			switch section {
+			case "QUESTION":
+				err = parseResponseQuestion(msg, body)
+			case "OPT":
+				err = parseResponseOPT(msg, body)
+			}
+		default:
+			var rr dns.RR
+			if rr, err = dns.NewRR(line); err != nil {
+				break
+			}
+			switch section {
+			case "ANSWER":
+				msg.Answer = append(msg.Answer, rr)
+			case "AUTHORITY":
+				msg.Ns = append(msg.Ns, rr)
+			case "ADDITIONAL":
+				msg.Extra = append(msg.Extra, rr)
+			default:
+				err = errors.New("record outside of a section")
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// parseResponseHeader applies a ";;" header or flags line to msg. Lines
+// that are neither are ignored.
+func parseResponseHeader(msg *dns.Msg, body string) error {
+	body = strings.TrimSpace(strings.TrimPrefix(body, "->>HEADER<<-"))
+
+	if flags, ok := strings.CutPrefix(body, "flags:"); ok {
+		flags, _, _ = strings.Cut(flags, ";")
+		for flag := range strings.FieldsSeq(flags) {
+			switch flag {
+			case "qr":
+				msg.Response = true
+			case "aa":
+				msg.Authoritative = true
+			case "tc":
+				msg.Truncated = true
+			case "rd":
+				msg.RecursionDesired = true
+			case "ra":
+				msg.RecursionAvailable = true
+			case "ad":
+				msg.AuthenticatedData = true
+			case "cd":
+				msg.CheckingDisabled = true
+			}
+		}
+		return nil
+	}
+
+	if !strings.HasPrefix(body, "opcode:") {
+		return nil
+	}
+	for field := range strings.SplitSeq(body, ",") {
+		key, value, _ := strings.Cut(field, ":")
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "opcode":
+			op, ok := dns.StringToOpcode[value]
+			if !ok {
+				return fmt.Errorf("unknown opcode %q", value)
+			}
+			msg.Opcode = op
+		case "status":
+			rcode, ok := dns.StringToRcode[value]
+			if !ok {
+				return fmt.Errorf("unknown status %q", value)
+			}
+			msg.Rcode = rcode
+		case "id":
+			id, err := strconv.ParseUint(value, 10, 16)
+			if err != nil {
+				return fmt.Errorf("invalid id %q", value)
+			}
+			msg.Id = uint16(id)
+		}
+	}
+	return nil
+}
+
+// parseResponseQuestion appends the question on a QUESTION SECTION line,
+// "name class type", to msg.
+func parseResponseQuestion(msg *dns.Msg, body string) error {
+	fields := strings.Fields(body)
+	if len(fields) != 3 {
+		return fmt.Errorf("malformed question %q", body)
+	}
+	class, ok := dns.StringToClass[fields[1]]
+	if !ok {
+		return fmt.Errorf("unknown class %q", fields[1])
+	}
+	qtype, ok := dns.StringToType[fields[2]]
+	if !ok {
+		return fmt.Errorf("unknown type %q", fields[2])
+	}
+	msg.Question = append(msg.Question, dns.Question{
+		Name:   dns.Fqdn(fields[0]),
+		Qtype:  qtype,
+		Qclass: class,
+	})
+	return nil
+}
+
+// parseResponseOPT applies an OPT PSEUDOSECTION line to msg: the EDNS line
+// creates the OPT record, and EDE lines add their options to it. Other
+// options are ignored.
+func parseResponseOPT(msg *dns.Msg, body string) error {
+	if rest, ok := strings.CutPrefix(body, "EDNS:"); ok {
+		size := uint64(defaultEDNS0Size)
+		if m := responseUDPSize.FindStringSubmatch(rest); m != nil {
+			var err error
+			if size, err = strconv.ParseUint(m[1], 10, 16); err != nil {
+				return fmt.Errorf("invalid EDNS buffer size %q", m[1])
+			}
+		}
+		_, flags, _ := strings.Cut(rest, "flags:")
+		flags, _, _ = strings.Cut(flags, ";")
+		do := strings.Contains(flags, "do")
+		if opt := msg.IsEdns0(); opt != nil {
+			opt.SetUDPSize(uint16(size))
+			opt.SetDo(do)
+		} else {
+			msg.SetEdns0(uint16(size), do)
+		}
+		return nil
+	}
+
+	m := responseEDE.FindStringSubmatch(body)
+	if m == nil {
+		return nil
+	}
+	code, err := strconv.ParseUint(m[1], 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid EDE code %q", m[1])
+	}
+	text := strings.TrimSpace(m[2])
+	if len(text) >= 2 && (text[0] == '(' && text[len(text)-1] == ')' || text[0] == '"' && text[len(text)-1] == '"') {
+		text = text[1 : len(text)-1]
+	}
+
+	opt := msg.IsEdns0()
+	if opt == nil {
+		msg.SetEdns0(defaultEDNS0Size, false)
+		opt = msg.IsEdns0()
+	}
+	opt.Option = append(opt.Option, &dns.EDNS0_EDE{InfoCode: uint16(code), ExtraText: text})
+	return nil
+}
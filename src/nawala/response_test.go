@@ -0,0 +1,126 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/H0llyW00dzZ/nawala-checker/src/nawala"
+)
+
+func readFixture(t *testing.T, name string) *dns.Msg {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	defer f.Close()
+
+	msg, err := nawala.ReadResponse(f)
+	require.NoError(t, err)
+	return msg
+}
+
+// TestDetectBlockFixtures runs the detection rules against the captured
+// responses in testdata.
+func TestDetectBlockFixtures(t *testing.T) {
+	tests := []struct {
+		file     string
+		keywords []string
+		blocked  bool
+		reason   nawala.BlockReason
+	}{
+		{"nawala_cname.txt", []string{"internetpositif"}, true, nawala.BlockReasonRedirect},
+		{"komdigi_ede15.txt", []string{"trustpositif"}, true, nawala.BlockReasonBlocked},
+		{"komdigi_ede15.bin", []string{"trustpositif"}, true, nawala.BlockReasonBlocked},
+		{"clean.txt", []string{"internetpositif"}, false, nawala.BlockReasonNone},
+		{"nxdomain.txt", []string{"internetpositif"}, false, nawala.BlockReasonNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			msg := readFixture(t, tt.file)
+			blocked, details := nawala.DetectBlock(msg, nawala.DetectOptions{Keywords: tt.keywords})
+			assert.Equal(t, tt.blocked, blocked)
+			assert.Equal(t, tt.reason, details.Reason)
+		})
+	}
+}
+
+func TestReadResponseText(t *testing.T) {
+	msg := readFixture(t, "komdigi_ede15.txt")
+
+	assert.Equal(t, uint16(40121), msg.Id)
+	assert.Equal(t, dns.RcodeSuccess, msg.Rcode)
+	assert.True(t, msg.Response)
+	assert.True(t, msg.RecursionAvailable)
+	assert.False(t, msg.Authoritative)
+	require.Len(t, msg.Question, 1)
+	assert.Equal(t, dns.Question{Name: "reddit.com.", Qtype: dns.TypeA, Qclass: dns.ClassINET}, msg.Question[0])
+	require.Len(t, msg.Answer, 1)
+	assert.Equal(t, "103.155.26.29", msg.Answer[0].(*dns.A).A.String())
+
+	opt := msg.IsEdns0()
+	require.NotNil(t, opt)
+	assert.Equal(t, uint16(1232), opt.UDPSize())
+	require.Len(t, opt.Option, 1)
+	ede := opt.Option[0].(*dns.EDNS0_EDE)
+	assert.Equal(t, dns.ExtendedErrorCodeBlocked, ede.InfoCode)
+	assert.True(t, strings.HasSuffix(ede.ExtraText, "; domain=reddit.com"), ede.ExtraText)
+
+	nx := readFixture(t, "nxdomain.txt")
+	assert.Equal(t, dns.RcodeNameError, nx.Rcode)
+	require.Len(t, nx.Ns, 1)
+	assert.Nil(t, nx.IsEdns0())
+}
+
+func TestReadResponseRoundTrip(t *testing.T) {
+	want := new(dns.Msg)
+	want.SetQuestion("reddit.com.", dns.TypeA)
+	want.Response = true
+	want.Authoritative = true
+	want.Rcode = dns.RcodeNameError
+	rr, err := dns.NewRR("reddit.com. 30 IN CNAME internetpositif.id.")
+	require.NoError(t, err)
+	want.Answer = append(want.Answer, rr)
+	want.SetEdns0(4096, true)
+	want.IsEdns0().Option = append(want.IsEdns0().Option, &dns.EDNS0_EDE{InfoCode: 16, ExtraText: "censored"})
+
+	// Presentation format, as printed by dns.Msg.String.
+	got, err := nawala.ReadResponse(strings.NewReader(want.String()))
+	require.NoError(t, err)
+	assert.Equal(t, want.String(), got.String())
+
+	// Wire format.
+	wire, err := want.Pack()
+	require.NoError(t, err)
+	got, err = nawala.ReadResponse(bytes.NewReader(wire))
+	require.NoError(t, err)
+	assert.Equal(t, want.String(), got.String())
+}
+
+func TestReadResponseErrors(t *testing.T) {
+	for name, input := range map[string]string{
+		"empty":           "  \n",
+		"garbage":         "not a DNS message",
+		"bad status":      ";; ->>HEADER<<- opcode: QUERY, status: BOGUS, id: 1",
+		"bad question":    ";; QUESTION SECTION:\n;example.com. IN",
+		"bad record":      ";; ANSWER SECTION:\nexample.com. 60 IN A not-an-ip",
+		"orphan record":   "example.com. 60 IN A 192.0.2.1",
+		"bad buffer size": ";; OPT PSEUDOSECTION:\n; EDNS: version 0; flags:; udp: 99999",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := nawala.ReadResponse(strings.NewReader(input))
+			assert.Error(t, err)
+		})
+	}
+}
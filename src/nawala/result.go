@@ -5,6 +5,12 @@
 
 package nawala
 
+import (
+	"net"
+	"slices"
+	"time"
+)
+
 // Result represents the outcome of checking a single domain
 // against a Nawala DNS server.
 //
@@ -26,6 +32,101 @@ type Result struct {
 	// Server is the DNS server IP that was used for the check.
 	Server string
 
+	// Latency is the round-trip time of the DNS query that produced this
+	// result. It is zero when the result carries an error.
+	Latency time.Duration
+
+	// TTL is the smallest TTL, in seconds, among the Answer records of the
+	// response that produced this result, or 0 when it had none. Block
+	// records often carry a distinctive TTL. For a result served from the
+	// built-in cache it is the TTL remaining, decremented by the time the
+	// entry has been cached.
+	TTL uint32
+
+	// BlockReason classifies how the domain was blocked, based on the EDE
+	// INFO-CODE of the response or, for Nawala-style blocks, the CNAME
+	// redirect. It is [BlockReasonNone] when the domain is not blocked.
+	BlockReason BlockReason
+
+	// MatchedSection names the response section in which the block signal
+	// was found: [SectionAnswer] (e.g. a CNAME redirect or sinkhole address),
+	// [SectionAuthority], or [SectionAdditional] (e.g. an EDE in the OPT
+	// record). It is empty when the domain is not blocked, or when the
+	// verdict came from a [ResponseValidator].
+	MatchedSection string
+
+	// Signals lists every block indicator found in the response, such as a
+	// CNAME redirect together with a filtering EDE. It is only set for
+	// blocked results when [WithAllMatches] is enabled; see [BlockSignal].
+	Signals []BlockSignal
+
+	// BlockListURL is the "blockListUrl=" field of the response's Extended
+	// DNS Error text, as sent by Komdigi (e.g.
+	// "https://trustpositif.komdigi.go.id/assets/db/domains_isp"). It names
+	// the block list the domain was found on and is empty when the response
+	// carries no such field.
+	BlockListURL string
+
+	// Authoritative reports whether the response that produced this result
+	// had the AA (Authoritative Answer) bit set. It helps distinguish a block
+	// served by the authoritative zone from one injected by an intercepting
+	// recursive resolver.
+	Authoritative bool
+
+	// ResolvedIPs holds the A and AAAA addresses found in the Answer
+	// section of the response that produced this result.
+	ResolvedIPs []net.IP
+
+	// HTTPConfirmed reports whether a blocked verdict was confirmed by
+	// fetching a known block page from one of the [Result.ResolvedIPs].
+	//
+	// Only set when [WithHTTPConfirmation] is configured and the domain
+	// was detected as blocked; it is always false otherwise.
+	HTTPConfirmed bool
+
+	// CookieVerified reports whether the response carried a valid DNS
+	// Cookie ([RFC 7873]) echoing the client cookie sent with the query,
+	// which an off-path spoofer cannot forge. Only set when [WithDNSCookie]
+	// is enabled; a false value then means the server cookie was missing or
+	// mismatched, which is suspicious for a resolver known to support
+	// cookies.
+	//
+	// [RFC 7873]: https://datatracker.ietf.org/doc/html/rfc7873
+	CookieVerified bool
+
+	// Attempts is the number of probes sent to [Result.Server] before the
+	// verdict was reached, including probes that failed. A blocked verdict
+	// stops probing early; a clean one uses every probe allowed by
+	// [WithMaxRetries].
+	Attempts int
+
+	// BlockedOnAttempt is the 1-based probe that detected the block, or 0
+	// when the domain is not blocked. A value above 1 means earlier probes
+	// came back clean or failed, revealing intermittent blocking. With
+	// [WithParallelProbes] the probes are numbered in completion order.
+	BlockedOnAttempt int
+
+	// Stale reports that the result was served from the cache after its TTL
+	// expired, within the grace window of [WithStaleWhileRevalidate]. A
+	// background check refreshing the entry has been started.
+	Stale bool
+
+	// Degraded reports that every server failed and the result is a
+	// fail-open "not blocked" verdict rather than an observed one, or an
+	// IP-only verdict from the system resolver. Only set when [WithFailOpen]
+	// or [WithSystemResolverFallback] is enabled; [Result.Error] is nil in
+	// that case.
+	Degraded bool
+
+	// Metadata holds data attached by the [Enricher] functions registered
+	// with [WithEnricher], such as RDAP registration details or the GeoIP
+	// location of [Result.ResolvedIPs]. It is nil when no enricher is set.
+	//
+	// Cached results share the map, so it must be treated as read-only.
+	// With a [Cache] backend that stores bytes, its values must be
+	// encodable by [EncodeResult] (see [encoding/gob.Register]).
+	Metadata map[string]any
+
 	// Error is non-nil if the check encountered an error
 	// (e.g., DNS timeout, invalid domain, NXDOMAIN).
 	// When set, the [Result.Blocked] field is unreliable and must be ignored.
@@ -74,6 +175,14 @@ type ServerStatus struct {
 //
 // When no port is specified, the default port is determined by the transport
 // protocol: port 53 for UDP and TCP, and port 853 for DNS-over-TLS (tcp-tls).
+//
+// DNSServer serializes to JSON and YAML with snake_case keys ("address",
+// "keyword", "query_type", "match_scope", "match_mode", "tags"); see
+// [ParseServers] and [WriteServers].
+//
+// Because Tags is a slice, DNSServer is not comparable: it cannot be
+// compared with == or used as a map key. Compare the fields that matter
+// instead, e.g. key a map by Address.
 type DNSServer struct {
 	// Address is the DNS server to query.
 	//
@@ -82,13 +191,107 @@ type DNSServer struct {
 	// IPv6 addresses with a port must be bracketed: "[::1]:5353".
 	//
 	// If no port is given, port 53 is used for UDP/TCP and port 853 for tcp-tls.
-	Address string
+	//
+	// Surrounding whitespace is trimmed when servers are configured, and the
+	// address is otherwise stored and dialed as given. When addresses are
+	// compared (to replace, look up, or delete a server), IP literals are
+	// taken in their standard form, hostnames are lower-cased, and an
+	// explicit port 53 is ignored, so "8.8.8.8:53" and "8.8.8.8" name the
+	// same server.
+	Address string `json:"address" yaml:"address"`
 
 	// Keyword is the substring to search for in DNS responses
 	// that indicates a domain is blocked.
-	Keyword string
+	Keyword string `json:"keyword" yaml:"keyword"`
 
 	// QueryType is the DNS record type to query.
-	// Use the dns query type constants (e.g., "ANY", "TXT", "A").
-	QueryType string
+	// Use the dns query type constants (e.g., "ANY", "TXT", "A", "PTR").
+	QueryType string `json:"query_type" yaml:"query_type"`
+
+	// MatchScope controls which part of each DNS record the Keyword is
+	// matched against:
+	//
+	//   - [MatchScopeRecord] ("record", also the default when empty) —
+	//     the full record string, including owner name, TTL, class, and type.
+	//   - [MatchScopeData] ("data") — only the record data is matched
+	//     (CNAME target, A/AAAA address, TXT strings, OPT options, ...),
+	//     avoiding false matches on short keywords such as "com" or "ns"
+	//     that appear in the record header, or on the queried domain itself.
+	//
+	// When empty, the checker-wide default applies (see [WithStrictMatch]).
+	MatchScope string `json:"match_scope,omitempty" yaml:"match_scope,omitempty"`
+
+	// MatchMode controls how the Keyword is compared with the matched text:
+	//
+	//   - [MatchModeSubstring] ("substring", also the default when empty) —
+	//     any case-insensitive substring matches, so "positif" also hits
+	//     unrelated names such as "positifity.com".
+	//   - [MatchModeLabel] ("label") — the keyword must match whole DNS
+	//     labels, bounded by dots: "internetpositif" matches
+	//     "internetpositif.id" but not "internetpositifity.com". A keyword
+	//     with dots, such as "internetpositif.id", matches that run of labels.
+	//
+	// When empty, the checker-wide default applies (see [WithMatchMode]).
+	MatchMode string `json:"match_mode,omitempty" yaml:"match_mode,omitempty"`
+
+	// Tags are free-form labels describing the server's role (e.g.
+	// "nawala", "komdigi", "reference"). [Checker.CheckWithTags] uses them
+	// to run a check against a subset of the configured servers.
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+
+	// Dialer, when set, is used instead of the checker's client dialer for
+	// queries to this server, e.g. to bind a LocalAddr on a VPN interface
+	// while other servers are reached directly. All other client settings
+	// (protocol, timeout, TLS) still come from the checker. Its Timeout
+	// only tightens the checker's; see [dns.Client.Dialer].
+	//
+	// A dialer cannot be expressed in configuration files, so it is
+	// skipped by [ParseServers] and [WriteServers].
+	Dialer *net.Dialer `json:"-" yaml:"-"`
+}
+
+// hasAnyTag reports whether s carries at least one of tags.
+// An empty tags slice matches every server.
+func (s DNSServer) hasAnyTag(tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	for _, tag := range tags {
+		if slices.Contains(s.Tags, tag) {
+			return true
+		}
+	}
+	return false
 }
+
+// Match scopes for [DNSServer.MatchScope].
+const (
+	// MatchScopeRecord matches the keyword against the full record string.
+	MatchScopeRecord = "record"
+
+	// MatchScopeData matches the keyword against record data only.
+	MatchScopeData = "data"
+)
+
+// Match modes for [DNSServer.MatchMode].
+const (
+	// MatchModeSubstring matches the keyword anywhere in the text.
+	MatchModeSubstring = "substring"
+
+	// MatchModeLabel matches the keyword only as whole DNS labels.
+	MatchModeLabel = "label"
+)
+
+// Response sections reported in [Result.MatchedSection].
+const (
+	// SectionAnswer is the Answer section, where Nawala's CNAME redirect
+	// and sinkhole addresses appear.
+	SectionAnswer = "answer"
+
+	// SectionAuthority is the Authority (Ns) section.
+	SectionAuthority = "authority"
+
+	// SectionAdditional is the Additional (Extra) section, which holds the
+	// OPT record carrying Extended DNS Errors such as Komdigi's EDE 15.
+	SectionAdditional = "additional"
+)
@@ -0,0 +1,335 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// validateServer checks that s has a well-formed address and, when set, a
+// known query type, match scope, and match mode. An empty QueryType is
+// accepted since it defaults to A, and an empty MatchScope or MatchMode
+// since they default to the checker-wide setting.
+func validateServer(s DNSServer) error {
+	if err := validateAddress(s.Address); err != nil {
+		return err
+	}
+	if strings.TrimSpace(s.QueryType) != "" {
+		if _, ok := lookupQueryType(s.QueryType); !ok {
+			return fmt.Errorf("unknown query type %q", s.QueryType)
+		}
+	}
+	if s.MatchScope != "" && !isMatchScope(s.MatchScope) {
+		return fmt.Errorf("unknown match scope %q", s.MatchScope)
+	}
+	if s.MatchMode != "" && !isMatchMode(s.MatchMode) {
+		return fmt.Errorf("unknown match mode %q", s.MatchMode)
+	}
+	return nil
+}
+
+// isMatchScope reports whether scope names a [DNSServer.MatchScope],
+// ignoring case as keyword matching does.
+func isMatchScope(scope string) bool {
+	return strings.EqualFold(scope, MatchScopeRecord) || strings.EqualFold(scope, MatchScopeData)
+}
+
+// isMatchMode reports whether mode names a [DNSServer.MatchMode], ignoring
+// case as keyword matching does.
+func isMatchMode(mode string) bool {
+	return strings.EqualFold(mode, MatchModeSubstring) || strings.EqualFold(mode, MatchModeLabel)
+}
+
+// validateAddress checks that addr is one of the formats documented on
+// [DNSServer.Address]: an IP or hostname, optionally with a port, where
+// IPv6 addresses with a port are bracketed.
+func validateAddress(addr string) error {
+	if strings.TrimSpace(addr) == "" {
+		return errors.New("empty address")
+	}
+
+	host := addr
+	if h, port, err := net.SplitHostPort(addr); err == nil {
+		p, err := strconv.Atoi(port)
+		if err != nil || p < 1 || p > 65535 {
+			return fmt.Errorf("invalid port %q", port)
+		}
+		host = h
+	} else {
+		// No port: allow a bracketed IPv6 literal such as "[::1]".
+		host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	}
+
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+
+	// Hostnames may be single-label (e.g. "localhost") or fully qualified.
+	name := strings.TrimSuffix(host, ".")
+	if name == "" || len(name) > 255 {
+		return fmt.Errorf("invalid host %q", host)
+	}
+	for label := range strings.SplitSeq(name, ".") {
+		if !isValidLabel(label) {
+			return fmt.Errorf("invalid host %q", host)
+		}
+	}
+	return nil
+}
+
+// normalizeAddress returns the canonical form of a server address, so that
+// spellings of the same server compare equal when servers are added,
+// replaced, looked up, or deleted: surrounding whitespace is trimmed, IP
+// literals are rewritten in their standard form (IPv6 compressed and
+// lower-cased, with brackets only when a port follows), hostnames are
+// lower-cased, and the default port 53 is dropped. "8.8.8.8:53 " and
+// "8.8.8.8" both become "8.8.8.8", and "[2001:DB8::0001]" becomes
+// "2001:db8::1".
+//
+// It is only used for comparison; configured addresses are stored and
+// dialed as given, since a dropped port would change what is dialed.
+//
+// Addresses it cannot make sense of are only trimmed; rejecting them is left
+// to [validateAddress].
+func normalizeAddress(addr string) string {
+	addr = strings.TrimSpace(addr)
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]"), ""
+	}
+	if host == "" {
+		return addr
+	}
+
+	if ip, err := netip.ParseAddr(host); err == nil {
+		host = ip.String()
+	} else {
+		host = strings.ToLower(host)
+	}
+
+	if port == "" || port == "53" {
+		return host
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// sameAddress reports whether a and b name the same server once normalized
+// by [normalizeAddress].
+func sameAddress(a, b string) bool {
+	return a == b || normalizeAddress(a) == normalizeAddress(b)
+}
+
+// trimServers returns a copy of servers with surrounding whitespace trimmed
+// from every address. The addresses are otherwise kept as configured, since
+// they are dialed as is: an explicit port must survive even where it is the
+// default. A nil input is returned unchanged.
+func trimServers(servers []DNSServer) []DNSServer {
+	if servers == nil {
+		return nil
+	}
+	trimmed := slices.Clone(servers)
+	for i := range trimmed {
+		trimmed[i].Address = strings.TrimSpace(trimmed[i].Address)
+	}
+	return trimmed
+}
+
+// validateServers validates every server, returning an error that wraps
+// [ErrInvalidServer] and lists each offender by index, or nil when all
+// servers are valid.
+func validateServers(servers []DNSServer) error {
+	var errs []error
+	for i, s := range servers {
+		if err := validateServer(s); err != nil {
+			errs = append(errs, fmt.Errorf("server %d (%q): %w", i, s.Address, err))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidServer, errors.Join(errs...))
+}
+
+// SetServersValidated is the strict counterpart of [Checker.SetServers].
+// It validates every server's [DNSServer.Address] format,
+// [DNSServer.QueryType], [DNSServer.MatchScope], and [DNSServer.MatchMode]
+// (which [Checker.SetServers] would otherwise silently treat as the
+// defaults when misspelled) before touching the configuration.
+//
+// If any server is invalid, the configuration is left unchanged and the
+// returned error wraps [ErrInvalidServer], listing each offender by index.
+// Otherwise the servers are applied exactly as [Checker.SetServers] does.
+func (c *Checker) SetServersValidated(servers ...DNSServer) error {
+	if err := validateServers(servers); err != nil {
+		return err
+	}
+	c.SetServers(servers...)
+	return nil
+}
+
+// ParseServers reads a list of [DNSServer] from r, in either JSON or YAML
+// (YAML being a superset of JSON, both are accepted by the same parser):
+//
+//	# servers.yaml
+//	- address: 180.131.144.144
+//	  keyword: internetpositif
+//	  query_type: A
+//	  tags: [nawala]
+//
+// Every entry is validated like [Checker.SetServersValidated] does; if any
+// is invalid the returned error wraps [ErrInvalidServer] and names each
+// offending index. Empty input yields an empty list.
+func ParseServers(r io.Reader) ([]DNSServer, error) {
+	var servers []DNSServer
+	if err := yaml.NewDecoder(r).Decode(&servers); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse servers: %w", err)
+	}
+	if err := validateServers(servers); err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
+// WriteServers writes servers to w as an indented JSON array, the format
+// read back by [ParseServers] and [WithRemoteServerConfig]. Since JSON is
+// valid YAML, the output can also be embedded in YAML configuration.
+func WriteServers(w io.Writer, servers []DNSServer) error {
+	if servers == nil {
+		servers = []DNSServer{} // encode as [] rather than null
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(servers)
+}
+
+// mutateServers runs fn, which changes c.servers, under the server lock, and
+// then reports the change to the [ServerChangeHook], if any, once the lock
+// has been released.
+func (c *Checker) mutateServers(fn func()) {
+	c.mu.Lock()
+	hook := c.serverHook
+	var old []DNSServer
+	if hook != nil {
+		old = slices.Clone(c.servers)
+	}
+	fn()
+	var updated []DNSServer
+	if hook != nil {
+		updated = slices.Clone(c.servers)
+	}
+	c.mu.Unlock()
+
+	if hook != nil && !slices.EqualFunc(old, updated, func(a, b DNSServer) bool {
+		return reflect.DeepEqual(a, b)
+	}) {
+		hook(old, updated)
+	}
+}
+
+// ServerInfo describes a configured DNS server for management and auditing
+// tools. It is returned by [Checker.ServerInfos].
+type ServerInfo struct {
+	DNSServer
+
+	// AddedAt is when the server entered the active set: construction time
+	// for servers passed to [New], or the time of the runtime call that
+	// added it. Replacing a server in place with [Checker.SetServers]
+	// keeps its original AddedAt.
+	AddedAt time.Time
+
+	// Default reports whether the server came from the built-in
+	// [DefaultServers] (at construction or via [Checker.ResetServers])
+	// rather than from caller configuration.
+	Default bool
+
+	// Enabled reports whether checks currently query the server. It is
+	// false while [WithCircuitBreaker] or [WithServerCooldown] skips the
+	// server after failures; if every server is skipped, checks query them
+	// all anyway.
+	Enabled bool
+}
+
+// serverAdded is the audit data tracked per normalized server address.
+type serverAdded struct {
+	at        time.Time
+	isDefault bool
+}
+
+// ServerInfos returns the currently configured DNS servers, in the same
+// order as [Checker.Servers], along with when each was added and whether
+// it is a built-in default and currently enabled. Timestamps come from the
+// checker's [Clock].
+func (c *Checker) ServerInfos() []ServerInfo {
+	now := c.clock.Now()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	infos := make([]ServerInfo, len(c.servers))
+	for i, srv := range c.servers {
+		added := c.serverAdded[normalizeAddress(srv.Address)]
+		infos[i] = ServerInfo{
+			DNSServer: srv,
+			AddedAt:   added.at,
+			Default:   added.isDefault,
+			Enabled:   !c.health.skipping(srv.Address, now),
+		}
+	}
+	return infos
+}
+
+// trackServersLocked updates the audit data after c.servers has changed:
+// addresses in changed are recorded with the given origin (keeping any
+// earlier AddedAt), and addresses no longer configured are forgotten.
+// Addresses are compared in their normalized form. The caller must hold
+// c.mu for writing.
+func (c *Checker) trackServersLocked(changed []DNSServer, isDefault bool) {
+	if c.serverAdded == nil {
+		c.serverAdded = make(map[string]serverAdded, len(c.servers))
+	}
+
+	now := c.clock.Now()
+	for _, srv := range changed {
+		key := normalizeAddress(srv.Address)
+		added, ok := c.serverAdded[key]
+		if !ok {
+			added.at = now
+		}
+		added.isDefault = isDefault
+		c.serverAdded[key] = added
+	}
+
+	present := make(map[string]struct{}, len(c.servers))
+	for _, srv := range c.servers {
+		present[normalizeAddress(srv.Address)] = struct{}{}
+	}
+	for addr := range c.serverAdded {
+		if _, ok := present[addr]; !ok {
+			delete(c.serverAdded, addr)
+		}
+	}
+}
+
+// isBuiltinServer reports whether s is one of the built-in default servers.
+func isBuiltinServer(s DNSServer) bool {
+	for _, d := range defaultServers {
+		if s.Address == d.Address && s.Keyword == d.Keyword && s.QueryType == d.QueryType {
+			return true
+		}
+	}
+	return false
+}
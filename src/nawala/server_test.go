@@ -0,0 +1,359 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAddress(t *testing.T) {
+	tests := []struct {
+		addr    string
+		wantErr bool
+	}{
+		{"180.131.144.144", false},
+		{"180.131.144.144:5353", false},
+		{"2001:db8::1", false},
+		{"[2001:db8::1]", false},
+		{"[2001:db8::1]:5353", false},
+		{"dns.example.com", false},
+		{"dns.example.com:853", false},
+		{"localhost", false},
+		{"", true},
+		{"   ", true},
+		{"8.8.8.8:0", true},
+		{"8.8.8.8:99999", true},
+		{"8.8.8.8:dns", true},
+		{"bad host.example", true},
+		{"-bad.example.com", true},
+	}
+
+	for _, tt := range tests {
+		err := validateAddress(tt.addr)
+		if tt.wantErr {
+			assert.Error(t, err, "addr %q", tt.addr)
+		} else {
+			assert.NoError(t, err, "addr %q", tt.addr)
+		}
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"8.8.8.8", "8.8.8.8"},
+		{" 8.8.8.8 ", "8.8.8.8"},
+		{"8.8.8.8:53", "8.8.8.8"},
+		{"8.8.8.8:5353", "8.8.8.8:5353"},
+		{"[::1]", "::1"},
+		{"[::1]:53", "::1"},
+		{"[::1]:5353", "[::1]:5353"},
+		{"2001:DB8::0001", "2001:db8::1"},
+		{"[2001:db8:0:0::1]:853", "[2001:db8::1]:853"},
+		{"DNS.Example.com", "dns.example.com"},
+		{"dns.example.com:53", "dns.example.com"},
+		{"dns.example.com:5353", "dns.example.com:5353"},
+		{"", ""},
+		{"  ", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, normalizeAddress(tt.addr), "%q", tt.addr)
+	}
+}
+
+func TestServersNormalizedAddresses(t *testing.T) {
+	c := New(WithServers([]DNSServer{
+		{Address: "8.8.8.8", Keyword: "blocked", QueryType: "A"},
+		{Address: "8.8.8.8:53 ", Keyword: "blocked", QueryType: "A"},
+	}))
+	require.Len(t, c.Servers(), 1, "the same server with and without :53 is one entry")
+	assert.Equal(t, "8.8.8.8", c.Servers()[0].Address, "the first spelling is kept")
+
+	// Upsert: the port-qualified spelling replaces the existing entry.
+	c.SetServers(DNSServer{Address: "8.8.8.8:53", Keyword: "updated", QueryType: "A"})
+	require.Len(t, c.Servers(), 1)
+	assert.Equal(t, "updated", c.Servers()[0].Keyword)
+
+	c.SetServers(DNSServer{Address: "[2001:DB8::1]", Keyword: "blocked", QueryType: "AAAA"})
+	require.Len(t, c.Servers(), 2)
+	assert.Equal(t, "[2001:DB8::1]", c.Servers()[1].Address, "addresses are stored as configured")
+
+	assert.True(t, c.HasServer(" 8.8.8.8:53"))
+	assert.True(t, c.HasServer("[2001:db8::1]:53"))
+	assert.False(t, c.HasServer("8.8.8.8:5353"), "a non-default port is a different server")
+
+	c.DeleteServers("[2001:db8::0001]:53")
+	require.Len(t, c.Servers(), 1)
+
+	c.ReplaceServers([]DNSServer{
+		{Address: "dns.example.com:53", Keyword: "blocked", QueryType: "A"},
+		{Address: "DNS.example.com", Keyword: "blocked", QueryType: "A"},
+	})
+	require.Len(t, c.Servers(), 1)
+	assert.Equal(t, "dns.example.com:53", c.Servers()[0].Address, "an explicit port is kept for dialing")
+}
+
+func TestValidateServer(t *testing.T) {
+	assert.NoError(t, validateServer(DNSServer{Address: "8.8.8.8", QueryType: "a"}))
+	assert.NoError(t, validateServer(DNSServer{Address: "8.8.8.8"}), "empty query type defaults to A")
+	assert.Error(t, validateServer(DNSServer{Address: "8.8.8.8", QueryType: "AAA"}))
+	assert.Error(t, validateServer(DNSServer{Address: "", QueryType: "A"}))
+
+	assert.NoError(t, validateServer(DNSServer{Address: "8.8.8.8", MatchScope: "Data", MatchMode: "LABEL"}))
+	assert.ErrorContains(t, validateServer(DNSServer{Address: "8.8.8.8", MatchScope: "answer"}), "match scope")
+	assert.ErrorContains(t, validateServer(DNSServer{Address: "8.8.8.8", MatchMode: "lable"}), "match mode")
+}
+
+func TestSetServersValidated(t *testing.T) {
+	c := New()
+	before := c.Servers()
+
+	err := c.SetServersValidated(
+		DNSServer{Address: "1.1.1.1", Keyword: "ok", QueryType: "A"},
+		DNSServer{Address: "8.8.8.8", Keyword: "typo", QueryType: "AAA"},
+		DNSServer{Address: "bad host", Keyword: "bad", QueryType: "TXT"},
+	)
+	require.ErrorIs(t, err, ErrInvalidServer)
+	assert.Contains(t, err.Error(), "server 1")
+	assert.Contains(t, err.Error(), "server 2")
+	assert.NotContains(t, err.Error(), "server 0")
+	assert.Equal(t, before, c.Servers(), "state must not change on validation failure")
+
+	require.NoError(t, c.SetServersValidated(
+		DNSServer{Address: "1.1.1.1", Keyword: "ok", QueryType: "A"},
+	))
+	assert.True(t, c.HasServer("1.1.1.1"))
+
+	// Zero servers is a no-op, like SetServers.
+	require.NoError(t, c.SetServersValidated())
+}
+
+func TestParseServers(t *testing.T) {
+	want := []DNSServer{
+		{Address: "180.131.144.144", Keyword: "internetpositif", QueryType: "A", Tags: []string{"nawala"}},
+		{Address: "[2001:db8::1]:5353", Keyword: "trustpositif", QueryType: "TXT", MatchScope: MatchScopeData},
+	}
+
+	jsonDoc := `[
+  {"address": "180.131.144.144", "keyword": "internetpositif", "query_type": "A", "tags": ["nawala"]},
+  {"address": "[2001:db8::1]:5353", "keyword": "trustpositif", "query_type": "TXT", "match_scope": "data"}
+]`
+	got, err := ParseServers(strings.NewReader(jsonDoc))
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	yamlDoc := `
+- address: 180.131.144.144
+  keyword: internetpositif
+  query_type: A
+  tags: [nawala]
+- address: "[2001:db8::1]:5353"
+  keyword: trustpositif
+  query_type: TXT
+  match_scope: data
+`
+	got, err = ParseServers(strings.NewReader(yamlDoc))
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	got, err = ParseServers(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestParseServersErrors(t *testing.T) {
+	_, err := ParseServers(strings.NewReader(`{not: [valid`))
+	assert.Error(t, err)
+
+	_, err = ParseServers(strings.NewReader(`
+- address: 8.8.8.8
+  query_type: A
+- address: 8.8.4.4
+  query_type: AAA
+`))
+	require.ErrorIs(t, err, ErrInvalidServer)
+	assert.Contains(t, err.Error(), "server 1")
+
+	// A misspelled match mode is rejected rather than falling back to
+	// substring matching.
+	_, err = ParseServers(strings.NewReader(`[{"address": "8.8.8.8", "match_mode": "lable"}]`))
+	require.ErrorIs(t, err, ErrInvalidServer)
+	assert.Contains(t, err.Error(), `unknown match mode "lable"`)
+}
+
+func TestWriteServersRoundTrip(t *testing.T) {
+	servers := []DNSServer{
+		{Address: "180.131.144.144", Keyword: "internetpositif", QueryType: "A", Tags: []string{"nawala", "primary"}},
+		{Address: "dns.example.com:853", Keyword: "blocked", QueryType: "TXT", MatchScope: MatchScopeData},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteServers(&buf, servers))
+	assert.Contains(t, buf.String(), `"query_type": "A"`)
+	assert.NotContains(t, buf.String(), `"match_scope": ""`, "empty optional fields are omitted")
+
+	got, err := ParseServers(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, servers, got)
+
+	buf.Reset()
+	require.NoError(t, WriteServers(&buf, nil))
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestServerInfos(t *testing.T) {
+	clock := newFakeClock()
+	start := clock.Now()
+	c := New(WithClock(clock))
+
+	infos := c.ServerInfos()
+	require.Len(t, infos, len(defaultServers))
+	for i, info := range infos {
+		assert.Equal(t, defaultServers[i], info.DNSServer)
+		assert.Equal(t, start, info.AddedAt)
+		assert.True(t, info.Default)
+		assert.True(t, info.Enabled)
+	}
+
+	clock.Advance(time.Hour)
+	custom := DNSServer{Address: "203.0.113.1", Keyword: "blocked", QueryType: "A"}
+	c.SetServers(custom)
+
+	infos = c.ServerInfos()
+	require.Len(t, infos, 3)
+	assert.Equal(t, custom, infos[2].DNSServer)
+	assert.Equal(t, start.Add(time.Hour), infos[2].AddedAt)
+	assert.False(t, infos[2].Default)
+
+	// Replacing in place keeps the original timestamp but not the default flag.
+	clock.Advance(time.Hour)
+	c.SetServers(DNSServer{Address: defaultServers[0].Address, Keyword: "custom", QueryType: "A"})
+	infos = c.ServerInfos()
+	assert.Equal(t, start, infos[0].AddedAt)
+	assert.False(t, infos[0].Default)
+
+	// So does replacing it under another spelling of the same address.
+	clock.Advance(time.Hour)
+	c.SetServers(DNSServer{Address: custom.Address + ":53", Keyword: "blocked", QueryType: "A"})
+	infos = c.ServerInfos()
+	require.Len(t, infos, 3)
+	assert.Equal(t, custom.Address+":53", infos[2].Address)
+	assert.Equal(t, start.Add(time.Hour), infos[2].AddedAt)
+	c.SetServers(custom)
+
+	// Deleted servers are forgotten, so re-adding records a new timestamp.
+	c.DeleteServers(custom.Address)
+	clock.Advance(time.Hour)
+	c.SetServers(custom)
+	infos = c.ServerInfos()
+	require.Len(t, infos, 3)
+	assert.Equal(t, start.Add(4*time.Hour), infos[2].AddedAt)
+
+	c.ResetServers()
+	infos = c.ServerInfos()
+	require.Len(t, infos, len(defaultServers))
+	assert.Equal(t, start, infos[0].AddedAt)
+	assert.True(t, infos[0].Default)
+	assert.Equal(t, start, infos[1].AddedAt)
+	assert.True(t, infos[1].Default)
+}
+
+func TestServerInfosWithServers(t *testing.T) {
+	clock := newFakeClock()
+	c := New(WithClock(clock), WithServers([]DNSServer{
+		{Address: "203.0.113.1", Keyword: "blocked", QueryType: "A"},
+	}))
+
+	infos := c.ServerInfos()
+	require.Len(t, infos, 1)
+	assert.Equal(t, clock.Now(), infos[0].AddedAt)
+	assert.False(t, infos[0].Default)
+
+	c.ReplaceServers(nil)
+	assert.Empty(t, c.ServerInfos())
+}
+
+func TestServerInfosEnabled(t *testing.T) {
+	var healthy atomic.Bool
+	var queries atomic.Int32
+	addr := startFlakyDNSServer(t, &healthy, &queries)
+
+	clock := newFakeClock()
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(0),
+		WithCache(nil),
+		WithClock(clock),
+		WithServerCooldown(1, time.Minute),
+	)
+	require.True(t, c.ServerInfos()[0].Enabled)
+
+	_, err := c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.False(t, c.ServerInfos()[0].Enabled, "skipped during the cooldown")
+
+	clock.Advance(time.Minute)
+	assert.True(t, c.ServerInfos()[0].Enabled)
+}
+
+func TestServerDialer(t *testing.T) {
+	var mu sync.Mutex
+	var remote string
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		mu.Lock()
+		remote = w.RemoteAddr().(*net.UDPAddr).IP.String()
+		mu.Unlock()
+		m := new(dns.Msg)
+		m.SetReply(r)
+		_ = w.WriteMsg(m)
+	})
+	addr, cleanup := startTestDNSServer(t, handler)
+	defer cleanup()
+
+	bound := DNSServer{
+		Address:   addr,
+		Keyword:   "internetpositif",
+		QueryType: "A",
+		Dialer:    &net.Dialer{LocalAddr: &net.UDPAddr{IP: net.ParseIP("127.0.0.2")}},
+	}
+	c := New(WithServers([]DNSServer{bound}), WithMaxRetries(0))
+
+	r, err := c.CheckOne(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.NoError(t, r.Error)
+	mu.Lock()
+	assert.Equal(t, "127.0.0.2", remote, "query must leave through the server's dialer")
+	mu.Unlock()
+
+	st := c.Ping(context.Background(), bound)
+	assert.True(t, st.Online)
+
+	// A dialer that cannot bind makes only this server fail.
+	unbindable := bound
+	unbindable.Dialer = &net.Dialer{LocalAddr: &net.UDPAddr{IP: net.ParseIP("192.0.2.1")}}
+	st = c.Ping(context.Background(), unbindable)
+	assert.False(t, st.Online)
+	assert.Error(t, st.Error)
+
+	// The dialer is not part of the serialized configuration.
+	var buf bytes.Buffer
+	require.NoError(t, WriteServers(&buf, []DNSServer{bound}))
+	assert.NotContains(t, strings.ToLower(buf.String()), "dialer")
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"sync"
+)
+
+// lifecycle counts in-flight checks so that [Checker.Shutdown] can refuse
+// new ones and wait for the rest. A mutex-guarded counter is used instead of
+// a [sync.WaitGroup], whose Add must not race with Wait.
+type lifecycle struct {
+	mu      sync.Mutex
+	closing bool
+	active  int
+	drained chan struct{} // created by Shutdown; closed once active reaches zero
+	closed  chan struct{} // created on demand by done; closed by Shutdown
+}
+
+// begin registers an in-flight check, or returns [ErrClosing] once
+// [Checker.Shutdown] has been called. Every successful begin must be paired
+// with a call to end.
+func (l *lifecycle) begin() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closing {
+		return ErrClosing
+	}
+	l.active++
+	return nil
+}
+
+// end marks an in-flight check as finished.
+func (l *lifecycle) end() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	if l.closing && l.active == 0 {
+		close(l.drained)
+	}
+}
+
+// done returns a channel that is closed once [Checker.Shutdown] has been
+// called, for long-running callers such as [Checker.WatchDomain].
+func (l *lifecycle) done() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed == nil {
+		l.closed = make(chan struct{})
+		if l.closing {
+			close(l.closed)
+		}
+	}
+	return l.closed
+}
+
+// shutdown stops new checks from starting and returns a channel that is
+// closed once every in-flight check has finished.
+func (l *lifecycle) shutdown() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.closing {
+		l.closing = true
+		if l.closed != nil {
+			close(l.closed)
+		}
+		l.drained = make(chan struct{})
+		if l.active == 0 {
+			close(l.drained)
+		}
+	}
+	return l.drained
+}
+
+// Shutdown gracefully stops the checker: new checks are refused with
+// [ErrClosing] right away, while checks already in flight are left to
+// finish. Once they have, Shutdown calls [Checker.Close] and returns its
+// result.
+//
+// If ctx is done first, Shutdown returns the context error (wrapped in
+// [ErrCanceled] or [ErrDNSTimeout]) without closing, since the remaining
+// checks may still be using pooled connections; call Shutdown again to keep
+// waiting, or Close to release resources anyway.
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if err := c.Shutdown(ctx); err != nil {
+//	    log.Printf("nawala: shutdown: %v", err)
+//	}
+//
+// The checks covered are [Checker.Check], [Checker.CheckWithTags],
+// [Checker.CheckOne], [Checker.CheckIP], [Checker.CheckStream],
+// [Checker.CheckDualStack], [Checker.CheckByServer], and [Checker.Explain],
+// along with the methods built on them, and each tick of
+// [Checker.WatchDomain], whose channel is closed once Shutdown is called. A
+// shut-down checker cannot be restarted.
+func (c *Checker) Shutdown(ctx context.Context) error {
+	select {
+	case <-c.lifecycle.shutdown():
+		return c.Close()
+	case <-ctx.Done():
+		return contextError(ctx.Err())
+	}
+}
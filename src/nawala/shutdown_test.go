@@ -0,0 +1,90 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdownRejectsNewChecks(t *testing.T) {
+	addr, cleanup := startNormalDNSServer(t)
+	defer cleanup()
+
+	c := New(WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}))
+	require.NoError(t, c.Shutdown(context.Background()))
+
+	ctx := context.Background()
+	_, err := c.Check(ctx, "example.com")
+	assert.ErrorIs(t, err, ErrClosing)
+	_, err = c.CheckOne(ctx, "example.com")
+	assert.ErrorIs(t, err, ErrClosing)
+	_, err = c.CheckIP(ctx, net.ParseIP("192.0.2.1"))
+	assert.ErrorIs(t, err, ErrClosing)
+	_, err = c.CheckDualStack(ctx, "example.com")
+	assert.ErrorIs(t, err, ErrClosing)
+	_, err = c.Explain(ctx, "example.com")
+	assert.ErrorIs(t, err, ErrClosing)
+	assert.ErrorIs(t, c.CheckStream(ctx, Stream{}), ErrClosing)
+
+	_, ok := <-c.WatchDomain(ctx, "example.com", time.Second)
+	assert.False(t, ok, "the watch must stop without emitting")
+
+	byServer := c.CheckByServer(ctx, "example.com")
+	require.Len(t, byServer[addr], 1)
+	assert.ErrorIs(t, byServer[addr][0].Error, ErrClosing)
+
+	// Shutdown is idempotent.
+	require.NoError(t, c.Shutdown(context.Background()))
+}
+
+func TestShutdownDrainsInFlight(t *testing.T) {
+	release := make(chan struct{})
+	received := make(chan struct{}, 1)
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		received <- struct{}{}
+		<-release
+		m := new(dns.Msg)
+		m.SetReply(r)
+		_ = w.WriteMsg(m)
+	})
+	addr, cleanup := startTestDNSServer(t, handler)
+	defer cleanup()
+
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(0),
+	)
+
+	done := make(chan error, 1)
+	go func() {
+		r, err := c.CheckOne(context.Background(), "example.com")
+		if err == nil {
+			err = r.Error
+		}
+		done <- err
+	}()
+	<-received
+
+	// The in-flight check keeps Shutdown waiting until ctx expires.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, c.Shutdown(ctx), ErrDNSTimeout)
+
+	// New checks are refused while draining.
+	_, err := c.CheckOne(context.Background(), "example.com")
+	assert.ErrorIs(t, err, ErrClosing)
+
+	close(release)
+	require.NoError(t, c.Shutdown(context.Background()))
+	assert.NoError(t, <-done, "the in-flight check must complete normally")
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// reservedNets are the IPv4 ranges that are never routed on the public
+// internet and are not already covered by the [net.IP] classification
+// methods used in [isBogon].
+var reservedNets = []*net.IPNet{
+	mustCIDR("0.0.0.0/8"),     // "this network" (RFC 791)
+	mustCIDR("100.64.0.0/10"), // shared address space, CGNAT (RFC 6598)
+	mustCIDR("240.0.0.0/4"),   // reserved for future use (RFC 1112)
+}
+
+// specialUseTLDs are top-level names for local or non-DNS use, which
+// legitimately resolve to private addresses and are therefore exempt from
+// sinkhole detection.
+var specialUseTLDs = map[string]bool{
+	"arpa":      true,
+	"home":      true,
+	"internal":  true,
+	"lan":       true,
+	"local":     true,
+	"localhost": true,
+	"test":      true,
+}
+
+func mustCIDR(s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// isBogon reports whether ip is a loopback, private, link-local, multicast,
+// unspecified, or otherwise reserved address that a public domain should
+// never resolve to.
+func isBogon(ip net.IP) bool {
+	if ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, n := range reservedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// sinkholeIP returns the first address of msg's Answer section when msg
+// answers a query for a public domain with addresses that are all bogons
+// (see [isBogon]), or nil otherwise. Names under special-use TLDs such as
+// "local" or "arpa" are never considered sinkholed.
+func sinkholeIP(msg *dns.Msg) net.IP {
+	if len(msg.Question) == 0 {
+		return nil
+	}
+	labels := dns.SplitDomainName(strings.ToLower(msg.Question[0].Name))
+	if len(labels) < 2 || specialUseTLDs[labels[len(labels)-1]] {
+		return nil
+	}
+
+	ips := resolvedIPs(msg)
+	if len(ips) == 0 {
+		return nil
+	}
+	for _, ip := range ips {
+		if !isBogon(ip) {
+			return nil
+		}
+	}
+	return ips[0]
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsBogon(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"0.0.0.0", true},
+		{"0.1.2.3", true},
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.1.1", true},
+		{"100.64.0.1", true},
+		{"224.0.0.1", true},
+		{"240.0.0.1", true},
+		{"::", true},
+		{"::1", true},
+		{"fd00::1", true},
+		{"fe80::1", true},
+		{"93.184.216.34", false},
+		{"36.86.63.185", false},
+		{"2606:2800:220:1::1", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, isBogon(net.ParseIP(tt.ip)), tt.ip)
+	}
+}
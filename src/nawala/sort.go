@@ -0,0 +1,84 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"cmp"
+	"slices"
+)
+
+// SortKey selects the ordering applied by [SortResults].
+type SortKey int
+
+// Sort keys for [SortResults].
+const (
+	// SortByDomain orders results alphabetically by [Result.Domain].
+	SortByDomain SortKey = iota
+
+	// SortByBlocked orders blocked results first, then not-blocked results,
+	// then results carrying an error.
+	SortByBlocked
+
+	// SortByLatency orders results by ascending [Result.Latency], with
+	// results carrying an error last.
+	SortByLatency
+)
+
+// SortResults sorts results in place by the given key.
+//
+// [Checker.Check] returns results positionally aligned with its input, which
+// is what most callers want; SortResults is a display helper for CLIs and
+// reports. The sort is stable, so results comparing equal keep their input
+// order. Results carrying an error are never treated as blocked or as fast:
+// they sort after all successful results for [SortByBlocked] and
+// [SortByLatency].
+//
+// Unknown keys leave results unchanged.
+func SortResults(results []Result, by SortKey) {
+	var compare func(a, b Result) int
+
+	switch by {
+	case SortByDomain:
+		compare = func(a, b Result) int {
+			return cmp.Compare(a.Domain, b.Domain)
+		}
+	case SortByBlocked:
+		compare = func(a, b Result) int {
+			return cmp.Compare(statusRank(a), statusRank(b))
+		}
+	case SortByLatency:
+		compare = func(a, b Result) int {
+			if c := cmp.Compare(errorRank(a), errorRank(b)); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.Latency, b.Latency)
+		}
+	default:
+		return
+	}
+
+	slices.SortStableFunc(results, compare)
+}
+
+// statusRank orders blocked (0) before not-blocked (1) before errored (2).
+func statusRank(r Result) int {
+	switch {
+	case r.Error != nil:
+		return 2
+	case r.Blocked:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// errorRank orders successful results (0) before errored ones (1).
+func errorRank(r Result) int {
+	if r.Error != nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/H0llyW00dzZ/nawala-checker/src/nawala"
+)
+
+func domainsOf(results []nawala.Result) []string {
+	out := make([]string, len(results))
+	for i, r := range results {
+		out[i] = r.Domain
+	}
+	return out
+}
+
+func sampleResults() []nawala.Result {
+	return []nawala.Result{
+		{Domain: "c.com", Latency: 30 * time.Millisecond},
+		{Domain: "err.com", Error: nawala.ErrAllDNSFailed},
+		{Domain: "a.com", Blocked: true, Latency: 50 * time.Millisecond},
+		{Domain: "b.com", Latency: 10 * time.Millisecond},
+		{Domain: "d.com", Blocked: true, Latency: 20 * time.Millisecond},
+	}
+}
+
+func TestSortResults(t *testing.T) {
+	tests := []struct {
+		name string
+		by   nawala.SortKey
+		want []string
+	}{
+		{"by domain", nawala.SortByDomain, []string{"a.com", "b.com", "c.com", "d.com", "err.com"}},
+		{"by blocked", nawala.SortByBlocked, []string{"a.com", "d.com", "c.com", "b.com", "err.com"}},
+		{"by latency", nawala.SortByLatency, []string{"b.com", "d.com", "c.com", "a.com", "err.com"}},
+		{"unknown key", nawala.SortKey(42), []string{"c.com", "err.com", "a.com", "b.com", "d.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := sampleResults()
+			nawala.SortResults(results, tt.by)
+			assert.Equal(t, tt.want, domainsOf(results))
+		})
+	}
+}
+
+func TestSortResultsEmpty(t *testing.T) {
+	assert.NotPanics(t, func() { nawala.SortResults(nil, nawala.SortByBlocked) })
+}
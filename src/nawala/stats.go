@@ -0,0 +1,92 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import "sync/atomic"
+
+// Stats is a snapshot of a [Checker]'s lifetime counters, as returned by
+// [Checker.Stats]. The counters only grow; compute rates by diffing two
+// snapshots.
+type Stats struct {
+	// FailoverCount is the number of times a check moved on from a failed
+	// server to the next configured one. A rising rate usually means the
+	// primary server is degrading.
+	FailoverCount uint64
+
+	// RetryCount is the number of probes sent to a server beyond the first
+	// one of each check, whether re-sent after an error or as part of the
+	// multi-probe logic (see [WithMaxRetries]).
+	RetryCount uint64
+
+	// IDMismatchCount is the number of responses rejected because their
+	// transaction ID did not match the query's (see [ErrIDMismatch]). Any
+	// non-zero value is worth investigating as possible spoofing. Only TCP
+	// and DNS-over-TLS queries are counted: over UDP such replies are
+	// dropped by the transport, so the count stays 0.
+	IDMismatchCount uint64
+}
+
+// SecurityStats is a snapshot of a [Checker]'s lifetime anti-spoofing
+// counters, as returned by [Checker.SecurityStats]. For an interception
+// monitor these are signals in their own right: a rising count suggests
+// someone is tampering with the monitoring traffic itself.
+type SecurityStats struct {
+	// IDMismatchCount is the number of responses rejected with
+	// [ErrIDMismatch], the same count as [Stats.IDMismatchCount].
+	IDMismatchCount uint64
+
+	// QuestionMismatchCount is the number of responses rejected because
+	// their question did not echo the query's (see [ErrQuestionMismatch]).
+	QuestionMismatchCount uint64
+
+	// CookieFailureCount is the number of responses without a valid server
+	// cookie echoing the checker's client cookie, while [WithDNSCookie] is
+	// enabled (see [Result.CookieVerified]). Servers without cookie support
+	// count too, so compare against a known baseline.
+	CookieFailureCount uint64
+
+	// TruncationRetryCount is the number of truncated UDP answers that were
+	// re-queried over TCP. Injected responses are sometimes sent truncated
+	// to force a fallback, and large block pages legitimately are.
+	TruncationRetryCount uint64
+}
+
+// checkerStats holds the counters behind [Stats] and [SecurityStats].
+type checkerStats struct {
+	failovers          atomic.Uint64
+	retries            atomic.Uint64
+	idMismatches       atomic.Uint64
+	questionMismatches atomic.Uint64
+	cookieFailures     atomic.Uint64
+	truncationRetries  atomic.Uint64
+}
+
+// Stats returns a snapshot of the checker's lifetime failover, retry, and
+// ID mismatch counters, suitable for feeding a metrics collector:
+//
+//	s := c.Stats()
+//	failovers.Set(float64(s.FailoverCount))
+//
+// It is safe to call concurrently with checks.
+func (c *Checker) Stats() Stats {
+	return Stats{
+		FailoverCount:   c.stats.failovers.Load(),
+		RetryCount:      c.stats.retries.Load(),
+		IDMismatchCount: c.stats.idMismatches.Load(),
+	}
+}
+
+// SecurityStats returns a snapshot of the checker's lifetime anti-spoofing
+// counters. Like [Checker.Stats], it is safe to call concurrently with
+// checks.
+func (c *Checker) SecurityStats() SecurityStats {
+	return SecurityStats{
+		IDMismatchCount:       c.stats.idMismatches.Load(),
+		QuestionMismatchCount: c.stats.questionMismatches.Load(),
+		CookieFailureCount:    c.stats.cookieFailures.Load(),
+		TruncationRetryCount:  c.stats.truncationRetries.Load(),
+	}
+}
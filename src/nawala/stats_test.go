@@ -0,0 +1,239 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	failAddr, cleanupFail := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetRcode(r, dns.RcodeServerFailure)
+		_ = w.WriteMsg(m)
+	}))
+	defer cleanupFail()
+	okAddr, cleanupOK := startNormalDNSServer(t)
+	defer cleanupOK()
+
+	ctx := context.Background()
+
+	t.Run("failover", func(t *testing.T) {
+		c := New(
+			WithServers([]DNSServer{
+				{Address: failAddr, Keyword: "internetpositif", QueryType: "A"},
+				{Address: okAddr, Keyword: "internetpositif", QueryType: "A"},
+			}),
+			WithMaxRetries(0),
+		)
+		assert.Equal(t, Stats{}, c.Stats())
+
+		result, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		require.NoError(t, result.Error)
+		assert.Equal(t, Stats{FailoverCount: 1}, c.Stats())
+
+		// Failing the last server is not a failover.
+		only := New(
+			WithServers([]DNSServer{{Address: failAddr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithMaxRetries(0),
+		)
+		_, err = only.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		assert.Zero(t, only.Stats().FailoverCount)
+	})
+
+	t.Run("retries", func(t *testing.T) {
+		servers := []DNSServer{{Address: okAddr, Keyword: "internetpositif", QueryType: "A"}}
+
+		c := New(WithServers(servers), WithMaxRetries(2), WithCache(nil))
+		_, err := c.Check(ctx, "example.com", "example.org")
+		require.NoError(t, err)
+		assert.Equal(t, Stats{RetryCount: 4}, c.Stats())
+
+		parallel := New(WithServers(servers), WithMaxRetries(2), WithParallelProbes(true))
+		_, err = parallel.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		assert.Equal(t, Stats{RetryCount: 2}, parallel.Stats())
+	})
+	t.Run("id mismatches", func(t *testing.T) {
+		// Over TCP the transport reports a foreign ID instead of waiting.
+		addr, cleanup := startTCPDNSServer(t, func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(r)
+			m.Id = r.Id + 1
+			_ = w.WriteMsg(m)
+		})
+		defer cleanup()
+
+		c := New(
+			WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithProtocol("tcp"),
+			WithMaxRetries(1),
+		)
+		result, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		assert.ErrorIs(t, result.Error, ErrAllDNSFailed)
+		assert.ErrorIs(t, result.Error, ErrIDMismatch)
+		assert.Equal(t, Stats{RetryCount: 1, IDMismatchCount: 2}, c.Stats())
+	})
+}
+
+// startDualDNSServer serves udpHandler over UDP and tcpHandler over TCP on
+// the same local port.
+func startDualDNSServer(t *testing.T, udpHandler, tcpHandler dns.HandlerFunc) string {
+	t.Helper()
+
+	for range 10 {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		listener, err := net.Listen("tcp", pc.LocalAddr().String())
+		if err != nil {
+			_ = pc.Close() // port taken over TCP; try another
+			continue
+		}
+
+		udp := &dns.Server{PacketConn: pc, Handler: udpHandler}
+		tcp := &dns.Server{Listener: listener, Handler: tcpHandler}
+		for _, srv := range []*dns.Server{udp, tcp} {
+			started := make(chan struct{})
+			srv.NotifyStartedFunc = func() { close(started) }
+			go func() { _ = srv.ActivateAndServe() }()
+			<-started
+			t.Cleanup(func() { _ = srv.Shutdown() })
+		}
+		return pc.LocalAddr().String()
+	}
+	t.Fatal("no port free for both UDP and TCP")
+	return ""
+}
+
+func TestSecurityStats(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("question mismatch", func(t *testing.T) {
+		addr, cleanup := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(r)
+			m.Question[0].Name = "evil.example."
+			_ = w.WriteMsg(m)
+		}))
+		defer cleanup()
+
+		c := New(
+			WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithMaxRetries(1),
+		)
+		result, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		assert.ErrorIs(t, result.Error, ErrAllDNSFailed)
+		assert.ErrorIs(t, result.Error, ErrQuestionMismatch)
+		assert.Equal(t, SecurityStats{QuestionMismatchCount: 2}, c.SecurityStats())
+	})
+
+	t.Run("cookie failures", func(t *testing.T) {
+		addr, cleanup := startNormalDNSServer(t) // does not echo cookies
+		defer cleanup()
+
+		c := New(
+			WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithMaxRetries(1),
+			WithDNSCookie(true),
+		)
+		result, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		require.NoError(t, result.Error)
+		assert.False(t, result.CookieVerified)
+		assert.Equal(t, SecurityStats{CookieFailureCount: 2}, c.SecurityStats())
+
+		// Without cookies nothing is counted.
+		c = New(WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}))
+		_, err = c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		assert.Equal(t, SecurityStats{}, c.SecurityStats())
+	})
+
+	t.Run("truncation retries", func(t *testing.T) {
+		addr := startDualDNSServer(t,
+			func(w dns.ResponseWriter, r *dns.Msg) {
+				m := new(dns.Msg)
+				m.SetReply(r)
+				m.Truncated = true
+				_ = w.WriteMsg(m)
+			},
+			func(w dns.ResponseWriter, r *dns.Msg) {
+				m := new(dns.Msg)
+				m.SetReply(r)
+				m.Answer = append(m.Answer, &dns.CNAME{
+					Hdr:    dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 60},
+					Target: "internetpositif.id.",
+				})
+				_ = w.WriteMsg(m)
+			},
+		)
+
+		c := New(
+			WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithMaxRetries(0),
+		)
+		result, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		require.NoError(t, result.Error)
+		assert.True(t, result.Blocked, "the full TCP answer must be judged")
+		assert.Equal(t, SecurityStats{TruncationRetryCount: 1}, c.SecurityStats())
+	})
+
+	t.Run("truncated without TCP", func(t *testing.T) {
+		addr, cleanup := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(r)
+			m.Truncated = true
+			m.Answer = append(m.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   []byte{93, 184, 216, 34},
+			})
+			_ = w.WriteMsg(m)
+		}))
+		defer cleanup()
+
+		c := New(
+			WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+			WithMaxRetries(0),
+		)
+		result, err := c.CheckOne(ctx, "example.com")
+		require.NoError(t, err)
+		require.NoError(t, result.Error, "the truncated answer is kept")
+		assert.Len(t, result.ResolvedIPs, 1)
+		assert.Equal(t, uint64(1), c.SecurityStats().TruncationRetryCount)
+	})
+}
+
+func TestQuestionEchoed(t *testing.T) {
+	query := new(dns.Msg)
+	query.SetQuestion("Example.com.", dns.TypeA)
+
+	resp := new(dns.Msg)
+	resp.SetReply(query)
+	assert.True(t, questionEchoed(query, resp))
+
+	resp.Question[0].Name = "EXAMPLE.COM."
+	assert.True(t, questionEchoed(query, resp), "names compare case-insensitively")
+
+	resp.Question[0].Qtype = dns.TypeAAAA
+	assert.False(t, questionEchoed(query, resp))
+
+	resp.Question = nil
+	assert.True(t, questionEchoed(query, resp), "a missing question is accepted")
+
+	resp.Question = []dns.Question{query.Question[0], query.Question[0]}
+	assert.False(t, questionEchoed(query, resp))
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import "errors"
+
+// CheckStatus is a coarse classification of a [Result], combining its
+// verdict and error into the categories an API typically reports per
+// domain. Obtain it with [Result.Status].
+type CheckStatus int
+
+// Check statuses reported by [Result.Status].
+const (
+	// StatusClean means a server answered and the domain is not blocked.
+	StatusClean CheckStatus = iota
+
+	// StatusBlocked means a server answered and the domain is blocked.
+	StatusBlocked
+
+	// StatusInvalid means the domain failed validation ([ErrInvalidDomain])
+	// and was never queried.
+	StatusInvalid
+
+	// StatusTimeout means the check timed out ([ErrDNSTimeout]), either on
+	// the query timeout or the caller's context deadline.
+	StatusTimeout
+
+	// StatusError covers every other failure, such as NXDOMAIN, rejected
+	// queries, cancellation, or a fail-open [Result.Degraded] verdict.
+	StatusError
+)
+
+// String returns a short lowercase name for the status.
+func (s CheckStatus) String() string {
+	switch s {
+	case StatusClean:
+		return "clean"
+	case StatusBlocked:
+		return "blocked"
+	case StatusInvalid:
+		return "invalid"
+	case StatusTimeout:
+		return "timeout"
+	default:
+		return "error"
+	}
+}
+
+// Status classifies r, matching [Result.Error] against the sentinel errors
+// with [errors.Is]:
+//
+//	switch r.Status() {
+//	case nawala.StatusBlocked:
+//	    w.WriteHeader(http.StatusOK)
+//	case nawala.StatusInvalid:
+//	    w.WriteHeader(http.StatusBadRequest)
+//	case nawala.StatusTimeout:
+//	    w.WriteHeader(http.StatusGatewayTimeout)
+//	}
+//
+// When every server failed and some failures were timeouts, the result
+// reports [StatusTimeout]. A [Result.Degraded] verdict reports [StatusError]
+// rather than [StatusClean], since no server actually answered.
+func (r Result) Status() CheckStatus {
+	switch {
+	case r.Error == nil && r.Degraded:
+		return StatusError
+	case r.Error == nil && r.Blocked:
+		return StatusBlocked
+	case r.Error == nil:
+		return StatusClean
+	case errors.Is(r.Error, ErrInvalidDomain):
+		return StatusInvalid
+	case errors.Is(r.Error, ErrDNSTimeout):
+		return StatusTimeout
+	default:
+		return StatusError
+	}
+}
+
+// SameVerdict reports whether r and other carry the same meaningful outcome:
+// the same [Result.Status], and for successful results the same block
+// verdict and [Result.Degraded] flag. Transient details such as the latency,
+// the server that answered, or the exact error text are ignored, so a
+// failover to another server with the same verdict, or two different
+// timeouts, compare equal:
+//
+//	if !prev.SameVerdict(next) {
+//	    notify(next)
+//	}
+//
+// It is the change test used by [Checker.WatchDomain].
+func (r Result) SameVerdict(other Result) bool {
+	if r.Status() != other.Status() {
+		return false
+	}
+	if r.Error != nil {
+		return true // same error category; Blocked is meaningless here
+	}
+	return r.Blocked == other.Blocked && r.Degraded == other.Degraded
+}
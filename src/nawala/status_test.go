@@ -0,0 +1,73 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		result Result
+		want   CheckStatus
+	}{
+		{"clean", Result{}, StatusClean},
+		{"blocked", Result{Blocked: true}, StatusBlocked},
+		{"degraded", Result{Degraded: true}, StatusError},
+		{"blocked with error", Result{Blocked: true, Error: ErrNXDOMAIN}, StatusError},
+		{"invalid", Result{Error: fmt.Errorf("%w: empty domain name", ErrInvalidDomain)}, StatusInvalid},
+		{"timeout", Result{Error: contextError(context.DeadlineExceeded)}, StatusTimeout},
+		{"all failed with a timeout", Result{Error: &CheckError{
+			Err: fmt.Errorf("%w: %w", ErrAllDNSFailed, errors.Join(ErrServerFailure, ErrDNSTimeout)),
+		}}, StatusTimeout},
+		{"all failed", Result{Error: &CheckError{Err: ErrAllDNSFailed}}, StatusError},
+		{"canceled", Result{Error: contextError(context.Canceled)}, StatusError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.result.Status())
+		})
+	}
+}
+
+func TestResultSameVerdict(t *testing.T) {
+	clean := Result{Server: "a", Latency: time.Millisecond}
+	cleanElsewhere := Result{Server: "b", Latency: time.Second}
+	blocked := Result{Blocked: true}
+	degraded := Result{Degraded: true}
+	degradedBlocked := Result{Degraded: true, Blocked: true}
+	failed := Result{Error: errors.New("boom")}
+	failedOther := Result{Error: errors.New("other"), Blocked: true}
+	timeout := Result{Error: contextError(context.DeadlineExceeded)}
+
+	assert.True(t, clean.SameVerdict(clean))
+	assert.True(t, clean.SameVerdict(cleanElsewhere), "server and latency are transient")
+	assert.False(t, clean.SameVerdict(blocked))
+	assert.False(t, blocked.SameVerdict(clean))
+	assert.False(t, clean.SameVerdict(failed))
+	assert.False(t, failed.SameVerdict(clean))
+	assert.True(t, failed.SameVerdict(failedOther), "same error category; Blocked is ignored")
+	assert.False(t, failed.SameVerdict(timeout))
+	assert.False(t, clean.SameVerdict(degraded))
+	assert.False(t, degraded.SameVerdict(degradedBlocked))
+	assert.True(t, degraded.SameVerdict(Result{Degraded: true, Server: "x"}))
+}
+
+func TestCheckStatusString(t *testing.T) {
+	assert.Equal(t, "clean", StatusClean.String())
+	assert.Equal(t, "blocked", StatusBlocked.String())
+	assert.Equal(t, "invalid", StatusInvalid.String())
+	assert.Equal(t, "timeout", StatusTimeout.String())
+	assert.Equal(t, "error", StatusError.String())
+	assert.Equal(t, "error", CheckStatus(99).String())
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CheckSubdomains checks every subdomain in subs under the zone base as a
+// single batch, answering "is any part of this site blocked" without
+// building the names by hand:
+//
+//	results, err := c.CheckSubdomains(ctx, "example.com", []string{"www", "mail", "@"})
+//	// checks www.example.com, mail.example.com, and example.com
+//
+// Each entry is a relative label run such as "www" or "cdn.static"; an
+// empty entry or "@" (the zone-file shorthand) stands for base itself, and
+// an entry that already ends in base (as in zone transfer output) is used
+// as is. Leading and trailing dots are ignored.
+//
+// base is normalized and validated first; if it is invalid the returned
+// error wraps [ErrInvalidDomain] and nothing is queried. The joined names
+// are then checked exactly as [Checker.Check] does, so an invalid
+// subdomain is reported in its own [Result] and results are returned in
+// the order of subs.
+func (c *Checker) CheckSubdomains(ctx context.Context, base string, subs []string) ([]Result, error) {
+	base = strings.TrimSuffix(c.normalizer(base), ".")
+	if err := c.domainRules.validate(base); err != nil {
+		return nil, err
+	}
+
+	domains := make([]string, len(subs))
+	for i, sub := range subs {
+		domains[i] = joinSubdomain(sub, base)
+	}
+	return c.Check(ctx, domains...)
+}
+
+// joinSubdomain prefixes sub onto base, as described on
+// [Checker.CheckSubdomains].
+func joinSubdomain(sub, base string) string {
+	sub = strings.Trim(strings.TrimSpace(sub), ".")
+	if sub == "" || sub == "@" {
+		return base
+	}
+	lower := strings.ToLower(sub)
+	if lower == strings.ToLower(base) || strings.HasSuffix(lower, "."+strings.ToLower(base)) {
+		return sub
+	}
+	return sub + "." + base
+}
+
+// ReadSubdomains reads subdomain names from r for use with
+// [Checker.CheckSubdomains]. It takes the first whitespace-separated field
+// of each line, so it accepts both a plain list (one name per line) and
+// zone file or "dig axfr" output, where the owner name comes first. Blank
+// lines and lines starting with "#" or ";" are skipped, and repeated names
+// (an owner with several records) are returned once, in order of first
+// appearance.
+func ReadSubdomains(r io.Reader) ([]string, error) {
+	var subs []string
+	seen := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+		name := fields[0]
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		subs = append(subs, name)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read subdomains: %w", err)
+	}
+	return subs, nil
+}
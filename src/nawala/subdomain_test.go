@@ -0,0 +1,87 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoinSubdomain(t *testing.T) {
+	tests := []struct {
+		sub  string
+		want string
+	}{
+		{"www", "www.example.com"},
+		{"cdn.static", "cdn.static.example.com"},
+		{" www. ", "www.example.com"},
+		{".mail", "mail.example.com"},
+		{"@", "example.com"},
+		{"", "example.com"},
+		{"www.example.com.", "www.example.com"},
+		{"WWW.Example.COM", "WWW.Example.COM"},
+		{"example.com", "example.com"},
+		{"notexample.com", "notexample.com.example.com"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, joinSubdomain(tt.sub, "example.com"), "sub %q", tt.sub)
+	}
+}
+
+func TestCheckSubdomains(t *testing.T) {
+	addr, cleanup := startBlockingDNSServer(t)
+	defer cleanup()
+
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(0),
+	)
+
+	results, err := c.CheckSubdomains(context.Background(), "Example.COM.", []string{"www", "@", "bad_label!"})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, "www.example.com", results[0].Domain)
+	assert.True(t, results[0].Blocked)
+	assert.Equal(t, "example.com", results[1].Domain)
+	assert.True(t, results[1].Blocked)
+	assert.ErrorIs(t, results[2].Error, ErrInvalidDomain)
+
+	_, err = c.CheckSubdomains(context.Background(), "not a domain", []string{"www"})
+	assert.ErrorIs(t, err, ErrInvalidDomain)
+}
+
+func TestReadSubdomains(t *testing.T) {
+	input := `# plain list
+www
+mail
+
+; <<>> DiG <<>> axfr example.com
+example.com.		3600	IN	SOA	ns1.example.com. admin.example.com. 1 7200 3600 1209600 3600
+example.com.		3600	IN	NS	ns1.example.com.
+cdn.example.com.	300	IN	A	192.0.2.1
+cdn.example.com.	300	IN	AAAA	2001:db8::1
+www
+`
+	subs, err := ReadSubdomains(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"www", "mail", "example.com.", "cdn.example.com."}, subs)
+
+	subs, err = ReadSubdomains(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Empty(t, subs)
+
+	boom := errors.New("boom")
+	_, err = ReadSubdomains(iotest.ErrReader(boom))
+	assert.ErrorIs(t, err, boom)
+}
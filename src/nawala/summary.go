@@ -0,0 +1,48 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+// Summary aggregates a batch of results, as returned by [Summarize].
+type Summary struct {
+	// Total is the number of results summarized.
+	Total int
+
+	// ByStatus counts the results per [Result.Status]. Statuses with no
+	// results are absent.
+	ByStatus map[CheckStatus]int
+
+	// ByReason breaks the [StatusBlocked] results down by how they were
+	// blocked ([Result.BlockReason]): a CNAME redirect, an EDE code, a
+	// sinkhole, and so on. Its counts add up to ByStatus[StatusBlocked].
+	ByReason map[BlockReason]int
+}
+
+// Summarize counts results by status and blocked results by block reason,
+// the reporting shape for censorship measurement: not only how many
+// domains were blocked, but how.
+//
+//	s := nawala.Summarize(results)
+//	fmt.Printf("%d/%d blocked\n", s.ByStatus[nawala.StatusBlocked], s.Total)
+//	for reason, n := range s.ByReason {
+//	    fmt.Printf("  %s: %d\n", reason, n)
+//	}
+//
+// The maps are never nil, so they can be indexed and ranged over directly.
+func Summarize(results []Result) Summary {
+	s := Summary{
+		Total:    len(results),
+		ByStatus: make(map[CheckStatus]int),
+		ByReason: make(map[BlockReason]int),
+	}
+	for _, r := range results {
+		status := r.Status()
+		s.ByStatus[status]++
+		if status == StatusBlocked {
+			s.ByReason[r.BlockReason]++
+		}
+	}
+	return s
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/H0llyW00dzZ/nawala-checker/src/nawala"
+)
+
+func TestSummarize(t *testing.T) {
+	results := []nawala.Result{
+		{Domain: "a.com", Blocked: true, BlockReason: nawala.BlockReasonRedirect},
+		{Domain: "b.com", Blocked: true, BlockReason: nawala.BlockReasonRedirect},
+		{Domain: "c.com", Blocked: true, BlockReason: nawala.BlockReasonBlocked},
+		{Domain: "d.com", Blocked: true, BlockReason: nawala.BlockReasonSinkhole},
+		{Domain: "e.com"},
+		{Domain: "f.com", Degraded: true},
+		{Domain: "bad", Error: nawala.ErrInvalidDomain},
+		// An errored result is not counted as blocked.
+		{Domain: "g.com", Blocked: true, BlockReason: nawala.BlockReasonRedirect, Error: nawala.ErrAllDNSFailed},
+	}
+
+	s := nawala.Summarize(results)
+	assert.Equal(t, 8, s.Total)
+	assert.Equal(t, map[nawala.CheckStatus]int{
+		nawala.StatusBlocked: 4,
+		nawala.StatusClean:   1,
+		nawala.StatusInvalid: 1,
+		nawala.StatusError:   2,
+	}, s.ByStatus)
+	assert.Equal(t, map[nawala.BlockReason]int{
+		nawala.BlockReasonRedirect: 2,
+		nawala.BlockReasonBlocked:  1,
+		nawala.BlockReasonSinkhole: 1,
+	}, s.ByReason)
+}
+
+func TestSummarizeEmpty(t *testing.T) {
+	s := nawala.Summarize(nil)
+	assert.Zero(t, s.Total)
+	assert.NotNil(t, s.ByStatus)
+	assert.NotNil(t, s.ByReason)
+	assert.Zero(t, s.ByStatus[nawala.StatusBlocked])
+}
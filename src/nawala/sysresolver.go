@@ -0,0 +1,65 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// SystemResolverServer is the [Result.Server] of a verdict obtained through
+// the system resolver fallback (see [WithSystemResolverFallback]).
+const SystemResolverServer = "system"
+
+// lookupSystem resolves domain with the system resolver and judges the
+// addresses it returns with the IP-based detection rules only: the
+// configured block IPs and sinkhole detection. The result is marked
+// [Result.Degraded], since no configured server answered.
+func (c *Checker) lookupSystem(ctx context.Context, domain string) (Result, error) {
+	start := time.Now()
+	ips, err := c.sysResolver.LookupIP(ctx, "ip", domain)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return Result{}, contextError(ctxErr)
+		}
+		return Result{}, err
+	}
+	rtt := time.Since(start)
+
+	// Dress the addresses up as a response, so that DetectBlock applies the
+	// same rules as for a DNS answer.
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(domain), dns.TypeA)
+	for _, ip := range ips {
+		hdr := dns.RR_Header{Name: msg.Question[0].Name, Class: dns.ClassINET}
+		if ip4 := ip.To4(); ip4 != nil {
+			hdr.Rrtype = dns.TypeA
+			msg.Answer = append(msg.Answer, &dns.A{Hdr: hdr, A: ip4})
+		} else {
+			hdr.Rrtype = dns.TypeAAAA
+			msg.Answer = append(msg.Answer, &dns.AAAA{Hdr: hdr, AAAA: ip})
+		}
+	}
+
+	blocked, details := DetectBlock(msg, DetectOptions{
+		BlockIPs:          c.sysBlockIPs,
+		SinkholeDetection: true,
+		AllMatches:        c.allMatches,
+	})
+	return Result{
+		Domain:         domain,
+		Server:         SystemResolverServer,
+		Blocked:        blocked,
+		BlockReason:    details.Reason,
+		MatchedSection: details.Section,
+		Signals:        details.Signals,
+		ResolvedIPs:    ips,
+		Latency:        rtt,
+		Degraded:       true,
+	}, nil
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSystemResolver returns a resolver that sends every query to a test
+// DNS server answering A queries with ip, or with SERVFAIL when ip is nil.
+func newTestSystemResolver(t *testing.T, ip net.IP) *net.Resolver {
+	t.Helper()
+	addr, cleanup := startTestDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		switch {
+		case ip == nil:
+			m.Rcode = dns.RcodeServerFailure
+		case r.Question[0].Qtype == dns.TypeA:
+			m.Answer = append(m.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   ip,
+			})
+		}
+		_ = w.WriteMsg(m)
+	}))
+	t.Cleanup(cleanup)
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "udp", addr)
+		},
+	}
+}
+
+func TestWithSystemResolverFallback(t *testing.T) {
+	blockPage := net.ParseIP("203.0.113.7")
+
+	newChecker := func(t *testing.T, ip net.IP) *Checker {
+		c := New(
+			WithServers([]DNSServer{{Address: "127.0.0.1:1", Keyword: "internetpositif", QueryType: "A"}}),
+			WithMaxRetries(0),
+			WithTimeout(200*time.Millisecond),
+			WithSystemResolverFallback(true, blockPage),
+		)
+		c.sysResolver = newTestSystemResolver(t, ip)
+		return c
+	}
+
+	t.Run("block page", func(t *testing.T) {
+		c := newChecker(t, blockPage)
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		require.NoError(t, r.Error)
+		assert.True(t, r.Blocked)
+		assert.True(t, r.Degraded)
+		assert.Equal(t, SystemResolverServer, r.Server)
+		assert.True(t, r.ResolvedIPs[0].Equal(blockPage))
+	})
+
+	t.Run("sinkhole", func(t *testing.T) {
+		c := newChecker(t, net.ParseIP("10.0.0.1"))
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		require.NoError(t, r.Error)
+		assert.True(t, r.Blocked)
+		assert.Equal(t, BlockReasonSinkhole, r.BlockReason)
+	})
+
+	t.Run("clean", func(t *testing.T) {
+		c := newChecker(t, net.ParseIP("93.184.216.34"))
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		require.NoError(t, r.Error)
+		assert.False(t, r.Blocked)
+		assert.True(t, r.Degraded)
+
+		// Degraded verdicts are never cached.
+		n, _ := c.CacheLen()
+		assert.Zero(t, n)
+	})
+
+	t.Run("lookup fails", func(t *testing.T) {
+		c := newChecker(t, nil)
+		r, err := c.CheckOne(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.ErrorIs(t, r.Error, ErrAllDNSFailed)
+		assert.Contains(t, r.Error.Error(), SystemResolverServer+":")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		c := New(WithSystemResolverFallback(true), WithSystemResolverFallback(false))
+		assert.Nil(t, c.sysResolver)
+		assert.Nil(t, c.sysBlockIPs)
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WatchDomain continuously monitors domain, checking it immediately and then
+// on every tick of interval, and emits a [Result] on the returned channel
+// whenever the observation changes: the first result is always emitted, and
+// after that only when the verdict changes according to [Result.SameVerdict],
+// such as [Result.Blocked] flipping or the check starting to fail or
+// failing differently (a timeout turning into another error).
+//
+//	for r := range c.WatchDomain(ctx, "example.com", time.Minute) {
+//	    if r.Error != nil {
+//	        log.Printf("%s: check failing: %v", r.Domain, r.Error)
+//	        continue
+//	    }
+//	    log.Printf("%s: blocked=%v", r.Domain, r.Blocked)
+//	}
+//
+// Every check bypasses the cache so that each tick observes the servers'
+// current answer. Each check counts as in flight for [Checker.Shutdown]; a
+// check already running when Shutdown is called still completes, and the
+// channel is closed once ctx is cancelled or the checker is shutting down.
+// interval must be positive; like [time.NewTicker], WatchDomain panics
+// otherwise.
+func (c *Checker) WatchDomain(ctx context.Context, domain string, interval time.Duration) <-chan Result {
+	ticker := time.NewTicker(interval)
+	out := make(chan Result, 1)
+	closing := c.lifecycle.done()
+
+	go func() {
+		defer close(out)
+		defer ticker.Stop()
+
+		var (
+			prev     Result
+			observed bool
+		)
+		for {
+			result, err := c.watchOnce(ctx, domain)
+			if ctx.Err() != nil || errors.Is(err, ErrClosing) {
+				return
+			}
+			if err != nil {
+				// ErrNoDNSServers: report it like any failing check.
+				result = Result{Domain: domain, Error: err}
+			}
+
+			if !observed || !prev.SameVerdict(result) {
+				// Deliver into a free buffer slot even while shutting down,
+				// but do not wait on a consumer that stopped reading.
+				select {
+				case out <- result:
+				default:
+					select {
+					case out <- result:
+					case <-ctx.Done():
+						return
+					case <-closing:
+						return
+					}
+				}
+				prev, observed = result, true
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			case <-closing:
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// watchOnce performs a single uncached check of domain for [Checker.WatchDomain].
+func (c *Checker) watchOnce(ctx context.Context, domain string) (Result, error) {
+	if err := c.lifecycle.begin(); err != nil {
+		return Result{}, err
+	}
+	defer c.lifecycle.end()
+
+	c.mu.RLock()
+	n := len(c.servers)
+	c.mu.RUnlock()
+
+	if n == 0 {
+		return Result{}, ErrNoDNSServers
+	}
+	return c.checkSingle(ctx, domain, checkOptions{fresh: true}), nil
+}
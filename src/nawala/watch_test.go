@@ -0,0 +1,167 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchDomain(t *testing.T) {
+	var blocked atomic.Bool
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		if blocked.Load() {
+			rr, _ := dns.NewRR(r.Question[0].Name + " 60 IN CNAME internetpositif.id.")
+			m.Answer = append(m.Answer, rr)
+		}
+		_ = w.WriteMsg(m)
+	})
+	addr, cleanup := startTestDNSServer(t, handler)
+	defer cleanup()
+
+	// The cache is left enabled on purpose: watching must bypass it.
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(0),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := c.WatchDomain(ctx, "example.com", 10*time.Millisecond)
+
+	receive := func() Result {
+		t.Helper()
+		select {
+		case r, ok := <-ch:
+			require.True(t, ok, "channel closed early")
+			return r
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for a change")
+			return Result{}
+		}
+	}
+
+	first := receive()
+	require.NoError(t, first.Error)
+	assert.False(t, first.Blocked, "the first observation is always emitted")
+
+	// Unchanged observations are not emitted.
+	select {
+	case r := <-ch:
+		t.Fatalf("unexpected emission without a change: %+v", r)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	blocked.Store(true)
+	r := receive()
+	require.NoError(t, r.Error)
+	assert.True(t, r.Blocked)
+
+	blocked.Store(false)
+	r = receive()
+	assert.False(t, r.Blocked)
+
+	cancel()
+	require.Eventually(t, func() bool {
+		_, ok := <-ch
+		return !ok
+	}, 2*time.Second, 5*time.Millisecond, "channel must close after cancel")
+}
+
+func TestWatchDomainNoServers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := <-New(WithServers(nil)).WatchDomain(ctx, "example.com", time.Second)
+	assert.ErrorIs(t, r.Error, ErrNoDNSServers)
+}
+
+func TestWatchDomainShutdown(t *testing.T) {
+	release := make(chan struct{})
+	received := make(chan struct{}, 1)
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		received <- struct{}{}
+		<-release
+		m := new(dns.Msg)
+		m.SetReply(r)
+		_ = w.WriteMsg(m)
+	})
+	addr, cleanup := startTestDNSServer(t, handler)
+	defer cleanup()
+
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(0),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := c.WatchDomain(ctx, "example.com", time.Hour)
+	<-received
+
+	// The in-flight tick keeps Shutdown waiting.
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer shortCancel()
+	assert.ErrorIs(t, c.Shutdown(shortCtx), ErrDNSTimeout)
+
+	close(release)
+	require.NoError(t, c.Shutdown(context.Background()))
+
+	r, ok := <-ch
+	require.True(t, ok)
+	assert.NoError(t, r.Error, "the in-flight tick completes normally")
+	_, ok = <-ch
+	assert.False(t, ok, "the watch stops once the checker is shutting down")
+}
+
+func TestWatchDomainShutdownStalledConsumer(t *testing.T) {
+	var blocked atomic.Bool
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		if blocked.Load() {
+			rr, _ := dns.NewRR(r.Question[0].Name + " 60 IN CNAME internetpositif.id.")
+			m.Answer = append(m.Answer, rr)
+		}
+		_ = w.WriteMsg(m)
+		blocked.Store(!blocked.Load())
+	})
+	addr, cleanup := startTestDNSServer(t, handler)
+	defer cleanup()
+
+	c := New(
+		WithServers([]DNSServer{{Address: addr, Keyword: "internetpositif", QueryType: "A"}}),
+		WithMaxRetries(0),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Every tick flips the verdict, so with nobody reading the watch soon
+	// blocks on a full channel.
+	ch := c.WatchDomain(ctx, "example.com", time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	require.NoError(t, c.Shutdown(context.Background()))
+	time.Sleep(50 * time.Millisecond)
+
+	// Only the buffered result is left: the pending one was abandoned
+	// instead of waiting for the consumer.
+	var n int
+	for range ch {
+		n++
+	}
+	assert.LessOrEqual(t, n, 1)
+}
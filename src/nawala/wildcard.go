@@ -0,0 +1,40 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala
+
+import "strings"
+
+// WildcardProbeLabel is the label [ExpandWildcard] prepends to the base
+// domain of a wildcard entry to build its synthetic probe subdomain.
+const WildcardProbeLabel = "nawala-wildcard-probe"
+
+// ExpandWildcard turns a block-list entry into the domains to check.
+//
+// A wildcard entry such as "*.gambling.example" expands to the apex
+// ("gambling.example") and a synthetic subdomain built from
+// [WildcardProbeLabel] ("nawala-wildcard-probe.gambling.example"). Any other
+// entry is returned unchanged as a single-element slice, and is left to the
+// checker's normal validation.
+//
+//	for _, d := range nawala.ExpandWildcard("*.gambling.example") {
+//	    r, err := c.CheckOne(ctx, d)
+//	    // ...
+//	}
+//
+// DNS wildcards are expanded by the server, never by the client: there is no
+// query that asks "is every subdomain blocked". The probe subdomain stands in
+// for an arbitrary subdomain, so a blocked probe means the server applies the
+// entry as a suffix rule (as with RPZ wildcard triggers), while the apex
+// result tells whether the base domain itself is blocked. The two can differ,
+// since a suffix rule does not necessarily cover its apex. Only a leading
+// "*." is recognized; a "*" anywhere else makes the entry invalid.
+func ExpandWildcard(entry string) []string {
+	base, ok := strings.CutPrefix(entry, "*.")
+	if !ok || base == "" {
+		return []string{entry}
+	}
+	return []string{base, WildcardProbeLabel + "." + base}
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2026 H0llyW00dzZ All rights reserved.
+//
+// By accessing or using this software, you agree to be bound by the terms
+// of the License Agreement, which you can find at LICENSE files.
+
+package nawala_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/H0llyW00dzZ/nawala-checker/src/nawala"
+)
+
+func TestExpandWildcard(t *testing.T) {
+	tests := []struct {
+		entry string
+		want  []string
+	}{
+		{"*.gambling.example", []string{"gambling.example", "nawala-wildcard-probe.gambling.example"}},
+		{"example.com", []string{"example.com"}},
+		{"*.", []string{"*."}},
+		{"sub.*.example.com", []string{"sub.*.example.com"}},
+		{"*example.com", []string{"*example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			got := nawala.ExpandWildcard(tt.entry)
+			assert.Equal(t, tt.want, got)
+			if len(got) == 2 {
+				for _, d := range got {
+					assert.True(t, nawala.IsValidDomain(d), d)
+				}
+			}
+		})
+	}
+}